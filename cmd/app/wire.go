@@ -7,6 +7,7 @@ import (
 
 	"thomas.vn/apartment_service/internal/config"
 	"thomas.vn/apartment_service/internal/di"
+	xcron "thomas.vn/apartment_service/pkg/cron"
 	xhttp "thomas.vn/apartment_service/pkg/http"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
 	xserver "thomas.vn/apartment_service/pkg/server"
@@ -33,9 +34,12 @@ func NewApp(cfg *config.Config) (*App, func(), error) {
 
 	// Initialize HTTP server
 	httpServer := xhttp.NewHTTPServer(logger, cfg.Server.HTTP.Host, cfg.Server.HTTP.Port, container.HTTPHandler)
+
+	// Initialize cron server
+	cronServer := xcron.NewCronServer(logger, container.CronJobs)
 	return &App{
 		logger:  logger,
-		servers: []xserver.Server{httpServer},
+		servers: []xserver.Server{httpServer, cronServer},
 	}, cleanup, nil
 
 }
@@ -0,0 +1,14 @@
+package config
+
+// ChatDigestConfig controls the job that mails offline members a digest of
+// their unread chat messages.
+type ChatDigestConfig struct {
+	Enabled  bool
+	Schedule string
+	// QuietMinutes is how long a conversation must stay quiet before its
+	// unread messages are mailed, so that a burst of messages ends up in one
+	// digest.
+	QuietMinutes int
+	// MaxMessages is the number of latest messages shown per chat group.
+	MaxMessages int
+}
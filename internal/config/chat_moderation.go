@@ -0,0 +1,10 @@
+package config
+
+// ChatModerationConfig controls the word list filter applied to chat
+// messages before they are stored.
+type ChatModerationConfig struct {
+	// Action is "mask" to replace banned words with asterisks or "reject"
+	// to refuse the message.
+	Action      string
+	BannedWords []string
+}
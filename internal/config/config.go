@@ -16,16 +16,20 @@ const (
 )
 
 type Config struct {
-	App        AppConfig
-	Server     ServerConfig
-	Logger     LoggerConfig
-	Database   DatabaseConfig
-	Data       DataConfig
-	Ai         AiConfig
-	JWT        JWTConfig
-	Auth       AuthConfig
-	Cloudinary CloudinaryConfig
-	Mailer     MailerConfig
+	App            AppConfig
+	Server         ServerConfig
+	Logger         LoggerConfig
+	Database       DatabaseConfig
+	Data           DataConfig
+	Ai             AiConfig
+	JWT            JWTConfig
+	Auth           AuthConfig
+	Cloudinary     CloudinaryConfig
+	Mailer         MailerConfig
+	MailOutbox     MailOutboxConfig
+	Retention      RetentionConfig
+	ChatDigest     ChatDigestConfig
+	ChatModeration ChatModerationConfig
 }
 
 func LoadConfig(env Environment, configPath string) (*Config, error) {
@@ -0,0 +1,14 @@
+package config
+
+// MailOutboxConfig configures the worker delivering the mail outbox.
+type MailOutboxConfig struct {
+	Enabled  bool
+	Schedule string
+	// BatchSize caps the mail sent per run.
+	BatchSize int
+	// MaxAttempts is how often a mail is tried before it is marked failed.
+	MaxAttempts int
+	// StaleMinutes is how long a mail may stay in sending before it is
+	// assumed lost, for example after a crash, and tried again.
+	StaleMinutes int
+}
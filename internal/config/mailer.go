@@ -1,10 +1,17 @@
 package config
 
+import "time"
+
 // MailerConfig holds all configuration for the outgoing mail service.
 // Values are loaded from the YAML config file — never hardcoded in code.
 type MailerConfig struct {
-	SMTP     MailerSMTPConfig
-	FromName string
+	// Transport is "smtp", "file" (maildir of .eml files for local
+	// development) or "memory" (kept in memory, nothing is delivered).
+	Transport   string
+	SMTP        MailerSMTPConfig
+	File        MailerFileConfig
+	Unsubscribe MailerUnsubscribeConfig
+	FromName    string
 }
 
 // MailerSMTPConfig holds SMTP connection and authentication details.
@@ -13,4 +20,20 @@ type MailerSMTPConfig struct {
 	Port string
 	User string
 	Pass string
+	// TLS is starttls, implicit or none.
+	TLS      string
+	Timeout  time.Duration
+	PoolSize int
+}
+
+// MailerUnsubscribeConfig holds the endpoint and signing secret of the
+// unsubscribe links embedded in mail.
+type MailerUnsubscribeConfig struct {
+	URL    string
+	Secret string
+}
+
+// MailerFileConfig holds where the file transport writes mail.
+type MailerFileConfig struct {
+	Dir string
 }
@@ -0,0 +1,8 @@
+package config
+
+// RetentionConfig controls the job that permanently removes soft deleted rows.
+type RetentionConfig struct {
+	Enabled  bool
+	Schedule string
+	Days     int
+}
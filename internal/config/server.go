@@ -3,7 +3,8 @@ package config
 import "time"
 
 type ServerConfig struct {
-	HTTP HTTPConfig
+	HTTP      HTTPConfig
+	WebSocket WebSocketConfig
 }
 
 type HTTPConfig struct {
@@ -11,3 +12,10 @@ type HTTPConfig struct {
 	Port    int
 	Timeout time.Duration
 }
+
+// WebSocketConfig selects how chat rooms are shared between instances.
+// Backend is "memory" (single instance) or "redis" (pub/sub across instances).
+type WebSocketConfig struct {
+	Backend string
+	Prefix  string
+}
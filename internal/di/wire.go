@@ -1,14 +1,27 @@
 package di
 
 import (
+	"fmt"
+	"io"
+
 	"thomas.vn/apartment_service/internal/config"
+	domainrepo "thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/infrastructure/attachmentadapter"
 	"thomas.vn/apartment_service/internal/infrastructure/fileadapter"
+	"thomas.vn/apartment_service/internal/infrastructure/filteradapter"
+	"thomas.vn/apartment_service/internal/infrastructure/mailadapter"
 	"thomas.vn/apartment_service/internal/repository"
+	cronjobs "thomas.vn/apartment_service/internal/server/cron/jobs"
 	"thomas.vn/apartment_service/internal/server/http/handler/ai"
 	"thomas.vn/apartment_service/internal/server/http/handler/articles"
+	"thomas.vn/apartment_service/internal/server/http/handler/audit"
 	xAuth "thomas.vn/apartment_service/internal/server/http/handler/auth"
 	"thomas.vn/apartment_service/internal/server/http/handler/chatgroup"
 	"thomas.vn/apartment_service/internal/server/http/handler/chatmessage"
+	"thomas.vn/apartment_service/internal/server/http/handler/mailoutbox"
+	"thomas.vn/apartment_service/internal/server/http/handler/mailtemplate"
+	"thomas.vn/apartment_service/internal/server/http/handler/moderation"
+	"thomas.vn/apartment_service/internal/server/http/handler/notification"
 	"thomas.vn/apartment_service/internal/server/http/handler/permission"
 	"thomas.vn/apartment_service/internal/server/http/handler/root"
 	xtotp "thomas.vn/apartment_service/internal/server/http/handler/totp"
@@ -18,7 +31,9 @@ import (
 	auth2 "thomas.vn/apartment_service/internal/usecase/auth"
 	"thomas.vn/apartment_service/internal/usecase/totp"
 	"thomas.vn/apartment_service/internal/usecase/user"
+	xcache "thomas.vn/apartment_service/pkg/cache"
 	xcloudinary "thomas.vn/apartment_service/pkg/cloudinary"
+	xcron "thomas.vn/apartment_service/pkg/cron"
 	xfile "thomas.vn/apartment_service/pkg/file"
 	xhttp "thomas.vn/apartment_service/pkg/http"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
@@ -31,6 +46,7 @@ import (
 type AppContainer struct {
 	HTTPHandler   xhttp.Handler
 	InMemoryQueue *xqueue.InMemoryQueue
+	CronJobs      []xcron.Job
 }
 
 func NewAppContainer(cfg *config.Config, logger *xlogger.Logger) (*AppContainer, func(), error) {
@@ -61,50 +77,85 @@ func NewAppContainer(cfg *config.Config, logger *xlogger.Logger) (*AppContainer,
 	tokenCfg := config.TokenConfig{AccessSecret: cfg.JWT.AccessSecret, AccessExpire: cfg.JWT.AccessExpire, RefreshSecret: cfg.JWT.RefreshSecret, RefreshExpire: cfg.JWT.RefreshExpire}
 
 	// Mailer is configured from YAML — credentials never hardcoded in source code.
-	mailerCfg := cfg.Mailer
-	senderDisplay := mailerCfg.FromName + " <" + mailerCfg.SMTP.User + ">"
-	mailer := mail.NewMailer(mail.SMTPConfig{
-		Host: mailerCfg.SMTP.Host,
-		Port: mailerCfg.SMTP.Port,
-		User: mailerCfg.SMTP.User,
-		Pass: mailerCfg.SMTP.Pass,
-	}, senderDisplay)
+	mailer, err := newMailer(cfg.Mailer)
+	if err != nil {
+		return nil, nil, err
+	}
 	googleOAuth := xgoogle.New(cfg.Auth.Google.ClientID, cfg.Auth.Google.ClientSecret, cfg.Auth.Google.CallbackURL)
 	cld, _ := xcloudinary.NewCloudinary(cfg.Cloudinary)
+	attachmentStorage := attachmentadapter.New(cld)
+	mailRenderer, err := mailadapter.NewRenderer()
+	if err != nil {
+		return nil, nil, err
+	}
+	unsubscribeLinks, err := mailadapter.NewUnsubscribeLinks(cfg.Mailer.Unsubscribe.URL, cfg.Mailer.Unsubscribe.Secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	wordFilter := filteradapter.NewWordList(cfg.ChatModeration.BannedWords, cfg.ChatModeration.Action)
+	hub := newWebSocketHub(cfg.Server.WebSocket, logger, redisCache)
+	if err := hub.Start(); err != nil {
+		return nil, nil, err
+	}
 
 	// === REPOSITORIES ===
 	userRepo := repository.NewUserRepository(logger, mysqlClient.DB)
 	aiRepo := repository.NewAiRepository(logger, httpClient, fileSvc, aiURLConfig.URL)
 	permissionRepo := repository.NewPermissionRepository(logger, mysqlClient.DB)
+	policyRepo := repository.NewPolicyRepository(logger, mysqlClient.DB)
 	chatMessageRepo := repository.NewChatMessageRepository(logger, mysqlClient.DB)
 	chatGroupRepo := repository.NewChatGroupRepository(logger, mysqlClient.DB)
+	chatMessageSearchRepo := repository.NewChatMessageSearchRepository(logger, esClient.Client)
 	tokenSvc := usecase.NewToken(tokenCfg)
 	articlesRepo := repository.NewArticlesRepository(logger, mysqlClient.DB)
+	auditRepo := repository.NewAuditRepository(logger, mysqlClient.DB)
+	retentionRepo := repository.NewRetentionRepository(logger, mysqlClient.DB)
+	moderationRepo := repository.NewModerationRepository(logger, mysqlClient.DB)
+	notificationRepo := repository.NewNotificationRepository(logger, mysqlClient.DB)
+	mailOutboxRepo := repository.NewMailOutboxRepository(logger, mysqlClient.DB)
+	notificationPrefRepo := repository.NewNotificationPreferenceRepository(logger, mysqlClient.DB)
+	txManager := repository.NewTransaction(mysqlClient.DB)
 
 	// === USECASES ===
-	userUC := user.NewUserUsecase(logger, userRepo, redisCache, fileSvc, inMemoryQueue)
-	chatMessageUC := usecase.NewChatMessageUsecase(logger, chatMessageRepo)
-	chatGroupUc := usecase.NewChatGroupUsecase(logger, chatGroupRepo)
-	authUC := auth2.NewAuthUsecase(logger, userRepo, tokenSvc, inMemoryQueue)
+	auditSvc := usecase.NewAuditService(logger, auditRepo)
+	notificationSvc := usecase.NewNotificationService(logger, notificationRepo, notificationPrefRepo, hub)
+	mailOutboxSvc := usecase.NewMailOutboxService(mailOutboxRepo, notificationPrefRepo)
+	auditUC := usecase.NewAuditUsecase(logger, auditRepo)
+	userUC := user.NewUserUsecase(logger, userRepo, redisCache, fileSvc, inMemoryQueue, auditSvc, hub, notificationSvc)
+	chatMessageUC := usecase.NewChatMessageUsecase(logger, chatMessageRepo, chatGroupRepo, chatMessageSearchRepo, hub, attachmentStorage, inMemoryQueue)
+	chatGroupUc := usecase.NewChatGroupUsecase(logger, chatGroupRepo, chatMessageUC, hub, hub, moderationRepo, userRepo, hub)
+	authUC := auth2.NewAuthUsecase(logger, userRepo, tokenSvc, txManager, mailOutboxSvc, notificationSvc)
 	aiUC := usecase.NewAiUsecase(logger, aiRepo, aiURLConfig.DownloadURL, inMemoryQueue)
-	permissionUC := usecase.NewPermissionUsecase(logger, permissionRepo)
-	mailUC := usecase.NewMailUsecase(mailer)
-	totpUc := totp.NewTotpUsecase(logger, userRepo)
-	chatWsUC := usecase.NewChatUcase(logger, chatGroupUc, chatMessageUC)
-	articleUc := usecase.NewArticlesUsecase(logger, articlesRepo)
+	permissionUC := usecase.NewPermissionUsecase(logger, permissionRepo, auditSvc)
+	policyUC := usecase.NewPolicyUsecase(logger, policyRepo)
+	mailUC := usecase.NewMailUsecase(mailer, mailRenderer, fileSvc, unsubscribeLinks)
+	mailOutboxUC := usecase.NewMailOutboxUsecase(logger, cfg.MailOutbox, mailOutboxRepo, mailUC, auditSvc)
+	totpUc := totp.NewTotpUsecase(logger, userRepo, auditSvc)
+	chatWsUC := usecase.NewChatUcase(logger, chatGroupUc, chatMessageUC, hub, wordFilter)
+	articleUc := usecase.NewArticlesUsecase(logger, articlesRepo, auditSvc)
+	retentionUC := usecase.NewRetentionUsecase(logger, retentionRepo, inMemoryQueue, cfg.Retention.Days)
+	moderationUC := usecase.NewModerationUsecase(logger, moderationRepo, userRepo, chatMessageRepo, chatGroupRepo, chatMessageUC)
+	notificationUC := usecase.NewNotificationUsecase(logger, notificationRepo, hub)
+	notificationPrefUC := usecase.NewNotificationPreferenceUsecase(logger, notificationPrefRepo, unsubscribeLinks)
+	chatDigestUC := usecase.NewChatDigestUsecase(logger, cfg.ChatDigest, chatGroupRepo, chatMessageRepo, hub, mailOutboxSvc)
 
 	// === HANDLERS ===
 	userHandler := xuser.NewHandler(logger, xuser.WithUserUsecase(userUC))
-	chatMessageHandler := chatmessage.NewHandler(logger, chatmessage.WithChatMessageUsecase(chatMessageUC))
-	chatGroupHandler := chatgroup.NewHandler(logger, chatgroup.WithChatGroupUsecase(chatGroupUc))
+	chatMessageHandler := chatmessage.NewHandler(logger, chatmessage.WithChatMessageUsecase(chatMessageUC), chatmessage.WithChatUsecase(chatWsUC))
+	chatGroupHandler := chatgroup.NewHandler(logger, chatgroup.WithChatGroupUsecase(chatGroupUc), chatgroup.WithChatUsecase(chatWsUC))
 	authHandler := xAuth.NewHandler(logger, xAuth.WithGoogleOAuth(googleOAuth), xAuth.WithAuthUsecase(authUC))
 	aiHandler := ai.NewAiHandler(logger, aiUC)
 	authMiddlewareHandler := xAuth.NewAuthMiddleware(logger, tokenSvc, userRepo)
 	permissionMiddlewareHandler := permission.NewPermissionMiddleware(logger, permissionUC)
+	policyMiddlewareHandler := permission.NewPolicyMiddleware(logger, policyUC)
 	tOtpHandler := xtotp.NewHandler(logger, xtotp.WithTotpUsecase(totpUc))
 	articleHandler := articles.NewHandler(logger, articles.WithArticleUsecase(articleUc))
 	permissionHandler := permission.NewHandler(logger, permission.WithPermissionUsecase(permissionUC))
-	hub := ws.NewHub()
+	auditHandler := audit.NewHandler(logger, audit.WithAuditUsecase(auditUC))
+	moderationHandler := moderation.NewHandler(logger, moderation.WithModerationUsecase(moderationUC))
+	notificationHandler := notification.NewHandler(logger, notification.WithNotificationUsecase(notificationUC), notification.WithNotificationPreferenceUsecase(notificationPrefUC))
+	mailTemplateHandler := mailtemplate.NewHandler(logger, mailtemplate.WithMailUsecase(mailUC))
+	mailOutboxHandler := mailoutbox.NewHandler(logger, mailoutbox.WithMailOutboxUsecase(mailOutboxUC))
 	wsServer := &ws.Server{Hub: hub, ChatUC: chatWsUC, Token: tokenSvc}
 	wsHandler := ws.NewHandler(wsServer)
 
@@ -116,39 +167,108 @@ func NewAppContainer(cfg *config.Config, logger *xlogger.Logger) (*AppContainer,
 		aiHandler,
 		authMiddlewareHandler,
 		permissionMiddlewareHandler,
+		policyMiddlewareHandler,
 		tOtpHandler,
 		chatMessageHandler,
 		chatGroupHandler,
 		wsHandler,
 		articleHandler,
 		permissionHandler,
+		auditHandler,
+		moderationHandler,
+		notificationHandler,
+		mailTemplateHandler,
+		mailOutboxHandler,
 	)
 
 	//========= Create job ==============
-	mailJob := queuejobs.NewMailJob(logger, mailUC)
 	uploadLocalAvatarJob := queuejobs.NewUploadUserAvatarJob(logger, fileImpl, userUC)
 	uploadCloudAvatarJob := queuejobs.NewUploadAvatarCloudJob(logger, cld, userUC)
 	deleteCloudAssetJob := queuejobs.NewDeleteCloudinaryAssetJob(logger, cld)
-	inMemoryQueue.RegisterJobs([]xqueue.Job{mailJob, uploadLocalAvatarJob, uploadCloudAvatarJob, deleteCloudAssetJob})
+	uploadChatAttachmentJob := queuejobs.NewUploadChatAttachmentJob(logger, attachmentStorage, chatMessageUC)
+	indexChatMessageJob := queuejobs.NewIndexChatMessageJob(logger, chatMessageUC)
+	inMemoryQueue.RegisterJobs([]xqueue.Job{uploadLocalAvatarJob, uploadCloudAvatarJob, deleteCloudAssetJob, uploadChatAttachmentJob, indexChatMessageJob})
 
 	if err := inMemoryQueue.Start(); err != nil {
 		return nil, nil, err
 	}
+
+	//========= Create cron job ==============
+	purgeDeletedJob := cronjobs.NewPurgeDeletedJob(logger, cfg.Retention, retentionUC)
+	chatDigestJob := cronjobs.NewChatDigestJob(logger, cfg.ChatDigest, chatDigestUC)
+	mailOutboxJob := cronjobs.NewMailOutboxJob(logger, cfg.MailOutbox, mailOutboxUC)
 	// === CLEANUP FUNCTION ===
 	cleanup := func() {
 		if err := mysqlClient.Close(); err != nil {
 			logger.Error("Close MySQL client failed", xlogger.Error(err))
 		}
+		if err := hub.Close(); err != nil {
+			logger.Error("Close WebSocket hub failed", xlogger.Error(err))
+		}
 		if err := redisCache.Close(); err != nil {
 			logger.Error("Close Redis cache failed", xlogger.Error(err))
 		}
 		if err := esClient.Close(); err != nil {
 			logger.Error("Close ElasticSearch client failed", xlogger.Error(err))
 		}
+		if err := mailer.Close(); err != nil {
+			logger.Error("Close mailer failed", xlogger.Error(err))
+		}
 	}
 
 	return &AppContainer{
 		HTTPHandler:   httpHandler,
 		InMemoryQueue: inMemoryQueue,
+		CronJobs:      []xcron.Job{purgeDeletedJob, chatDigestJob, mailOutboxJob},
 	}, cleanup, nil
 }
+
+// newWebSocketHub builds the chat hub for the configured backend.
+func newWebSocketHub(cfg config.WebSocketConfig, logger *xlogger.Logger, redisCache *xcache.RedisCache) *ws.Hub {
+	if cfg.Backend != "redis" {
+		return ws.NewHub()
+	}
+
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = "ws"
+	}
+	return ws.NewHub(ws.WithBackend(ws.NewRedisBackend(logger, redisCache.Client(), prefix)))
+}
+
+// mailTransport is a mail backend that holds resources until closed.
+type mailTransport interface {
+	domainrepo.MailRepository
+	io.Closer
+}
+
+// newMailer builds the mail transport selected in the config.
+func newMailer(cfg config.MailerConfig) (mailTransport, error) {
+	from := mail.Sender{Name: cfg.FromName, Email: cfg.SMTP.User}
+	if from.Email == "" {
+		from.Email = "no-reply@localhost"
+	}
+
+	switch cfg.Transport {
+	case "", "smtp":
+		return mail.NewMailer(mail.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			User:     cfg.SMTP.User,
+			Pass:     cfg.SMTP.Pass,
+			TLS:      cfg.SMTP.TLS,
+			Timeout:  cfg.SMTP.Timeout,
+			PoolSize: cfg.SMTP.PoolSize,
+		}, cfg.FromName), nil
+	case "file":
+		dir := cfg.File.Dir
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		return mail.NewFileMailer(dir, from)
+	case "memory":
+		return mail.NewMemoryMailer(from), nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", cfg.Transport)
+	}
+}
@@ -0,0 +1,29 @@
+package consts
+
+// Audit actions recorded by the audit log.
+const (
+	AuditActionUserUpdate       = "user.update"
+	AuditActionUserDelete       = "user.delete"
+	AuditActionUserRestore      = "user.restore"
+	AuditActionArticleDelete    = "article.delete"
+	AuditActionArticleRestore   = "article.restore"
+	AuditActionTotpEnable       = "totp.enable"
+	AuditActionTotpDisable      = "totp.disable"
+	AuditActionPermissionCreate = "permission.create"
+	AuditActionPermissionUpdate = "permission.update"
+	AuditActionRoleAssign       = "role.assign"
+	AuditActionRoleRevoke       = "role.revoke"
+	AuditActionMailResend       = "mail.resend"
+)
+
+// Audit target types.
+const (
+	AuditTargetUser       = "user"
+	AuditTargetPermission = "permission"
+	AuditTargetUserRole   = "user_role"
+	AuditTargetArticle    = "article"
+	AuditTargetMail       = "mail"
+)
+
+// AuditExportLimit caps the number of rows returned by a CSV export.
+const AuditExportLimit = 10000
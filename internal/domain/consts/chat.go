@@ -0,0 +1,68 @@
+package consts
+
+// Chat group member roles.
+const (
+	ChatGroupRoleMember = "member"
+	ChatGroupRoleAdmin  = "admin"
+)
+
+// Chat message types. System messages record group changes; their text is
+// a JSON encoded chatmessage.SystemEvent.
+const (
+	ChatMessageTypeText   = "text"
+	ChatMessageTypeSystem = "system"
+)
+
+// Chat message attachment kinds and upload states.
+const (
+	ChatAttachmentKindImage    = "image"
+	ChatAttachmentKindDocument = "document"
+
+	ChatAttachmentStatusPending = "pending"
+	ChatAttachmentStatusReady   = "ready"
+)
+
+// Chat group events carried by system messages.
+const (
+	ChatEventMembersAdded  = "members_added"
+	ChatEventMemberRemoved = "member_removed"
+	ChatEventMemberLeft    = "member_left"
+	ChatEventRoleChanged   = "role_changed"
+	ChatEventGroupRenamed  = "group_renamed"
+	ChatEventGroupDeleted  = "group_deleted"
+)
+
+// WebSocket event types pushed by the server.
+const (
+	WSEventSendMessage = "SEND_MESSAGE"
+	WSEventMessageRead = "MESSAGE_READ"
+	// WSEventEditMessage, WSEventDeleteMessage and WSEventReact share their
+	// names with the client messages that trigger them.
+	WSEventEditMessage   = "EDIT_MESSAGE"
+	WSEventDeleteMessage = "DELETE_MESSAGE"
+	WSEventReact         = "REACT"
+	// WSEventAttachmentReady tells the room an attachment finished uploading.
+	WSEventAttachmentReady = "ATTACHMENT_READY"
+)
+
+// WebSocket event types pushed to a single user on all their connections.
+const (
+	// WSEventChatGroupJoined tells a user they were added to a chat group,
+	// which they may then join as a room.
+	WSEventChatGroupJoined = "CHAT_GROUP_JOINED"
+	// WSEventAvatarUpdated tells a user their avatar upload finished.
+	WSEventAvatarUpdated = "AVATAR_UPDATED"
+)
+
+// Chat message report states.
+const (
+	ChatReportStatusPending   = "pending"
+	ChatReportStatusRemoved   = "removed"
+	ChatReportStatusDismissed = "dismissed"
+)
+
+// What the word list filter does with a message containing a banned word.
+const (
+	ChatFilterActionMask   = "mask"
+	ChatFilterActionReject = "reject"
+)
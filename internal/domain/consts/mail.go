@@ -0,0 +1,24 @@
+package consts
+
+// Mail locales. Users without a preference get LocaleDefault.
+const (
+	LocaleVI      = "vi"
+	LocaleEN      = "en"
+	LocaleDefault = LocaleVI
+)
+
+// Mail template names.
+const (
+	MailTemplateLogin      = "login"
+	MailTemplateRegister   = "register"
+	MailTemplateChatDigest = "chat_digest"
+)
+
+// Mail outbox statuses. Mail waiting for a retry is pending again; failed
+// means it ran out of attempts.
+const (
+	MailStatusPending = "pending"
+	MailStatusSending = "sending"
+	MailStatusSent    = "sent"
+	MailStatusFailed  = "failed"
+)
@@ -0,0 +1,40 @@
+package consts
+
+// Notification types.
+const (
+	NotificationTypeLoginAlert    = "login_alert"
+	NotificationTypeAvatarUpdated = "avatar_updated"
+)
+
+// Notification categories. Users choose per category and channel what they
+// receive; mail outside these categories, such as the registration mail,
+// is always sent.
+const (
+	NotificationCategorySecurity = "security"
+	NotificationCategoryAccount  = "account"
+	NotificationCategoryChat     = "chat"
+)
+
+// Notification channels.
+const (
+	NotificationChannelEmail     = "email"
+	NotificationChannelInApp     = "in_app"
+	NotificationChannelWebSocket = "websocket"
+)
+
+var (
+	NotificationCategories = []string{NotificationCategorySecurity, NotificationCategoryAccount, NotificationCategoryChat}
+	NotificationChannels   = []string{NotificationChannelEmail, NotificationChannelInApp, NotificationChannelWebSocket}
+)
+
+// WebSocket events that keep the notification inbox in sync.
+const (
+	// WSEventNotification carries a new notification and the unread count.
+	WSEventNotification = "NOTIFICATION"
+	// WSEventNotificationBadge carries the unread count after notifications
+	// were read on another device.
+	WSEventNotificationBadge = "NOTIFICATION_BADGE"
+	// WSEventNotificationAlert carries a notification that was not stored
+	// because the user turned off the inbox for its category.
+	WSEventNotificationAlert = "NOTIFICATION_ALERT"
+)
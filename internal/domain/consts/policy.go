@@ -0,0 +1,13 @@
+package consts
+
+// Policy names used by routes to declare attribute-based access rules.
+// They are evaluated after the role permission check and allow the
+// request when the caller satisfies at least one of the declared policies.
+const (
+	// PolicySelf allows access when the resource is the caller's own user record.
+	PolicySelf = "self"
+	// PolicyArticleOwner allows access when the caller authored the article.
+	PolicyArticleOwner = "article_owner"
+	// PolicyChatGroupMember allows access when the caller is a member of the chat group.
+	PolicyChatGroupMember = "chat_group_member"
+)
@@ -7,15 +7,13 @@ type MessageType = string
 
 const (
 	// Job names (used when registering jobs with the queue)
-	UploadFileJobName        = "upload_file_job"
-	MailJobName              = "mail_job"
-	UploadUserAvatarJobName  = "upload_local_avatar_file_job"
-	UploadAvatarCloudJobName = "upload_cloud_avatar_file_job"
+	UploadFileJobName           = "upload_file_job"
+	UploadUserAvatarJobName     = "upload_local_avatar_file_job"
+	UploadAvatarCloudJobName    = "upload_cloud_avatar_file_job"
+	UploadChatAttachmentJobName = "upload_chat_attachment_job"
+	IndexChatMessageJobName     = "index_chat_message_job"
 
 	// Message types (used when publishing messages to the queue)
-	MailJobType       MessageType = "mail_job"
-	QueueMailLogin    MessageType = "mail_login"
-	QueueMailRegister MessageType = "mail_register"
 
 	// Upload file local
 	UploadUserAvatarJobType MessageType = "upload_local_avatar_file_job"
@@ -23,4 +21,8 @@ const (
 	// Upload file cloud
 	UploadAvatarCloudJobType     MessageType = "upload_cloud_avatar_file_job"
 	DeleteCloudinaryAssetJobType MessageType = "delete_cloud_asset_job"
+	UploadChatAttachmentJobType  MessageType = "upload_chat_attachment_job"
+
+	// Search index
+	IndexChatMessageJobType MessageType = "index_chat_message_job"
 )
@@ -9,3 +9,12 @@ const (
 	DefaultUserRoleID  = 2
 	UserAdmin          = 1
 )
+
+const (
+	// GlobalBuildingID marks a role assignment that is not scoped to a building.
+	GlobalBuildingID int64 = 0
+	// BuildingScopeHeader carries the building a request is scoped to.
+	BuildingScopeHeader = "X-Building-ID"
+	// BuildingScopeParam is the path/query parameter carrying the building scope.
+	BuildingScopeParam = "building_id"
+)
@@ -7,31 +7,46 @@ import (
 )
 
 type Articles struct {
-	ID        int        `json:"id"`
-	Title     string     `json:"title"`
-	Content   string     `json:"content"`
-	ImageURL  string     `json:"image_url"`
-	Views     int        `json:"views"`
-	UserID    int        `json:"user_id"`
-	DeletedBy int        `json:"deleted_by"`
-	IsDeleted int        `json:"is_deleted"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at"`
+	ID         int        `json:"id"`
+	Title      string     `json:"title"`
+	Content    string     `json:"content"`
+	ImageURL   string     `json:"image_url"`
+	Views      int        `json:"views"`
+	UserID     int        `json:"user_id"`
+	BuildingID int64      `json:"building_id"`
+	DeletedBy  int        `json:"deleted_by"`
+	IsDeleted  int        `json:"is_deleted"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+type ArticleIDRequest struct {
+	ID int64 `json:"id" param:"id" swaggerignore:"true" validate:"required,gt=0"`
 }
 
 type ListArticleRequest struct {
 	query.PaginationOptions
 	query.DateRangeOptions
 	query.SortOptions
-	IsDeleted int    `query:"is_deleted"`
+	IsDeleted int    `query:"is_deleted" validate:"omitempty,oneof=0 1"`
 	Filters   string `query:"filters"`
 }
 
-type ArticlesFilters struct {
-	ID      int    `query:"id"`
-	Content string `query:"content"`
-	Views   int    `query:"views"`
+// ArticleQuerySchema whitelists the filters and sort keys of the article list.
+var ArticleQuerySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":          {Column: "id", Type: query.TypeInt, Operators: []query.Operator{query.OpEq, query.OpIn}, Sortable: true},
+		"title":       {Column: "title", Operators: []query.Operator{query.OpLike, query.OpEq}, Sortable: true},
+		"content":     {Column: "content", Operators: []query.Operator{query.OpLike}},
+		"views":       {Column: "views", Type: query.TypeInt, Operators: []query.Operator{query.OpEq, query.OpRange}, Sortable: true},
+		"user_id":     {Column: "user_id", Type: query.TypeInt, Operators: []query.Operator{query.OpEq, query.OpIn}},
+		"building_id": {Column: "building_id", Type: query.TypeInt, Operators: []query.Operator{query.OpEq, query.OpIn}},
+		"created_at":  {Column: "created_at", Type: query.TypeDate, Operators: []query.Operator{query.OpRange}, Sortable: true},
+		"updated_at":  {Column: "updated_at", Type: query.TypeDate, Operators: []query.Operator{query.OpRange}, Sortable: true},
+	},
+	Key:          "id",
+	DefaultRange: "created_at",
 }
 
 func (Articles) TableName() string {
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"thomas.vn/apartment_service/pkg/query"
+)
+
+// AuditLog is an append-only record of a security-relevant or admin action.
+type AuditLog struct {
+	ID         int64     `json:"id"`
+	ActorID    int       `json:"actor_id"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   int64     `json:"target_id"`
+	Changes    string    `json:"changes" example:"{\"role_id\":{\"before\":2,\"after\":3}}"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	RequestID  string    `json:"request_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuditEntry describes an action to record. Before and After are snapshots
+// of the target; only the fields that differ are stored.
+type AuditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   int64
+	Before     interface{}
+	After      interface{}
+}
+
+// AuditChange is the before/after value of a single changed field.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type ListAuditLogRequest struct {
+	query.PaginationOptions
+	query.DateRangeOptions
+
+	ActorID    int    `query:"actor_id" validate:"omitempty,gt=0"`
+	Action     string `query:"action"`
+	TargetType string `query:"target_type"`
+	TargetID   int64  `query:"target_id" validate:"omitempty,gt=0"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -11,6 +11,7 @@ type ChatGroup struct {
 	KeyForChatOne *ChatOneKey `json:"key_for_chat_one"`
 	Name          string      `json:"name"`
 	OwnerID       int64       `json:"owner_id"`
+	BuildingID    int64       `json:"building_id"`
 	DeletedBy     int         `json:"deleted_by"`
 	IsDeleted     int         `json:"is_deleted"`
 	CreatedAt     time.Time   `json:"created_at"`
@@ -27,22 +28,98 @@ type ListChatGroupRequest struct {
 }
 
 type CreateChatGroupRequest struct {
-	Name          string  `json:"name"`
+	Name          string  `json:"name" validate:"max=255" example:"Block A residents"`
 	OwnerID       int64   `json:"-"`
-	TargetUserIDs []int64 `json:"target_user_ids"`
+	TargetUserIDs []int64 `json:"target_user_ids" validate:"required,min=1,max=100,dive,gt=0"`
+	// BuildingID places the group in one of the owner's buildings. When
+	// empty, the owner's only building is used, if any.
+	BuildingID int64 `json:"building_id" validate:"omitempty,gte=0" example:"1"`
 }
 type CreateMemberRequest struct {
 	ChatGroupID int64
 	UserIDs     []int64
+	Role        string
 }
+
+type ChatGroupIDRequest struct {
+	ID int64 `json:"id" param:"id" swaggerignore:"true" validate:"required,gt=0"`
+}
+
+type AddMembersRequest struct {
+	ChatGroupIDRequest
+	UserIDs []int64 `json:"user_ids" validate:"required,min=1,max=100,dive,gt=0"`
+}
+
+type MemberRequest struct {
+	ChatGroupIDRequest
+	UserID int64 `json:"user_id" param:"userId" swaggerignore:"true" validate:"required,gt=0"`
+}
+
+type UpdateMemberRoleRequest struct {
+	MemberRequest
+	Role string `json:"role" validate:"required,oneof=member admin" example:"admin"`
+}
+
+type RenameChatGroupRequest struct {
+	ChatGroupIDRequest
+	Name string `json:"name" validate:"required,max=255" example:"Block A residents"`
+}
+type MarkReadRequest struct {
+	ChatGroupIDRequest
+	// MessageID defaults to the latest message of the group.
+	MessageID int64 `json:"message_id" validate:"omitempty,gt=0" example:"120"`
+}
+
+// ReadReceipt tells the room how far a member has read.
+type ReadReceipt struct {
+	ChatGroupID       int64 `json:"chat_group_id"`
+	UserID            int64 `json:"user_id"`
+	LastReadMessageID int64 `json:"last_read_message_id"`
+}
+
+// MuteRequest mutes or unmutes notifications of a chat group for the
+// caller. Without Until a mute lasts until it is lifted.
+type MuteRequest struct {
+	ChatGroupIDRequest
+	Muted bool       `json:"muted" example:"true"`
+	Until *time.Time `json:"until" example:"2026-01-02T15:04:05Z"`
+}
+
+type MuteSetting struct {
+	ChatGroupID int64      `json:"chat_group_id"`
+	MutedUntil  *time.Time `json:"muted_until"`
+}
+
+// AckRequest acknowledges delivery of the messages of a chat group up to
+// Seq. Acknowledgements are cumulative.
+type AckRequest struct {
+	ChatGroupIDRequest
+	Seq int64 `json:"seq" validate:"required,gt=0"`
+}
+
+// ListResponse is an entry of the caller's conversation list.
 type ListResponse struct {
-	ID               int64            `json:"id"`
-	Name             string           `json:"name"`
-	ChatGroupMembers []MemberResponse `json:"ChatGroupMembers"`
+	ID                int64            `json:"id"`
+	Name              string           `json:"name"`
+	UnreadCount       int64            `json:"unread_count"`
+	LastReadMessageID int64            `json:"last_read_message_id"`
+	LastMessage       *LastMessage     `json:"last_message"`
+	LastActivityAt    time.Time        `json:"last_activity_at"`
+	ChatGroupMembers  []MemberResponse `json:"ChatGroupMembers"`
+}
+
+// LastMessage is the preview of the latest message of a conversation.
+type LastMessage struct {
+	ID          int64     `json:"id"`
+	MessageText string    `json:"message_text"`
+	MessageType string    `json:"message_type"`
+	SenderID    int64     `json:"sender_id"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type MemberResponse struct {
 	UserID int64        `json:"user_id"`
+	Role   string       `json:"role"`
 	Users  UserResponse `json:"Users"`
 }
 
@@ -55,10 +132,45 @@ type Row struct {
 	GroupID   int64
 	GroupName string
 	UserID    int64
+	Role      string
 	FullName  string
 	Avatar    string `gorm:"column:avatar"`
 }
 
+type ConversationRow struct {
+	GroupID             int64
+	GroupName           string
+	LastReadMessageID   *int64
+	LastMessageID       *int64
+	LastMessageText     string
+	LastMessageType     string
+	LastMessageSenderID int64
+	LastMessageAt       time.Time
+	LastActivityAt      time.Time
+	UnreadCount         int64
+}
+
+// DigestRow is a member with unread messages that were not mailed yet.
+type DigestRow struct {
+	UserID      int64
+	Email       string
+	FullName    string
+	Locale      string
+	ChatGroupID int64
+	GroupName   string
+	// AfterMessageID is the newest message already read or mailed.
+	AfterMessageID int64
+	LastMessageID  int64
+	UnreadCount    int64
+}
+
+// DigestCursor is the last member group handled by a digest run. The next
+// run continues after it.
+type DigestCursor struct {
+	UserID      int64
+	ChatGroupID int64
+}
+
 func (ChatGroup) TableName() string {
 	return "chat_groups"
 }
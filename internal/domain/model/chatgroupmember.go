@@ -3,14 +3,24 @@ package model
 import "time"
 
 type ChatGroupMembers struct {
-	ID          int        `json:"id"`
-	UserID      int64      `json:"user_id"`
-	ChatGroupID int64      `json:"chat_group_id"`
-	DeletedBy   int        `json:"deleted_by"`
-	IsDeleted   int        `json:"is_deleted"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at"`
+	ID          int    `json:"id"`
+	UserID      int64  `json:"user_id"`
+	ChatGroupID int64  `json:"chat_group_id"`
+	Role        string `json:"role"`
+	// LastReadMessageID is the newest message the member has read.
+	LastReadMessageID *int64     `json:"last_read_message_id"`
+	DeletedBy         int        `json:"deleted_by"`
+	IsDeleted         int        `json:"is_deleted"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at"`
+	// LastDeliveredSeq is the newest message seq acknowledged by a client
+	// of the member.
+	LastDeliveredSeq int64 `json:"last_delivered_seq"`
+	// MutedUntil silences notifications of the chat group until then.
+	MutedUntil *time.Time `json:"muted_until"`
+	// LastNotifiedMessageID is the newest message mailed in a digest.
+	LastNotifiedMessageID *int64 `json:"last_notified_message_id"`
 }
 
 func (ChatGroupMembers) TableName() string {
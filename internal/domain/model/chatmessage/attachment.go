@@ -0,0 +1,87 @@
+package chatmessage
+
+import (
+	"fmt"
+	"mime/multipart"
+	"time"
+)
+
+// Attachment is a file uploaded to a chat group. It is created pending when
+// the upload is accepted and linked to a message when the message is sent.
+type Attachment struct {
+	ID            int       `json:"id"`
+	ChatMessageID *int      `json:"chat_message_id"`
+	ChatGroupID   int       `json:"chat_group_id"`
+	UploadedBy    int       `json:"uploaded_by"`
+	Kind          string    `json:"kind"`
+	Status        string    `json:"status"`
+	FileName      string    `json:"file_name"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	StorageKey    string    `json:"-"`
+	ResourceType  string    `json:"-"`
+	Format        string    `json:"-"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (Attachment) TableName() string {
+	return "chat_message_attachments"
+}
+
+type AttachmentResponse struct {
+	ID            int    `json:"id"`
+	ChatMessageID *int   `json:"chat_message_id"`
+	ChatGroupID   int    `json:"chat_group_id"`
+	FileName      string `json:"file_name"`
+	ContentType   string `json:"content_type"`
+	Size          int64  `json:"size"`
+	Kind          string `json:"kind" example:"image"`
+	Status        string `json:"status" example:"ready"`
+	Width         int    `json:"width,omitempty"`
+	Height        int    `json:"height,omitempty"`
+	// ThumbnailURL is a signed preview URL, only set on ready images.
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	// DownloadURL checks the membership of the caller before redirecting
+	// to the file.
+	DownloadURL string `json:"download_url"`
+	// StorageKey is kept for building the thumbnail URL.
+	StorageKey string `json:"-" swaggerignore:"true"`
+}
+
+func (a *Attachment) ToResponse() *AttachmentResponse {
+	return &AttachmentResponse{
+		ID:            a.ID,
+		ChatMessageID: a.ChatMessageID,
+		ChatGroupID:   a.ChatGroupID,
+		FileName:      a.FileName,
+		ContentType:   a.ContentType,
+		Size:          a.Size,
+		Kind:          a.Kind,
+		Status:        a.Status,
+		Width:         a.Width,
+		Height:        a.Height,
+		DownloadURL:   fmt.Sprintf("/api/chat-message/attachments/%d/download", a.ID),
+		StorageKey:    a.StorageKey,
+	}
+}
+
+type UploadAttachmentRequest struct {
+	ChatGroupID int                   `form:"chat_group_id" validate:"required,gt=0"`
+	File        *multipart.FileHeader `form:"file" swaggerignore:"true"`
+	// Kind is set by the handler from the validated file type.
+	Kind string `form:"-" swaggerignore:"true"`
+}
+
+type AttachmentIDRequest struct {
+	ID int `param:"id" validate:"required,gt=0"`
+}
+
+type UploadAttachmentQueuePayload struct {
+	AttachmentID int
+	ChatGroupID  int
+	Image        bool
+	File         *multipart.FileHeader
+}
@@ -11,11 +11,16 @@ type ChatMessage struct {
 	ChatGroupID  int        `json:"chat_group_id"`
 	UserIDSender int        `json:"user_id_sender"`
 	MessageText  string     `json:"message_text"`
-	DeletedBy    int        `json:"deleted_by"`
-	IsDeleted    int        `json:"is_deleted"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	DeletedAt    *time.Time `json:"deleted_at"`
+	MessageType  string     `json:"message_type"`
+	ParentID     *int       `json:"parent_id"`
+	EditedAt     *time.Time `json:"edited_at"`
+	// Seq numbers the messages of a chat group without gaps, from 1.
+	Seq       int64      `json:"seq"`
+	DeletedBy int        `json:"deleted_by"`
+	IsDeleted int        `json:"is_deleted"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
 }
 
 type ListChatMessageRequest struct {
@@ -23,21 +28,124 @@ type ListChatMessageRequest struct {
 	query.DateRangeOptions
 	query.SortOptions
 
-	ChatGroupID int `json:"-" query:"-"`
+	Filters string `query:"filters"`
+}
+
+// ChatMessageQuerySchema whitelists the filters and sort keys of the chat
+// message list. chatGroupID only supports eq so that the chat group member
+// policy, which reads the same filter, covers every returned message.
+var ChatMessageQuerySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"chatGroupID":  {Column: "cm.chat_group_id", Type: query.TypeInt, Operators: []query.Operator{query.OpEq}},
+		"parentID":     {Column: "cm.parent_id", Type: query.TypeInt, Operators: []query.Operator{query.OpEq}},
+		"id":           {Column: "cm.id", Type: query.TypeInt, Operators: []query.Operator{query.OpEq}, Sortable: true},
+		"message_text": {Column: "cm.message_text", Operators: []query.Operator{query.OpLike}},
+		"created_at":   {Column: "cm.created_at", Type: query.TypeDate, Operators: []query.Operator{query.OpRange}, Sortable: true},
+	},
+	Key:          "id",
+	DefaultRange: "created_at",
 }
 
 type CreateChatMessageRequest struct {
-	ChatGroupID  int    `json:"chat_group_id" validate:"required"`
-	UserIDSender int    `json:"user_id_sender" validate:"required"`
-	MessageText  string `json:"message_text" validate:"required"`
+	ChatGroupID int `json:"chat_group_id" validate:"required,gt=0"`
+	// UserIDSender is the authenticated caller.
+	UserIDSender int `json:"-"`
+	// MessageText may be empty when the message carries attachments.
+	MessageText string `json:"message_text"`
+	// ParentID makes the message a reply in the thread of that message.
+	ParentID *int `json:"parent_id" validate:"omitempty,gt=0"`
+	// AttachmentIDs are uploads of the sender to link to the message.
+	AttachmentIDs []int `json:"attachment_ids" validate:"omitempty,max=10,dive,gt=0"`
+}
+
+type ChatMessageIDRequest struct {
+	ID int `json:"id" param:"id" swaggerignore:"true" validate:"required,gt=0"`
+}
+
+type EditChatMessageRequest struct {
+	ChatMessageIDRequest
+	MessageText string `json:"message_text" validate:"required" example:"Hello again"`
+}
+
+type ReactRequest struct {
+	ChatMessageIDRequest
+	Emoji string `json:"emoji" validate:"required,max=32" example:"👍"`
+}
+
+// SystemEvent is the content of a system message describing a change to
+// the chat group.
+type SystemEvent struct {
+	Event   string  `json:"event"`
+	ActorID int64   `json:"actorId"`
+	UserIDs []int64 `json:"userIds,omitempty"`
+	Role    string  `json:"role,omitempty"`
+	Name    string  `json:"name,omitempty"`
 }
 
 type Response struct {
 	ID          int       `json:"id"`
+	Seq         int64     `json:"seq"`
 	MessageText string    `json:"message_text"`
+	MessageType string    `json:"message_type"`
 	CreatedAt   time.Time `json:"created_at"`
 	ChatGroupID int       `json:"chat_group_id"`
 	Sender      Sender    `json:"sender"`
+	// ParentID is set on replies.
+	ParentID    *int                  `json:"parent_id"`
+	EditedAt    *time.Time            `json:"edited_at"`
+	IsDeleted   bool                  `json:"is_deleted"`
+	ReplyCount  int64                 `json:"reply_count"`
+	Reactions   []ReactionCount       `json:"reactions"`
+	Attachments []*AttachmentResponse `json:"attachments"`
+}
+
+// Replay is a batch of messages a client missed, in sequence order.
+type Replay struct {
+	ChatGroupID int         `json:"chat_group_id"`
+	Messages    []*Response `json:"messages"`
+	// LastSeq is the seq to resume from for the next batch.
+	LastSeq int64 `json:"last_seq"`
+	HasMore bool  `json:"has_more"`
+}
+
+// ReactionCount is the number of users who reacted with an emoji.
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int64  `json:"count"`
+}
+
+// ReactionEvent is pushed to the room when a reaction is added or removed.
+type ReactionEvent struct {
+	ChatMessageID int             `json:"chat_message_id"`
+	ChatGroupID   int             `json:"chat_group_id"`
+	UserID        int             `json:"user_id"`
+	Emoji         string          `json:"emoji"`
+	Added         bool            `json:"added"`
+	Reactions     []ReactionCount `json:"reactions"`
+}
+
+// DeletedEvent is pushed to the room when a message is deleted.
+type DeletedEvent struct {
+	ChatMessageID int `json:"chat_message_id"`
+	ChatGroupID   int `json:"chat_group_id"`
+	DeletedBy     int `json:"deleted_by"`
+}
+
+// Edit is a previous version of an edited message.
+type Edit struct {
+	ID            int       `json:"id"`
+	ChatMessageID int       `json:"chat_message_id"`
+	MessageText   string    `json:"message_text"`
+	EditedBy      int       `json:"edited_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type Reaction struct {
+	ID            int       `json:"id"`
+	ChatMessageID int       `json:"chat_message_id"`
+	UserID        int       `json:"user_id"`
+	Emoji         string    `json:"emoji"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type Sender struct {
@@ -49,8 +157,13 @@ type Sender struct {
 
 type Row struct {
 	ID          int
+	Seq         int64
 	ChatGroupID int
 	MessageText string
+	MessageType string
+	ParentID    *int
+	EditedAt    *time.Time
+	IsDeleted   int
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 
@@ -63,3 +176,39 @@ type Row struct {
 func (ChatMessage) TableName() string {
 	return "chat_messages"
 }
+
+func (Edit) TableName() string {
+	return "chat_message_edits"
+}
+
+func (Reaction) TableName() string {
+	return "chat_message_reactions"
+}
+
+// ToResponse maps the row to the API shape. Deleted messages keep their
+// place in the history but lose their text.
+func (r *Row) ToResponse() *Response {
+	resp := &Response{
+		ID:          r.ID,
+		Seq:         r.Seq,
+		ChatGroupID: r.ChatGroupID,
+		MessageText: r.MessageText,
+		MessageType: r.MessageType,
+		CreatedAt:   r.CreatedAt,
+		ParentID:    r.ParentID,
+		EditedAt:    r.EditedAt,
+		IsDeleted:   r.IsDeleted != 0,
+		Reactions:   []ReactionCount{},
+		Attachments: []*AttachmentResponse{},
+		Sender: Sender{
+			ID:       r.UserID,
+			FullName: r.FullName,
+			Avatar:   r.Avatar,
+			RoleID:   r.RoleID,
+		},
+	}
+	if resp.IsDeleted {
+		resp.MessageText = ""
+	}
+	return resp
+}
@@ -0,0 +1,57 @@
+package chatmessage
+
+import (
+	"time"
+
+	"thomas.vn/apartment_service/pkg/query"
+)
+
+// SearchDocument is a chat message as stored in the search index.
+type SearchDocument struct {
+	ID           int        `json:"id"`
+	ChatGroupID  int        `json:"chat_group_id"`
+	UserIDSender int        `json:"user_id_sender"`
+	ParentID     *int       `json:"parent_id,omitempty"`
+	Seq          int64      `json:"seq"`
+	MessageText  string     `json:"message_text"`
+	CreatedAt    time.Time  `json:"created_at"`
+	EditedAt     *time.Time `json:"edited_at,omitempty"`
+}
+
+func (m *ChatMessage) ToSearchDocument() *SearchDocument {
+	return &SearchDocument{
+		ID:           m.ID,
+		ChatGroupID:  m.ChatGroupID,
+		UserIDSender: m.UserIDSender,
+		ParentID:     m.ParentID,
+		Seq:          m.Seq,
+		MessageText:  m.MessageText,
+		CreatedAt:    m.CreatedAt,
+		EditedAt:     m.EditedAt,
+	}
+}
+
+type SearchRequest struct {
+	query.PaginationOptions
+
+	Q string `query:"q" validate:"required,max=200" example:"hóa đơn điện"`
+	// ChatGroupID narrows the search to one chat group of the caller.
+	ChatGroupID int `query:"chat_group_id" validate:"omitempty,gt=0" example:"12"`
+}
+
+// SearchHit is a message matched by the search index.
+type SearchHit struct {
+	ID         int
+	Highlights []string
+}
+
+type SearchResult struct {
+	Message *Response `json:"message"`
+	// Highlights are HTML-escaped snippets of the message with the matches
+	// wrapped in <em> tags.
+	Highlights []string `json:"highlights"`
+}
+
+type IndexQueuePayload struct {
+	ChatMessageID int
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+
+	"thomas.vn/apartment_service/pkg/query"
+)
+
+// RenderedMail is a mail template rendered for one locale.
+type RenderedMail struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// MailTemplate describes a mail template and the locales it is written in.
+type MailTemplate struct {
+	Name    string   `json:"name" example:"login"`
+	Locales []string `json:"locales" example:"vi,en"`
+}
+
+type PreviewMailRequest struct {
+	Name   string `json:"name" param:"name" swaggerignore:"true" validate:"required"`
+	Locale string `json:"locale" query:"locale" validate:"omitempty,oneof=vi en" example:"en"`
+}
+
+// LoginMailData is the data of the login alert template.
+type LoginMailData struct {
+	FullName string
+}
+
+// RegisterMailData is the data of the registration template.
+type RegisterMailData struct {
+	FullName string
+}
+
+// ChatDigestMailData is the data of the unread chat digest template.
+type ChatDigestMailData struct {
+	FullName    string
+	UnreadCount int64
+	Digest      *ChatDigest
+}
+
+// MailOutbox records a mail and its delivery. Payload holds the JSON
+// encoded MailPayload the mail is rendered from.
+type MailOutbox struct {
+	ID            int64      `json:"id"`
+	Template      string     `json:"template" example:"login"`
+	Recipient     string     `json:"recipient" example:"abc@host.com"`
+	Locale        string     `json:"locale" example:"vi"`
+	Payload       string     `json:"-"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status" example:"sent"`
+	Attempts      int        `json:"attempts" example:"1"`
+	MessageID     string     `json:"message_id"`
+	Error         string     `json:"error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// MailResult is the outcome of a delivered mail.
+type MailResult struct {
+	Subject   string
+	MessageID string
+}
+
+type ListMailOutboxRequest struct {
+	query.PaginationOptions
+	query.DateRangeOptions
+
+	Status    string `query:"status" validate:"omitempty,oneof=pending sending sent failed"`
+	Template  string `query:"template"`
+	Recipient string `query:"recipient"`
+}
+
+type MailOutboxIDRequest struct {
+	ID int64 `json:"id" param:"id" swaggerignore:"true" validate:"required,gt=0"`
+}
+
+func (MailOutbox) TableName() string {
+	return "mail_outbox"
+}
@@ -1,9 +1,73 @@
 package model
 
-import xqueue "thomas.vn/apartment_service/pkg/queue"
+import (
+	"time"
+)
 
+// MailPayload is everything needed to render a mail. It is stored in the
+// mail outbox so failed mail can be sent again later.
 type MailPayload struct {
-	Type     xqueue.MessageType `json:"type"`
-	Email    string             `json:"email"`
-	FullName string             `json:"full_name,omitempty"`
+	Template string `json:"template"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name,omitempty"`
+	// UserID and Category tie the mail to the recipient's notification
+	// preferences. Mail without a category is always sent and has no
+	// unsubscribe link.
+	UserID   int    `json:"user_id,omitempty"`
+	Category string `json:"category,omitempty"`
+	// Locale picks the template variant; empty means the default locale.
+	Locale string `json:"locale,omitempty"`
+	// ChatDigest is set on chat digest mails.
+	ChatDigest *ChatDigest `json:"chat_digest,omitempty"`
+	// Attachments are attached to the mail as they are.
+	Attachments []MailAttachment `json:"attachments,omitempty"`
+	// Event is attached as an .ics calendar invite.
+	Event *CalendarEvent `json:"event,omitempty"`
+}
+
+// MailAttachment is a file attached to a mail. Content is used when set,
+// otherwise the file is downloaded from URL when the mail is sent, which
+// keeps large files out of the mail outbox.
+type MailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Content     []byte `json:"content,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// CalendarEvent is an event sent as a calendar invite, such as a check-in.
+type CalendarEvent struct {
+	// UID identifies the event; resending an invite with the same UID and
+	// a higher Sequence updates it in the recipient's calendar.
+	UID            string    `json:"uid"`
+	Sequence       int       `json:"sequence,omitempty"`
+	Summary        string    `json:"summary"`
+	Description    string    `json:"description,omitempty"`
+	Location       string    `json:"location,omitempty"`
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	OrganizerName  string    `json:"organizer_name,omitempty"`
+	OrganizerEmail string    `json:"organizer_email,omitempty"`
+	// ReminderMinutes are alarms, in minutes before Start.
+	ReminderMinutes []int `json:"reminder_minutes,omitempty"`
+	Cancelled       bool  `json:"cancelled,omitempty"`
+}
+
+// ChatDigest lists the unread chat messages of a member who was offline.
+type ChatDigest struct {
+	Groups []ChatDigestGroup `json:"groups"`
+}
+
+type ChatDigestGroup struct {
+	ChatGroupID int64  `json:"chat_group_id"`
+	Name        string `json:"name"`
+	UnreadCount int64  `json:"unread_count"`
+	// Messages are the latest unread messages, oldest first.
+	Messages []ChatDigestMessage `json:"messages"`
+}
+
+type ChatDigestMessage struct {
+	Sender string    `json:"sender"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sent_at"`
 }
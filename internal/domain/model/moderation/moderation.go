@@ -0,0 +1,88 @@
+package moderation
+
+import (
+	"time"
+
+	"thomas.vn/apartment_service/pkg/query"
+)
+
+// Block stops two users from starting or continuing a 1-1 chat.
+type Block struct {
+	ID        int64     `json:"id"`
+	BlockerID int       `json:"blocker_id"`
+	BlockedID int       `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BlockRequest struct {
+	UserID int `json:"user_id" validate:"required,gt=0" example:"42"`
+}
+
+type UnblockRequest struct {
+	UserID int `json:"user_id" param:"userId" swaggerignore:"true" validate:"required,gt=0"`
+}
+
+type BlockedUser struct {
+	UserID    int       `json:"user_id"`
+	FullName  string    `json:"full_name"`
+	Avatar    string    `json:"avatar"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// Report flags a chat message for review by a moderator.
+type Report struct {
+	ID            int64      `json:"id"`
+	ChatMessageID int        `json:"chat_message_id"`
+	ChatGroupID   int        `json:"chat_group_id"`
+	ReporterID    int        `json:"reporter_id"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status"`
+	ReviewedBy    *int       `json:"reviewed_by"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type ReportMessageRequest struct {
+	ChatMessageID int    `json:"-" param:"id" swaggerignore:"true" validate:"required,gt=0"`
+	Reason        string `json:"reason" validate:"max=500" example:"Offensive language"`
+}
+
+type ListReportRequest struct {
+	query.PaginationOptions
+
+	Status string `query:"status" validate:"omitempty,oneof=pending removed dismissed"`
+}
+
+// ReportResponse is a report with the reported message, as shown in the
+// moderator queue.
+type ReportResponse struct {
+	ID               int64      `json:"id"`
+	ChatMessageID    int        `json:"chat_message_id"`
+	ChatGroupID      int        `json:"chat_group_id"`
+	ReporterID       int        `json:"reporter_id"`
+	ReporterName     string     `json:"reporter_name"`
+	Reason           string     `json:"reason"`
+	Status           string     `json:"status"`
+	ReviewedBy       *int       `json:"reviewed_by"`
+	ReviewedAt       *time.Time `json:"reviewed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	MessageText      string     `json:"message_text"`
+	MessageSenderID  int        `json:"message_sender_id"`
+	MessageSender    string     `json:"message_sender"`
+	MessageDeleted   bool       `json:"message_deleted"`
+	MessageCreatedAt time.Time  `json:"message_created_at"`
+}
+
+type ReviewReportRequest struct {
+	ID     int64  `json:"-" param:"id" swaggerignore:"true" validate:"required,gt=0"`
+	Action string `json:"action" validate:"required,oneof=remove dismiss" example:"remove"`
+}
+
+func (Block) TableName() string {
+	return "user_blocks"
+}
+
+func (Report) TableName() string {
+	return "chat_message_reports"
+}
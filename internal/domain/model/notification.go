@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+
+	"thomas.vn/apartment_service/pkg/query"
+)
+
+// Notification is an entry of a user's in-app inbox.
+type Notification struct {
+	ID     int64  `json:"id"`
+	UserID int    `json:"user_id"`
+	Type   string `json:"type" example:"login_alert"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	// Link is the app route the notification opens, if any.
+	Link      string     `json:"link" example:"/chat/12"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationEntry describes a notification to send to a user. Category
+// decides which of the user's preferences apply.
+type NotificationEntry struct {
+	UserID   int
+	Category string
+	Type     string
+	Title    string
+	Body     string
+	Link     string
+}
+
+type ListNotificationRequest struct {
+	query.PaginationOptions
+
+	Unread bool `query:"unread"`
+}
+
+type NotificationIDRequest struct {
+	ID int64 `json:"id" param:"id" swaggerignore:"true" validate:"required,gt=0"`
+}
+
+// NotificationBadge is the number of unread notifications of a user.
+type NotificationBadge struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// NotificationEvent is pushed to the user when a notification arrives.
+type NotificationEvent struct {
+	Notification *Notification `json:"notification"`
+	UnreadCount  int64         `json:"unread_count"`
+}
+
+// NotificationAlert is pushed instead of a NotificationEvent when the
+// notification was not stored: it has no ID and leaves the unread count
+// unchanged.
+type NotificationAlert struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Link  string `json:"link"`
+}
+
+// NotificationPreference is whether a user gets a category of
+// notifications on a channel. Without a stored preference it is enabled.
+type NotificationPreference struct {
+	UserID    int       `json:"-"`
+	Category  string    `json:"category" example:"security"`
+	Channel   string    `json:"channel" example:"email"`
+	Enabled   bool      `json:"enabled" example:"true"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceItem `json:"preferences" validate:"required,min=1,dive"`
+}
+
+type NotificationPreferenceItem struct {
+	Category string `json:"category" validate:"required,oneof=security account chat" example:"security"`
+	Channel  string `json:"channel" validate:"required,oneof=email in_app websocket" example:"email"`
+	Enabled  *bool  `json:"enabled" validate:"required" example:"false"`
+}
+
+// UnsubscribeResponse names the mail category of an unsubscribe link and
+// whether the user was unsubscribed from it.
+type UnsubscribeResponse struct {
+	Category     string `json:"category" example:"chat"`
+	Unsubscribed bool   `json:"unsubscribed" example:"true"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
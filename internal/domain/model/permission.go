@@ -35,9 +35,11 @@ type PermissionIDRequest struct {
 	ID uint `json:"id" param:"id" swaggerignore:"true" validate:"required,gt=0"`
 }
 type CheckPermissionRequest struct {
-	RoleID   int    `json:"role_id"`
-	Method   string `json:"method"`
-	Endpoint string `json:"endpoint"`
+	UserID     int    `json:"user_id"`
+	RoleID     int    `json:"role_id"`
+	Method     string `json:"method"`
+	Endpoint   string `json:"endpoint"`
+	BuildingID int64  `json:"building_id"`
 }
 
 func (Permission) TableName() string {
@@ -0,0 +1,8 @@
+package model
+
+type PolicyRequest struct {
+	UserID     int    `json:"user_id"`
+	RoleID     int    `json:"role_id"`
+	Policy     string `json:"policy"`
+	ResourceID int64  `json:"resource_id"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// UserPresence tells whether a user has an open chat connection and when
+// they were last seen connected.
+type UserPresence struct {
+	UserID     int64      `json:"user_id"`
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+}
@@ -45,4 +45,15 @@ type UploadAvatarCloudInput struct {
 
 type DeleteCloudAssetPayload struct {
 	PublicID string
+	// ResourceType and DeliveryType default to an uploaded image.
+	ResourceType string
+	DeliveryType string
+}
+
+// ===== REALTIME EVENT =====
+
+// AvatarUpdatedEvent is pushed to the user once an avatar upload is stored.
+type AvatarUpdatedEvent struct {
+	UserID uint   `json:"user_id"`
+	Avatar string `json:"avatar"`
 }
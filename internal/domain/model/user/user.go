@@ -16,6 +16,7 @@ type User struct {
 	GoogleID   *string    `json:"google_id" gorm:"unique"`
 	TotpSecret *string    `json:"totp_secret" example:"secret"`
 	RoleID     int        `json:"role_id" example:"2"`
+	Locale     string     `json:"locale" gorm:"default:vi" example:"vi"`
 	DeletedBy  int        `json:"deleted_by" example:"1"`
 	IsDeleted  bool       `json:"is_deleted" example:"0"`
 	IsActive   int        `json:"is_active" example:"1"`
@@ -32,28 +33,44 @@ type CreateUserRequest struct {
 	Email    string `json:"email" validate:"required,email" example:"abc@host.com"`
 	Password string `json:"password" validate:"required,min=8" example:"password"`
 	FullName string `json:"full_name" validate:"required" example:"John Doe"`
+	Locale   string `json:"locale" validate:"omitempty,oneof=vi en" example:"vi"`
 }
 
 type UpdateUserRequest struct {
 	UserIDRequest
 	Password string `json:"password" validate:"omitempty,min=8" example:"password"`
 	FullName string `json:"full_name" validate:"omitempty" example:"John Doe"`
-	RoleID   int    `json:"role_id" validate:"omitempty,oneof=1 2" example:"user"`
-	IsActive int    `json:"is_active" validate:"omitempty,oneof=0 1" example:"1"`
+	// RoleID and IsActive are admin only; nil leaves them unchanged.
+	RoleID   *int   `json:"role_id" validate:"omitempty,oneof=1 2" example:"2"`
+	IsActive *int   `json:"is_active" validate:"omitempty,oneof=0 1" example:"1"`
+	Locale   string `json:"locale" validate:"omitempty,oneof=vi en" example:"en"`
+	// CallerRoleID is the role of the authenticated user.
+	CallerRoleID int `json:"-"`
 }
-type UserFilters struct {
-	FullName string `json:"full_name"`
-}
-
 type ListUserRequest struct {
 	query.PaginationOptions
 	query.DateRangeOptions
 	query.SortOptions
 
-	IsDeleted int    `query:"is_deleted"`
+	IsDeleted int    `query:"is_deleted" validate:"omitempty,oneof=0 1"`
 	Filters   string `query:"filters"`
 }
 
+// UserQuerySchema whitelists the filters and sort keys of the user list.
+var UserQuerySchema = query.Schema{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Type: query.TypeInt, Operators: []query.Operator{query.OpEq, query.OpIn}, Sortable: true},
+		"email":      {Column: "email", Operators: []query.Operator{query.OpEq, query.OpLike}, Sortable: true},
+		"full_name":  {Column: "full_name", Operators: []query.Operator{query.OpLike, query.OpEq}, Sortable: true},
+		"role_id":    {Column: "role_id", Type: query.TypeInt, Operators: []query.Operator{query.OpEq, query.OpIn}},
+		"is_active":  {Column: "is_active", Type: query.TypeInt, Operators: []query.Operator{query.OpEq}},
+		"created_at": {Column: "created_at", Type: query.TypeDate, Operators: []query.Operator{query.OpRange}, Sortable: true},
+		"updated_at": {Column: "updated_at", Type: query.TypeDate, Operators: []query.Operator{query.OpRange}, Sortable: true},
+	},
+	Key:          "id",
+	DefaultRange: "created_at",
+}
+
 func (User) TableName() string {
 	return "users"
 }
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// UserRole assigns a role to a user, optionally scoped to a single building.
+// A BuildingID of 0 (consts.GlobalBuildingID) makes the assignment global.
+type UserRole struct {
+	ID         int64      `json:"id"`
+	UserID     int        `json:"user_id"`
+	RoleID     int        `json:"role_id"`
+	BuildingID int64      `json:"building_id"`
+	CreatedBy  int        `json:"created_by"`
+	DeletedBy  int        `json:"deleted_by"`
+	IsDeleted  int        `json:"is_deleted"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at"`
+}
+
+type AssignRoleRequest struct {
+	UserID     int   `json:"user_id" validate:"required,gt=0"`
+	RoleID     int   `json:"role_id" validate:"required,gt=0"`
+	BuildingID int64 `json:"building_id" validate:"omitempty,gte=0"`
+}
+
+// RoleGrantRequest describes a caller granting TargetRoleID in BuildingID.
+type RoleGrantRequest struct {
+	UserID       int
+	RoleID       int
+	TargetRoleID int
+	BuildingID   int64
+}
+
+type UserRoleIDRequest struct {
+	ID uint `json:"id" param:"id" swaggerignore:"true" validate:"required,gt=0"`
+}
+
+func (UserRole) TableName() string {
+	return "user_roles"
+}
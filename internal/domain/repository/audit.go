@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+// AuditRepository is append-only: audit records are never updated or deleted.
+type AuditRepository interface {
+	CreateAuditLog(ctx context.Context, log *model.AuditLog) error
+	ListAuditLogs(ctx context.Context, req *model.ListAuditLogRequest) ([]*model.AuditLog, int64, error)
+}
@@ -2,13 +2,31 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"thomas.vn/apartment_service/internal/domain/model"
 	"thomas.vn/apartment_service/internal/domain/model/chatgroup"
 )
 
 type ChatGroupRepository interface {
-	ListChatGroupsWithMembers(ctx context.Context, req *chatgroup.ListChatGroupRequest) ([]*chatgroup.ListResponse, int64, error)
+	ListChatGroupsWithMembers(ctx context.Context, userID int64, req *chatgroup.ListChatGroupRequest) ([]*chatgroup.ListResponse, int64, error)
 	CreateChatGroup(ctx context.Context, chatGroup *chatgroup.ChatGroup) (*chatgroup.ChatGroup, error)
 	AddMembers(ctx context.Context, req *chatgroup.CreateMemberRequest) error
 	FindChatOneByUserIDs(ctx context.Context, userIDs []int64) (*chatgroup.ChatGroup, error)
+	IsMember(ctx context.Context, chatGroupID int64, userID int64) (bool, error)
+	ListMemberGroupIDs(ctx context.Context, userID int64) ([]int64, error)
+	GetChatGroupByID(ctx context.Context, id int64) (*chatgroup.ChatGroup, error)
+	// GetChatGroupByIDWithDeleted is GetChatGroupByID including soft deleted groups.
+	GetChatGroupByIDWithDeleted(ctx context.Context, id int64) (*chatgroup.ChatGroup, error)
+	GetMember(ctx context.Context, chatGroupID int64, userID int64) (*model.ChatGroupMembers, error)
+	ListMembers(ctx context.Context, chatGroupID int64) ([]*model.ChatGroupMembers, error)
+	RemoveMember(ctx context.Context, memberID int) (bool, error)
+	UpdateMemberRole(ctx context.Context, memberID int, role string) error
+	RenameChatGroup(ctx context.Context, id int64, name string) error
+	DeleteChatGroup(ctx context.Context, id int64) (bool, error)
+	MarkRead(ctx context.Context, chatGroupID int64, userID int64, messageID int64) (int64, error)
+	AckDelivery(ctx context.Context, chatGroupID int64, userID int64, seq int64) (int64, error)
+	SetMute(ctx context.Context, chatGroupID int64, userID int64, until *time.Time) error
+	ListDigestCandidates(ctx context.Context, now time.Time, quietBefore time.Time, after chatgroup.DigestCursor, limit int) ([]*chatgroup.DigestRow, error)
+	MarkNotified(ctx context.Context, chatGroupID int64, userID int64, messageID int64) error
 }
@@ -4,9 +4,27 @@ import (
 	"context"
 
 	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/pkg/query"
 )
 
 type ChatMessageRepository interface {
-	ListChatMessages(ctx context.Context, req *chatmessage.ListChatMessageRequest) ([]*chatmessage.Response, int64, error)
+	ListChatMessages(ctx context.Context, req *chatmessage.ListChatMessageRequest, spec *query.Spec) ([]*chatmessage.Response, int64, error)
+	ListChatMessagesAfterSeq(ctx context.Context, chatGroupID int, afterSeq int64, limit int) ([]*chatmessage.Response, error)
+	ListChatMessagesByIDs(ctx context.Context, ids []int) ([]*chatmessage.Response, error)
+	ListTextMessagesAfterID(ctx context.Context, afterID int, limit int) ([]*chatmessage.ChatMessage, error)
+	ListUnreadPreview(ctx context.Context, chatGroupID int, userID int, afterID int64, limit int) ([]*chatmessage.Row, error)
 	CreateChatMessage(ctx context.Context, chatMessage *chatmessage.ChatMessage) (*chatmessage.Row, error)
+	GetChatMessageRow(ctx context.Context, id int) (*chatmessage.Row, error)
+	GetChatMessageByID(ctx context.Context, id int) (*chatmessage.ChatMessage, error)
+	EditChatMessage(ctx context.Context, chatMessage *chatmessage.ChatMessage, text string, editorID int) error
+	ListChatMessageEdits(ctx context.Context, id int) ([]*chatmessage.Edit, error)
+	DeleteChatMessage(ctx context.Context, id int) (bool, error)
+	ToggleReaction(ctx context.Context, messageID int, userID int, emoji string) (bool, error)
+	ListReactionCounts(ctx context.Context, messageIDs []int) (map[int][]chatmessage.ReactionCount, error)
+	CreateAttachment(ctx context.Context, attachment *chatmessage.Attachment) error
+	GetAttachment(ctx context.Context, id int) (*chatmessage.Attachment, error)
+	UpdateAttachmentStored(ctx context.Context, id int, file *service.StoredFile) error
+	LinkAttachments(ctx context.Context, messageID int, groupID int, uploaderID int, ids []int) (bool, error)
+	ListAttachments(ctx context.Context, messageIDs []int) (map[int][]*chatmessage.Attachment, error)
 }
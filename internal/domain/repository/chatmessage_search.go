@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+)
+
+type ChatMessageSearchRepository interface {
+	IndexMessages(ctx context.Context, docs []*chatmessage.SearchDocument) error
+	DeleteMessages(ctx context.Context, ids []int) error
+	// Search matches text in the messages of the given chat groups only.
+	Search(ctx context.Context, text string, chatGroupIDs []int64, from int, size int) ([]*chatmessage.SearchHit, int64, error)
+}
@@ -3,14 +3,26 @@ package repository
 import "context"
 
 type MailData struct {
-	Email    string
-	Subject  string
-	Title    string
-	Color    string
-	Action   string
-	FullName string
+	Email   string
+	Subject string
+	// HTML and Text are the alternatives of the body. Clients that cannot
+	// show HTML fall back to Text.
+	HTML string
+	Text string
+	// Attachments turn the mail into multipart/mixed.
+	Attachments []MailAttachment
+	// UnsubscribeURL is sent as a one-click List-Unsubscribe header.
+	UnsubscribeURL string
+}
+
+type MailAttachment struct {
+	Filename string
+	// ContentType may carry parameters, e.g. the method of a calendar invite.
+	ContentType string
+	Content     []byte
 }
 
 type MailRepository interface {
-	Send(ctx context.Context, data MailData) error
+	// Send delivers the mail and returns its Message-ID.
+	Send(ctx context.Context, data MailData) (string, error)
 }
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+type MailOutboxRepository interface {
+	// CreateMailOutbox inserts the mail within tx, or on its own when tx is nil.
+	CreateMailOutbox(ctx context.Context, tx *gorm.DB, mail *model.MailOutbox) error
+	GetMailOutbox(ctx context.Context, id int64) (*model.MailOutbox, error)
+	ListMailOutbox(ctx context.Context, req *model.ListMailOutboxRequest) ([]*model.MailOutbox, int64, error)
+	// ListDueMailOutbox returns pending mail due at now and mail stuck in
+	// sending since before staleBefore.
+	ListDueMailOutbox(ctx context.Context, now time.Time, staleBefore time.Time, limit int) ([]*model.MailOutbox, error)
+	// ClaimMailOutbox moves the mail to sending and counts the attempt. It
+	// reports false when another worker changed the mail first.
+	ClaimMailOutbox(ctx context.Context, mail *model.MailOutbox) (bool, error)
+	MarkMailSent(ctx context.Context, id int64, result *model.MailResult) error
+	MarkMailFailed(ctx context.Context, id int64, status string, reason string, nextAttemptAt time.Time) error
+	// ResetMailOutbox queues a failed mail again with fresh attempts.
+	ResetMailOutbox(ctx context.Context, id int64) (bool, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model/moderation"
+)
+
+type ModerationRepository interface {
+	BlockUser(ctx context.Context, blockerID int, blockedID int) error
+	UnblockUser(ctx context.Context, blockerID int, blockedID int) (bool, error)
+	ListBlockedUsers(ctx context.Context, blockerID int) ([]*moderation.BlockedUser, error)
+	IsBlocked(ctx context.Context, userID int, otherID int) (bool, error)
+	CreateReport(ctx context.Context, report *moderation.Report) (bool, error)
+	GetReport(ctx context.Context, id int64) (*moderation.Report, error)
+	ListReports(ctx context.Context, req *moderation.ListReportRequest) ([]*moderation.ReportResponse, int64, error)
+	ResolveReports(ctx context.Context, chatMessageID int, status string, reviewerID int) (int64, error)
+}
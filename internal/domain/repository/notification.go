@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, notification *model.Notification) error
+	ListNotifications(ctx context.Context, userID int, req *model.ListNotificationRequest) ([]*model.Notification, int64, error)
+	CountUnread(ctx context.Context, userID int) (int64, error)
+	MarkRead(ctx context.Context, userID int, id int64) (bool, error)
+	MarkAllRead(ctx context.Context, userID int) (int64, error)
+}
+
+type NotificationPreferenceRepository interface {
+	ListPreferences(ctx context.Context, userID int) ([]*model.NotificationPreference, error)
+	SavePreferences(ctx context.Context, prefs []*model.NotificationPreference) error
+	// IsEnabled reports whether the user gets the category on the channel.
+	IsEnabled(ctx context.Context, userID int, category string, channel string) (bool, error)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"thomas.vn/apartment_service/internal/domain/model"
+	xtenant "thomas.vn/apartment_service/pkg/tenant"
 )
 
 type PermissionRepository interface {
@@ -11,4 +12,9 @@ type PermissionRepository interface {
 	CreatePermission(ctx context.Context, permission *model.Permission) (*model.Permission, error)
 	UpdatePermission(ctx context.Context, permission *model.Permission) (*model.Permission, error)
 	GetPermissionByID(ctx context.Context, permissionID uint) (*model.Permission, error)
+	ResolvePermissionScope(ctx context.Context, request model.CheckPermissionRequest) (*xtenant.Scope, error)
+	CountMissingPermissions(ctx context.Context, request model.RoleGrantRequest) (int64, error)
+	AssignRole(ctx context.Context, userRole *model.UserRole) (*model.UserRole, error)
+	GetUserRoleByID(ctx context.Context, id uint) (*model.UserRole, error)
+	RevokeRole(ctx context.Context, id uint, deletedBy int) error
 }
@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+// PolicyRepository answers the resource attribute lookups needed to
+// evaluate access policies (ownership, membership).
+type PolicyRepository interface {
+	IsArticleOwner(ctx context.Context, userID int, articleID int64) (bool, error)
+	IsChatGroupMember(ctx context.Context, userID int, chatGroupID int64) (bool, error)
+}
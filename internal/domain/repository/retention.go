@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+)
+
+type RetentionRepository interface {
+	// PurgeDeleted permanently removes rows of table soft deleted before the given time.
+	PurgeDeleted(ctx context.Context, table string, before time.Time) (int64, error)
+	// PurgeDeletedMessages permanently removes chat messages soft deleted
+	// before the given time with their edits, reactions, reports and
+	// attachments, and returns the removed attachments.
+	PurgeDeletedMessages(ctx context.Context, before time.Time) (int64, []*chatmessage.Attachment, error)
+	// AnonymizeDeletedUsers strips the personal data of users soft deleted
+	// before the given time, keeping the rows other tables point at.
+	AnonymizeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
+}
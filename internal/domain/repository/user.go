@@ -3,15 +3,25 @@ package repository
 import (
 	"context"
 
+	"gorm.io/gorm"
 	xuser "thomas.vn/apartment_service/internal/domain/model/user"
+	"thomas.vn/apartment_service/pkg/query"
 )
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *xuser.User) (*xuser.User, error)
+	CreateUserTx(ctx context.Context, tx *gorm.DB, user *xuser.User) (*xuser.User, error)
 	GetUserByID(ctx context.Context, id uint) (*xuser.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*xuser.User, error)
+	// EmailExists also counts soft-deleted users, which keep their email
+	// under the unique index.
+	EmailExists(ctx context.Context, email string) (bool, error)
 	UpdateUser(ctx context.Context, user *xuser.User) (*xuser.User, error)
 	DeleteUser(ctx context.Context, id uint) error
-	ListUsers(ctx context.Context, req *xuser.ListUserRequest, filters *xuser.UserFilters) ([]*xuser.User, int64, error)
+	RestoreUser(ctx context.Context, id uint) (bool, error)
+	ListUsers(ctx context.Context, req *xuser.ListUserRequest, spec *query.Spec) ([]*xuser.User, int64, error)
 	UpdateTotpSecret(ctx context.Context, userID int64, secret *string) error
+	// ListBuildingIDs returns the buildings the user holds a building-scoped
+	// role in.
+	ListBuildingIDs(ctx context.Context, userID int64) ([]int64, error)
 }
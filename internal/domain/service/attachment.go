@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"mime/multipart"
+	"time"
+)
+
+// StoredFile locates an uploaded chat attachment in the storage.
+type StoredFile struct {
+	Key          string
+	ResourceType string
+	Format       string
+	Width        int
+	Height       int
+}
+
+// AttachmentStorage keeps chat attachments private: files are only reached
+// through short-lived or signed URLs.
+type AttachmentStorage interface {
+	Upload(ctx context.Context, file *multipart.FileHeader, folder string, image bool) (*StoredFile, error)
+	// DownloadURL returns a URL valid for ttl.
+	DownloadURL(file StoredFile, fileName string, ttl time.Duration) (string, error)
+	// ThumbnailURL returns a signed URL of a small rendition of an image.
+	ThumbnailURL(file StoredFile) (string, error)
+}
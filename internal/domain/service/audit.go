@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+// AuditService records security-relevant and admin actions. The actor, IP,
+// user agent and request ID are taken from ctx, so usecases only describe
+// what happened.
+type AuditService interface {
+	Record(ctx context.Context, entry model.AuditEntry) error
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+// MailRenderer renders mail templates. Unknown locales fall back to the
+// default locale. A non-empty unsubscribeURL is linked in the footer.
+type MailRenderer interface {
+	Render(name, locale string, data any, unsubscribeURL string) (*model.RenderedMail, error)
+	Templates() []model.MailTemplate
+}
+
+// UnsubscribeLinks builds and checks the signed one-click unsubscribe links
+// embedded in mail.
+type UnsubscribeLinks interface {
+	URL(userID int, category string) string
+	Parse(token string) (userID int, category string, err error)
+}
+
+// MailOutbox records mail for the outbox worker to deliver.
+type MailOutbox interface {
+	// Enqueue records the mail within tx, so it is only sent once the
+	// change that triggered it commits. A nil tx records it on its own.
+	Enqueue(ctx context.Context, tx *gorm.DB, payload *model.MailPayload) error
+}
@@ -0,0 +1,10 @@
+package service
+
+import "context"
+
+// MessageFilter inspects the text of a chat message before it is stored.
+// It returns the text to store, which may be rewritten, or an error to
+// refuse the message.
+type MessageFilter interface {
+	FilterMessage(ctx context.Context, text string) (string, error)
+}
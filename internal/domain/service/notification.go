@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+// NotificationService stores a notification in the user's inbox and pushes
+// it to their open sessions.
+type NotificationService interface {
+	Notify(ctx context.Context, entry model.NotificationEntry) error
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+// RealtimeService pushes events to clients connected to a chat room.
+type RealtimeService interface {
+	BroadcastToRoom(ctx context.Context, room int, eventType string, data interface{}) error
+	// EvictFromRoom stops delivering room events to the user's connections.
+	EvictFromRoom(ctx context.Context, room int, userID int) error
+}
+
+// RealtimeNotifier pushes events to every connection of a user, whatever
+// rooms they joined, e.g. to tell them a background job finished.
+type RealtimeNotifier interface {
+	NotifyUser(ctx context.Context, userID int, eventType string, data interface{}) error
+}
+
+// PresenceService reports which users are connected to the chat.
+type PresenceService interface {
+	UserPresence(ctx context.Context, userIDs []int64) ([]*model.UserPresence, error)
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 
 	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/pkg/query"
 )
 
 type ArticlesUsecase interface {
-	ListArticles(ctx context.Context, req *model.ListArticleRequest, filter *model.ArticlesFilters) ([]*model.Articles, int64, error)
+	ListArticles(ctx context.Context, req *model.ListArticleRequest, spec *query.Spec) ([]*model.Articles, int64, error)
+	DeleteArticle(ctx context.Context, id int64) error
+	RestoreArticle(ctx context.Context, id int64) error
 }
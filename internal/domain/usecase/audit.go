@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+type AuditUsecase interface {
+	ListAuditLogs(ctx context.Context, req *model.ListAuditLogRequest) ([]*model.AuditLog, int64, error)
+	ExportAuditLogs(ctx context.Context, req *model.ListAuditLogRequest) ([]*model.AuditLog, error)
+}
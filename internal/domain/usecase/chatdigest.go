@@ -0,0 +1,7 @@
+package usecase
+
+import "context"
+
+type ChatDigestUsecase interface {
+	SendDigests(ctx context.Context) error
+}
@@ -3,10 +3,23 @@ package usecase
 import (
 	"context"
 
+	"thomas.vn/apartment_service/internal/domain/model"
 	"thomas.vn/apartment_service/internal/domain/model/chatgroup"
 )
 
 type ChatGroupUsecase interface {
-	ListChatGroups(ctx context.Context, req *chatgroup.ListChatGroupRequest) ([]*chatgroup.ListResponse, int64, error)
+	ListChatGroups(ctx context.Context, userID int64, req *chatgroup.ListChatGroupRequest) ([]*chatgroup.ListResponse, int64, error)
 	CreateChatGroup(ctx context.Context, req *chatgroup.CreateChatGroupRequest) (*chatgroup.ChatGroup, error)
+	EnsureMember(ctx context.Context, chatGroupID int64, userID int64) error
+	EnsureNotBlocked(ctx context.Context, chatGroupID int64, userID int64) error
+	AddMembers(ctx context.Context, actorID int64, req *chatgroup.AddMembersRequest) error
+	RemoveMember(ctx context.Context, actorID int64, req *chatgroup.MemberRequest) error
+	UpdateMemberRole(ctx context.Context, actorID int64, req *chatgroup.UpdateMemberRoleRequest) error
+	RenameChatGroup(ctx context.Context, actorID int64, req *chatgroup.RenameChatGroupRequest) error
+	LeaveChatGroup(ctx context.Context, actorID int64, chatGroupID int64) error
+	DeleteChatGroup(ctx context.Context, actorID int64, chatGroupID int64) error
+	MarkRead(ctx context.Context, userID int64, req *chatgroup.MarkReadRequest) (*chatgroup.ReadReceipt, error)
+	AckDelivery(ctx context.Context, userID int64, req *chatgroup.AckRequest) (int64, error)
+	SetMute(ctx context.Context, userID int64, req *chatgroup.MuteRequest) (*chatgroup.MuteSetting, error)
+	ListMemberPresence(ctx context.Context, userID int64, chatGroupID int64) ([]*model.UserPresence, error)
 }
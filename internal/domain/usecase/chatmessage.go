@@ -4,9 +4,24 @@ import (
 	"context"
 
 	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/pkg/query"
 )
 
 type ChatMessageUsecase interface {
-	ListChatMessages(ctx context.Context, req *chatmessage.ListChatMessageRequest) ([]*chatmessage.Response, int64, error)
+	ListChatMessages(ctx context.Context, req *chatmessage.ListChatMessageRequest, spec *query.Spec) ([]*chatmessage.Response, int64, error)
+	Replay(ctx context.Context, userID int, chatGroupID int, afterSeq *int64) (*chatmessage.Replay, error)
 	SendMessage(ctx context.Context, req *chatmessage.CreateChatMessageRequest) (*chatmessage.Response, error)
+	SendSystemMessage(ctx context.Context, chatGroupID int, event *chatmessage.SystemEvent) (*chatmessage.Response, error)
+	EditMessage(ctx context.Context, userID int, req *chatmessage.EditChatMessageRequest) (*chatmessage.Response, error)
+	DeleteMessage(ctx context.Context, userID int, id int) (*chatmessage.DeletedEvent, error)
+	RemoveMessage(ctx context.Context, moderatorID int, id int) (*chatmessage.DeletedEvent, error)
+	React(ctx context.Context, userID int, req *chatmessage.ReactRequest) (*chatmessage.ReactionEvent, error)
+	ListEdits(ctx context.Context, userID int, id int) ([]*chatmessage.Edit, error)
+	SearchMessages(ctx context.Context, userID int, req *chatmessage.SearchRequest) ([]*chatmessage.SearchResult, int64, error)
+	SyncSearchIndex(ctx context.Context, id int) error
+	ReindexSearch(ctx context.Context) (int, error)
+	UploadAttachment(ctx context.Context, userID int, req *chatmessage.UploadAttachmentRequest) (*chatmessage.AttachmentResponse, error)
+	ProcessAttachmentUpload(ctx context.Context, attachmentID int, file *service.StoredFile) error
+	AttachmentDownloadURL(ctx context.Context, userID int, id int) (string, error)
 }
@@ -1,8 +1,14 @@
 package usecase
 
-import "context"
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
 
 type MailUsecase interface {
-	SendLoginMail(ctx context.Context, email, fullName string) error
-	SendRegisterMail(ctx context.Context, email, fullName string) error
+	// Send renders the payload's template and delivers it.
+	Send(ctx context.Context, payload *model.MailPayload) (*model.MailResult, error)
+	ListTemplates(ctx context.Context) []model.MailTemplate
+	PreviewTemplate(ctx context.Context, req *model.PreviewMailRequest) (*model.RenderedMail, error)
 }
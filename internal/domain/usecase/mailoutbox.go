@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+type MailOutboxUsecase interface {
+	// DeliverDue sends the mail that is due and records the outcome.
+	DeliverDue(ctx context.Context) error
+	ListMailOutbox(ctx context.Context, req *model.ListMailOutboxRequest) ([]*model.MailOutbox, int64, error)
+	GetMailOutbox(ctx context.Context, id int64) (*model.MailOutbox, error)
+	Resend(ctx context.Context, id int64) (*model.MailOutbox, error)
+}
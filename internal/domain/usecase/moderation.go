@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model/moderation"
+)
+
+type ModerationUsecase interface {
+	BlockUser(ctx context.Context, userID int, req *moderation.BlockRequest) error
+	UnblockUser(ctx context.Context, userID int, req *moderation.UnblockRequest) error
+	ListBlockedUsers(ctx context.Context, userID int) ([]*moderation.BlockedUser, error)
+	ReportMessage(ctx context.Context, userID int, req *moderation.ReportMessageRequest) (*moderation.Report, error)
+	ListReports(ctx context.Context, req *moderation.ListReportRequest) ([]*moderation.ReportResponse, int64, error)
+	ReviewReport(ctx context.Context, moderatorID int, req *moderation.ReviewReportRequest) error
+}
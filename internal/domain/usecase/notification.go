@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+type NotificationUsecase interface {
+	ListNotifications(ctx context.Context, userID int, req *model.ListNotificationRequest) ([]*model.Notification, int64, error)
+	Badge(ctx context.Context, userID int) (*model.NotificationBadge, error)
+	MarkRead(ctx context.Context, userID int, id int64) (*model.NotificationBadge, error)
+	MarkAllRead(ctx context.Context, userID int) (*model.NotificationBadge, error)
+}
+
+type NotificationPreferenceUsecase interface {
+	// ListPreferences returns every category and channel, with defaults
+	// filled in.
+	ListPreferences(ctx context.Context, userID int) ([]*model.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, userID int, req *model.UpdateNotificationPreferencesRequest) ([]*model.NotificationPreference, error)
+	// CheckUnsubscribe validates a signed unsubscribe link without changing
+	// anything, so the user can confirm it.
+	CheckUnsubscribe(ctx context.Context, token string) (*model.UnsubscribeResponse, error)
+	// Unsubscribe turns off mail of the category in a signed unsubscribe link.
+	Unsubscribe(ctx context.Context, token string) (*model.UnsubscribeResponse, error)
+}
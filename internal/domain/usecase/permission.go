@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"thomas.vn/apartment_service/internal/domain/model"
+	xtenant "thomas.vn/apartment_service/pkg/tenant"
 )
 
 type PermissionUsecase interface {
@@ -11,4 +12,9 @@ type PermissionUsecase interface {
 	CreatePermission(ctx context.Context, req *model.CreatePermissionRequest, userID int) (*model.Permission, error)
 	GetPermissionByID(ctx context.Context, permissionID uint) (*model.Permission, error)
 	UpdatePermission(ctx context.Context, req *model.UpdatePermissionRequest) (*model.Permission, error)
+	ResolveScope(ctx context.Context, request model.CheckPermissionRequest) (*xtenant.Scope, error)
+	// AssignRole grants a role within the caller's building scope. Callers
+	// cannot grant a role above their own base role.
+	AssignRole(ctx context.Context, req *model.AssignRoleRequest, userID int, roleID int) (*model.UserRole, error)
+	RevokeRole(ctx context.Context, id uint, userID int) error
 }
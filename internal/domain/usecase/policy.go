@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+type PolicyUsecase interface {
+	Evaluate(ctx context.Context, request model.PolicyRequest) (bool, error)
+}
@@ -0,0 +1,7 @@
+package usecase
+
+import "context"
+
+type RetentionUsecase interface {
+	PurgeDeleted(ctx context.Context) error
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	xuser "thomas.vn/apartment_service/internal/domain/model/user"
+	"thomas.vn/apartment_service/pkg/query"
 )
 
 type UserUsecase interface {
@@ -12,7 +13,8 @@ type UserUsecase interface {
 	GetUser(ctx context.Context, id uint) (*xuser.User, error)
 	UpdateUser(ctx context.Context, req *xuser.UpdateUserRequest) (*xuser.User, error)
 	DeleteUser(ctx context.Context, id uint) error
-	ListUsers(ctx context.Context, req *xuser.ListUserRequest, filters *xuser.UserFilters) ([]*xuser.User, int64, error)
+	RestoreUser(ctx context.Context, id uint) error
+	ListUsers(ctx context.Context, req *xuser.ListUserRequest, spec *query.Spec) ([]*xuser.User, int64, error)
 	DeleteUsersCreatedBefore(ctx context.Context, days time.Time) error
 	UploadLocal(ctx context.Context, req *xuser.UploadAvatarLocalRequest) error
 	ProcessUploadLocal(ctx context.Context, req *xuser.UploadAvatarLocalInput) error
@@ -8,6 +8,13 @@ import (
 )
 
 type ChatUsecase interface {
-	CreateRoom(ctx context.Context, request *chatgroup.CreateChatGroupRequest) (int, error)
+	CreateRoom(ctx context.Context, request *chatgroup.CreateChatGroupRequest) (*chatgroup.ChatGroup, error)
+	JoinRoom(ctx context.Context, chatGroupID int, userID int) error
 	SendMessage(ctx context.Context, req *chatmessage.CreateChatMessageRequest) (*chatmessage.Response, error)
+	MarkRead(ctx context.Context, userID int, req *chatgroup.MarkReadRequest) (*chatgroup.ReadReceipt, error)
+	EditMessage(ctx context.Context, userID int, req *chatmessage.EditChatMessageRequest) (*chatmessage.Response, error)
+	DeleteMessage(ctx context.Context, userID int, id int) (*chatmessage.DeletedEvent, error)
+	React(ctx context.Context, userID int, req *chatmessage.ReactRequest) (*chatmessage.ReactionEvent, error)
+	Resume(ctx context.Context, userID int, chatGroupID int, afterSeq *int64) (*chatmessage.Replay, error)
+	Ack(ctx context.Context, userID int, req *chatgroup.AckRequest) (int64, error)
 }
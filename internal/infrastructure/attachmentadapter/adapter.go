@@ -0,0 +1,101 @@
+// Package attachmentadapter implements service.AttachmentStorage on top of
+// Cloudinary. Attachments are uploaded with the "authenticated" delivery
+// type, so they can only be fetched through signed URLs.
+package attachmentadapter
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+
+	"thomas.vn/apartment_service/internal/domain/service"
+)
+
+// thumbnailTransformation renders image previews shown in the chat.
+const thumbnailTransformation = "c_fill,w_320,h_320,q_auto"
+
+var errNotConfigured = errors.New("attachment storage is not configured")
+
+type adapter struct {
+	cld *cloudinary.Cloudinary
+}
+
+// New returns a service.AttachmentStorage backed by cld. cld may be nil when
+// Cloudinary is not configured; every call then fails.
+func New(cld *cloudinary.Cloudinary) service.AttachmentStorage {
+	return &adapter{cld: cld}
+}
+
+func (a *adapter) Upload(ctx context.Context, fileHeader *multipart.FileHeader, folder string, image bool) (*service.StoredFile, error) {
+	if a.cld == nil {
+		return nil, errNotConfigured
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	resourceType := string(api.File)
+	if image {
+		resourceType = string(api.Image)
+	}
+
+	res, err := a.cld.Upload.Upload(ctx, file, uploader.UploadParams{
+		Folder:       folder,
+		ResourceType: resourceType,
+		Type:         api.Authenticated,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Error.Message != "" {
+		return nil, errors.New(res.Error.Message)
+	}
+
+	return &service.StoredFile{
+		Key:          res.PublicID,
+		ResourceType: res.ResourceType,
+		Format:       res.Format,
+		Width:        res.Width,
+		Height:       res.Height,
+	}, nil
+}
+
+func (a *adapter) DownloadURL(file service.StoredFile, _ string, ttl time.Duration) (string, error) {
+	if a.cld == nil {
+		return "", errNotConfigured
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	return a.cld.Upload.PrivateDownloadURL(uploader.PrivateDownloadURLParams{
+		PublicID:     file.Key,
+		Format:       file.Format,
+		DeliveryType: api.Authenticated,
+		Attachment:   "true",
+		ExpiresAt:    &expiresAt,
+		ResourceType: api.AssetType(file.ResourceType),
+	})
+}
+
+func (a *adapter) ThumbnailURL(file service.StoredFile) (string, error) {
+	if a.cld == nil {
+		return "", errNotConfigured
+	}
+
+	img, err := a.cld.Image(file.Key)
+	if err != nil {
+		return "", err
+	}
+	img.DeliveryType = api.Authenticated
+	img.Transformation = thumbnailTransformation
+	img.Config.URL.SignURL = true
+
+	return img.String()
+}
@@ -0,0 +1,98 @@
+// Package filteradapter implements service.MessageFilter with a list of
+// banned words. Matching ignores case and Vietnamese diacritics, so "đồ ngu"
+// also catches "do ngu" and "ĐỒ NGU", and only whole words match.
+package filteradapter
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/service"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+type wordList struct {
+	words  [][]rune
+	reject bool
+}
+
+// NewWordList returns a filter for the banned words. With the reject action
+// messages containing a banned word are refused, otherwise the words are
+// masked with asterisks.
+func NewWordList(words []string, action string) service.MessageFilter {
+	f := &wordList{reject: action == consts.ChatFilterActionReject}
+	for _, w := range words {
+		folded := fold(strings.TrimSpace(w))
+		if len(folded) > 0 {
+			f.words = append(f.words, folded)
+		}
+	}
+	return f
+}
+
+func (f *wordList) FilterMessage(_ context.Context, text string) (string, error) {
+	if len(f.words) == 0 || text == "" {
+		return text, nil
+	}
+
+	runes := []rune(text)
+	folded := fold(text)
+	matched := false
+
+	for _, w := range f.words {
+		for i := 0; i+len(w) <= len(folded); i++ {
+			if !isWordAt(folded, w, i) {
+				continue
+			}
+			matched = true
+			if f.reject {
+				return "", apperror.BadRequest("Message contains inappropriate language")
+			}
+			for j := i; j < i+len(w); j++ {
+				if !unicode.IsSpace(runes[j]) {
+					runes[j] = '*'
+				}
+			}
+		}
+	}
+
+	if !matched {
+		return text, nil
+	}
+	return string(runes), nil
+}
+
+// isWordAt reports whether w occurs in s at i as a whole word.
+func isWordAt(s []rune, w []rune, i int) bool {
+	for j, r := range w {
+		if s[i+j] != r {
+			return false
+		}
+	}
+	if i > 0 && isWordRune(s[i-1]) {
+		return false
+	}
+	end := i + len(w)
+	return end == len(s) || !isWordRune(s[end])
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// fold lowercases s and strips diacritics rune by rune, so that positions in
+// the result match positions in s.
+func fold(s string) []rune {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		r = unicode.ToLower(r)
+		if base := []rune(xutils.RemoveDiacritics(string(r))); len(base) == 1 {
+			r = unicode.ToLower(base[0])
+		}
+		out = append(out, r)
+	}
+	return out
+}
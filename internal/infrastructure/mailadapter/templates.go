@@ -0,0 +1,148 @@
+// Package mailadapter renders the mail templates embedded under templates/.
+//
+// Every locale has a directory holding, per template, an .html body and a
+// .txt file with the subject and plain-text body. Bodies define a "content"
+// block that the shared layouts wrap; common.txt defines the blocks shared
+// by all templates of the locale, such as the footer and the unsubscribe
+// label.
+package mailadapter
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/service"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+const commonFile = "common.txt"
+
+type mailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+type renderer struct {
+	// templates maps locale, then template name, to the parsed template.
+	templates map[string]map[string]*mailTemplate
+}
+
+// view is what the templates execute against.
+type view struct {
+	Locale         string
+	Subject        string
+	UnsubscribeURL string
+	Data           any
+}
+
+// NewRenderer parses the embedded templates.
+func NewRenderer() (service.MailRenderer, error) {
+	r := &renderer{templates: make(map[string]map[string]*mailTemplate)}
+
+	locales, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range locales {
+		if !dir.IsDir() {
+			continue
+		}
+		locale := dir.Name()
+		files, err := fs.Glob(templateFS, path.Join("templates", locale, "*.html"))
+		if err != nil {
+			return nil, err
+		}
+		r.templates[locale] = make(map[string]*mailTemplate, len(files))
+		for _, file := range files {
+			name := strings.TrimSuffix(path.Base(file), ".html")
+			t, err := parse(locale, name)
+			if err != nil {
+				return nil, fmt.Errorf("parse mail template %s/%s: %w", locale, name, err)
+			}
+			r.templates[locale][name] = t
+		}
+	}
+
+	if _, ok := r.templates[consts.LocaleDefault]; !ok {
+		return nil, fmt.Errorf("no mail templates for default locale %q", consts.LocaleDefault)
+	}
+	return r, nil
+}
+
+func parse(locale, name string) (*mailTemplate, error) {
+	common := path.Join("templates", locale, commonFile)
+
+	html, err := htmltemplate.ParseFS(templateFS, "templates/layout.html", common, path.Join("templates", locale, name+".html"))
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.ParseFS(templateFS, "templates/layout.txt", common, path.Join("templates", locale, name+".txt"))
+	if err != nil {
+		return nil, err
+	}
+	if text.Lookup("subject") == nil {
+		return nil, fmt.Errorf("missing subject block")
+	}
+	return &mailTemplate{html: html, text: text}, nil
+}
+
+func (r *renderer) Render(name, locale string, data any, unsubscribeURL string) (*model.RenderedMail, error) {
+	if _, ok := r.templates[locale]; !ok {
+		locale = consts.LocaleDefault
+	}
+	t, ok := r.templates[locale][name]
+	if !ok {
+		t, ok = r.templates[consts.LocaleDefault][name]
+		locale = consts.LocaleDefault
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown mail template %q", name)
+	}
+
+	v := view{Locale: locale, UnsubscribeURL: unsubscribeURL, Data: data}
+	var subject, html, text bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", v); err != nil {
+		return nil, err
+	}
+	v.Subject = strings.TrimSpace(subject.String())
+	if err := t.html.ExecuteTemplate(&html, "layout", v); err != nil {
+		return nil, err
+	}
+	if err := t.text.ExecuteTemplate(&text, "layout", v); err != nil {
+		return nil, err
+	}
+
+	return &model.RenderedMail{
+		Subject: v.Subject,
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
+
+func (r *renderer) Templates() []model.MailTemplate {
+	locales := make(map[string][]string)
+	for locale, templates := range r.templates {
+		for name := range templates {
+			locales[name] = append(locales[name], locale)
+		}
+	}
+
+	res := make([]model.MailTemplate, 0, len(locales))
+	for name, l := range locales {
+		sort.Strings(l)
+		res = append(res, model.MailTemplate{Name: name, Locales: l})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
@@ -0,0 +1,73 @@
+package mailadapter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"thomas.vn/apartment_service/internal/domain/service"
+)
+
+var errInvalidToken = errors.New("invalid unsubscribe token")
+
+type unsubscribeLinks struct {
+	baseURL string
+	secret  []byte
+}
+
+// NewUnsubscribeLinks signs unsubscribe tokens with an HMAC of the user and
+// category, so links work without a login and cannot be forged for other
+// users. Links do not expire.
+func NewUnsubscribeLinks(baseURL, secret string) (service.UnsubscribeLinks, error) {
+	if secret == "" {
+		return nil, errors.New("mail unsubscribe secret is not configured")
+	}
+	return &unsubscribeLinks{baseURL: baseURL, secret: []byte(secret)}, nil
+}
+
+func (l *unsubscribeLinks) URL(userID int, category string) string {
+	payload := fmt.Sprintf("%d:%s", userID, category)
+	token := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(l.sign(payload))
+	return l.baseURL + "?token=" + url.QueryEscape(token)
+}
+
+func (l *unsubscribeLinks) Parse(token string) (int, string, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", errInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return 0, "", errInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return 0, "", errInvalidToken
+	}
+	if !hmac.Equal(sig, l.sign(string(payload))) {
+		return 0, "", errInvalidToken
+	}
+
+	rawUserID, category, ok := strings.Cut(string(payload), ":")
+	if !ok {
+		return 0, "", errInvalidToken
+	}
+	userID, err := strconv.Atoi(rawUserID)
+	if err != nil {
+		return 0, "", errInvalidToken
+	}
+
+	return userID, category, nil
+}
+
+func (l *unsubscribeLinks) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -15,6 +15,7 @@ func GetESMigrations() []xesmigration.Migration {
 	return []xesmigration.Migration{
 		esmg.CreateTestUsersIndex{},
 		esmg.AddFieldsToTestUsersIndex{},
+		esmg.CreateChatMessagesIndex{},
 		// Add your ES migrations here
 	}
 }
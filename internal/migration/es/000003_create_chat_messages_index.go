@@ -0,0 +1,82 @@
+package esmg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v6"
+
+	xes "thomas.vn/apartment_service/pkg/es"
+)
+
+type CreateChatMessagesIndex struct{}
+
+func (c CreateChatMessagesIndex) Version() int {
+	return 3
+}
+
+// Up creates the chat message search index. message_text keeps the
+// Vietnamese diacritics; message_text.folded strips them (and maps đ to d)
+// so that "nha" also finds "nhà".
+func (c CreateChatMessagesIndex) Up(ctx context.Context, client *elasticsearch.Client) error {
+	indexName := "chat_messages_v1"
+	aliasName := "chat_messages"
+
+	mapping := `{
+        "settings": {
+            "number_of_shards": 1,
+            "number_of_replicas": 1,
+            "analysis": {
+                "analyzer": {
+                    "vi_text": {
+                        "type": "custom",
+                        "tokenizer": "standard",
+                        "filter": ["lowercase"]
+                    },
+                    "vi_folded": {
+                        "type": "custom",
+                        "tokenizer": "standard",
+                        "filter": ["lowercase", "asciifolding"]
+                    }
+                }
+            }
+        },
+        "mappings": {
+            "doc": {
+                "properties": {
+                    "id": { "type": "long" },
+                    "chat_group_id": { "type": "long" },
+                    "user_id_sender": { "type": "long" },
+                    "parent_id": { "type": "long" },
+                    "seq": { "type": "long" },
+                    "message_text": {
+                        "type": "text",
+                        "analyzer": "vi_text",
+                        "fields": {
+                            "folded": { "type": "text", "analyzer": "vi_folded" }
+                        }
+                    },
+                    "created_at": { "type": "date" },
+                    "edited_at": { "type": "date" }
+                }
+            }
+        }
+    }`
+
+	if err := xes.CreateIndex(ctx, client, indexName, mapping); err != nil {
+		return err
+	}
+
+	return xes.AddAlias(ctx, client, indexName, aliasName)
+}
+
+func (c CreateChatMessagesIndex) Down(ctx context.Context, client *elasticsearch.Client) error {
+	indexName := "chat_messages_v1"
+	aliasName := "chat_messages"
+
+	if err := xes.RemoveAlias(ctx, client, indexName, aliasName); err != nil {
+		fmt.Println("Error removing alias:", err)
+	}
+
+	return xes.DeleteIndex(ctx, client, indexName)
+}
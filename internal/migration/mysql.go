@@ -16,6 +16,22 @@ func GetMySQLMigrations() []xmigration.Migration {
 	return []xmigration.Migration{
 		mysqlmg.AddIsActiveToUsers{},
 		mysqlmg.AddCreatedByToPermission{},
+		mysqlmg.CreateBuildingTables{},
+		mysqlmg.CreateUserRolesTable{},
+		mysqlmg.CreateAuditLogsTable{},
+		mysqlmg.AddSoftDeleteIndexes{},
+		mysqlmg.AddChatGroupRoles{},
+		mysqlmg.AddChatReadReceipts{},
+		mysqlmg.AddChatMessageThreads{},
+		mysqlmg.CreateChatAttachmentsTable{},
+		mysqlmg.AddChatMessageSequence{},
+		mysqlmg.AddChatMemberNotifications{},
+		mysqlmg.CreateChatModerationTables{},
+		mysqlmg.CreateNotificationsTable{},
+		mysqlmg.AddUserLocale{},
+		mysqlmg.CreateMailOutboxTable{},
+		mysqlmg.CreateNotificationPreferencesTable{},
+		mysqlmg.BackfillBuildingIDs{},
 		// Add more migrations here
 	}
 }
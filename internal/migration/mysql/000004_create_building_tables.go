@@ -0,0 +1,29 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type CreateBuildingTables struct{}
+
+func (m CreateBuildingTables) Version() int {
+	return 3
+}
+
+func (m CreateBuildingTables) Up(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE IF NOT EXISTS buildings (
+			id INT NOT NULL AUTO_INCREMENT,
+			name VARCHAR(255) NOT NULL,
+			address VARCHAR(255) NOT NULL DEFAULT '',
+			deleted_by INT NOT NULL DEFAULT 0,
+			is_deleted TINYINT(1) NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME NULL,
+			PRIMARY KEY (id)
+		)
+	`).Error
+}
+
+func (m CreateBuildingTables) Down(tx *gorm.DB) error {
+	return tx.Exec(`DROP TABLE IF EXISTS buildings`).Error
+}
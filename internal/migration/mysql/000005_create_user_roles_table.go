@@ -0,0 +1,69 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type CreateUserRolesTable struct{}
+
+func (m CreateUserRolesTable) Version() int {
+	return 4
+}
+
+func (m CreateUserRolesTable) Up(tx *gorm.DB) error {
+	queries := []string{
+		`
+		CREATE TABLE IF NOT EXISTS user_roles (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			user_id INT NOT NULL,
+			role_id INT NOT NULL,
+			building_id BIGINT NOT NULL DEFAULT 0 COMMENT '0 = global assignment',
+			created_by INT NOT NULL DEFAULT 0,
+			deleted_by INT NOT NULL DEFAULT 0,
+			is_deleted TINYINT(1) NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at DATETIME NULL,
+			PRIMARY KEY (id),
+			KEY idx_user_roles_user_building (user_id, building_id)
+		)
+		`,
+		`
+		ALTER TABLE articles
+		ADD COLUMN building_id BIGINT NOT NULL DEFAULT 0,
+		ADD KEY idx_articles_building_id (building_id)
+		`,
+		`
+		ALTER TABLE chat_groups
+		ADD COLUMN building_id BIGINT NOT NULL DEFAULT 0,
+		ADD KEY idx_chat_groups_building_id (building_id)
+		`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1060) || isMySQLError(err, 1061) {
+				continue
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m CreateUserRolesTable) Down(tx *gorm.DB) error {
+	queries := []string{
+		`ALTER TABLE chat_groups DROP COLUMN building_id`,
+		`ALTER TABLE articles DROP COLUMN building_id`,
+		`DROP TABLE IF EXISTS user_roles`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1091) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type CreateAuditLogsTable struct{}
+
+func (m CreateAuditLogsTable) Version() int {
+	return 5
+}
+
+func (m CreateAuditLogsTable) Up(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_logs (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			actor_id INT NOT NULL DEFAULT 0,
+			action VARCHAR(64) NOT NULL,
+			target_type VARCHAR(64) NOT NULL,
+			target_id BIGINT NOT NULL DEFAULT 0,
+			changes JSON NULL,
+			ip VARCHAR(64) NOT NULL DEFAULT '',
+			user_agent VARCHAR(512) NOT NULL DEFAULT '',
+			request_id VARCHAR(64) NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_audit_logs_actor (actor_id),
+			KEY idx_audit_logs_target (target_type, target_id),
+			KEY idx_audit_logs_action_created (action, created_at)
+		)
+	`).Error
+}
+
+func (m CreateAuditLogsTable) Down(tx *gorm.DB) error {
+	return tx.Exec(`DROP TABLE IF EXISTS audit_logs`).Error
+}
@@ -0,0 +1,38 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+// softDeleteTables are the tables purged by the retention job.
+var softDeleteTables = []string{"users", "articles", "chat_groups", "chat_group_members", "chat_messages"}
+
+type AddSoftDeleteIndexes struct{}
+
+func (m AddSoftDeleteIndexes) Version() int {
+	return 6
+}
+
+func (m AddSoftDeleteIndexes) Up(tx *gorm.DB) error {
+	for _, table := range softDeleteTables {
+		err := tx.Exec(`ALTER TABLE ` + table + ` ADD INDEX idx_` + table + `_soft_delete (is_deleted, deleted_at)`).Error
+		if err != nil {
+			if isMySQLError(err, 1061) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (m AddSoftDeleteIndexes) Down(tx *gorm.DB) error {
+	for _, table := range softDeleteTables {
+		err := tx.Exec(`ALTER TABLE ` + table + ` DROP INDEX idx_` + table + `_soft_delete`).Error
+		if err != nil {
+			if isMySQLError(err, 1091) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
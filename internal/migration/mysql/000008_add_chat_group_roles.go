@@ -0,0 +1,56 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type AddChatGroupRoles struct{}
+
+func (m AddChatGroupRoles) Version() int {
+	return 7
+}
+
+func (m AddChatGroupRoles) Up(tx *gorm.DB) error {
+	queries := []string{
+		`
+		ALTER TABLE chat_group_members
+		ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'member'
+		`,
+		`
+		ALTER TABLE chat_messages
+		ADD COLUMN message_type VARCHAR(20) NOT NULL DEFAULT 'text'
+		`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1060) {
+				continue
+			}
+			return err
+		}
+	}
+
+	// Group owners become the first group admins.
+	return tx.Exec(`
+		UPDATE chat_group_members cgm
+		JOIN chat_groups cg ON cg.id = cgm.chat_group_id
+		SET cgm.role = 'admin'
+		WHERE cgm.user_id = cg.owner_id
+	`).Error
+}
+
+func (m AddChatGroupRoles) Down(tx *gorm.DB) error {
+	queries := []string{
+		`ALTER TABLE chat_group_members DROP COLUMN role`,
+		`ALTER TABLE chat_messages DROP COLUMN message_type`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1091) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
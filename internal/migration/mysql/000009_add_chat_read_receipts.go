@@ -0,0 +1,50 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type AddChatReadReceipts struct{}
+
+func (m AddChatReadReceipts) Version() int {
+	return 8
+}
+
+func (m AddChatReadReceipts) Up(tx *gorm.DB) error {
+	queries := []string{
+		`
+		ALTER TABLE chat_group_members
+		ADD COLUMN last_read_message_id BIGINT NULL DEFAULT NULL
+		`,
+		// Serves the latest message and unread count lookups per group.
+		`
+		ALTER TABLE chat_messages
+		ADD INDEX idx_chat_messages_group_id (chat_group_id, id)
+		`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1060) || isMySQLError(err, 1061) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (m AddChatReadReceipts) Down(tx *gorm.DB) error {
+	queries := []string{
+		`ALTER TABLE chat_group_members DROP COLUMN last_read_message_id`,
+		`ALTER TABLE chat_messages DROP INDEX idx_chat_messages_group_id`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1091) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
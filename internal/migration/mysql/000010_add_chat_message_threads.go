@@ -0,0 +1,77 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type AddChatMessageThreads struct{}
+
+func (m AddChatMessageThreads) Version() int {
+	return 9
+}
+
+func (m AddChatMessageThreads) Up(tx *gorm.DB) error {
+	queries := []string{
+		`
+		ALTER TABLE chat_messages
+		ADD COLUMN parent_id BIGINT NULL DEFAULT NULL,
+		ADD COLUMN edited_at DATETIME NULL DEFAULT NULL
+		`,
+		`
+		ALTER TABLE chat_messages
+		ADD INDEX idx_chat_messages_parent (parent_id)
+		`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1060) || isMySQLError(err, 1061) {
+				continue
+			}
+			return err
+		}
+	}
+
+	if err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS chat_message_edits (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			chat_message_id BIGINT NOT NULL,
+			message_text TEXT NOT NULL,
+			edited_by INT NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_chat_message_edits_message (chat_message_id)
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		CREATE TABLE IF NOT EXISTS chat_message_reactions (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			chat_message_id BIGINT NOT NULL,
+			user_id INT NOT NULL,
+			emoji VARCHAR(32) NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			UNIQUE KEY uq_chat_message_reactions (chat_message_id, user_id, emoji)
+		)
+	`).Error
+}
+
+func (m AddChatMessageThreads) Down(tx *gorm.DB) error {
+	queries := []string{
+		`DROP TABLE IF EXISTS chat_message_reactions`,
+		`DROP TABLE IF EXISTS chat_message_edits`,
+		`ALTER TABLE chat_messages DROP INDEX idx_chat_messages_parent`,
+		`ALTER TABLE chat_messages DROP COLUMN parent_id, DROP COLUMN edited_at`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1091) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
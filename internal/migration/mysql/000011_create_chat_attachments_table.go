@@ -0,0 +1,39 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type CreateChatAttachmentsTable struct{}
+
+func (m CreateChatAttachmentsTable) Version() int {
+	return 10
+}
+
+func (m CreateChatAttachmentsTable) Up(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE IF NOT EXISTS chat_message_attachments (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			chat_message_id BIGINT NULL DEFAULT NULL,
+			chat_group_id BIGINT NOT NULL,
+			uploaded_by INT NOT NULL,
+			kind VARCHAR(20) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			file_name VARCHAR(255) NOT NULL,
+			content_type VARCHAR(100) NOT NULL DEFAULT '',
+			size BIGINT NOT NULL DEFAULT 0,
+			storage_key VARCHAR(255) NOT NULL DEFAULT '',
+			resource_type VARCHAR(20) NOT NULL DEFAULT '',
+			format VARCHAR(20) NOT NULL DEFAULT '',
+			width INT NOT NULL DEFAULT 0,
+			height INT NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_chat_message_attachments_message (chat_message_id),
+			KEY idx_chat_message_attachments_uploader (uploaded_by, chat_group_id)
+		)
+	`).Error
+}
+
+func (m CreateChatAttachmentsTable) Down(tx *gorm.DB) error {
+	return tx.Exec(`DROP TABLE IF EXISTS chat_message_attachments`).Error
+}
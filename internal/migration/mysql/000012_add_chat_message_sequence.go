@@ -0,0 +1,76 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type AddChatMessageSequence struct{}
+
+func (m AddChatMessageSequence) Version() int {
+	return 11
+}
+
+func (m AddChatMessageSequence) Up(tx *gorm.DB) error {
+	queries := []string{
+		`
+		ALTER TABLE chat_groups
+		ADD COLUMN last_seq BIGINT NOT NULL DEFAULT 0
+		`,
+		`
+		ALTER TABLE chat_messages
+		ADD COLUMN seq BIGINT NOT NULL DEFAULT 0
+		`,
+		`
+		ALTER TABLE chat_group_members
+		ADD COLUMN last_delivered_seq BIGINT NOT NULL DEFAULT 0
+		`,
+		// Number the existing messages of each group in insertion order.
+		`
+		UPDATE chat_messages cm
+		JOIN (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY chat_group_id ORDER BY id) AS seq
+			FROM chat_messages
+		) numbered ON numbered.id = cm.id
+		SET cm.seq = numbered.seq
+		WHERE cm.seq = 0
+		`,
+		`
+		UPDATE chat_groups g
+		SET g.last_seq = (
+			SELECT COALESCE(MAX(cm.seq), 0) FROM chat_messages cm WHERE cm.chat_group_id = g.id
+		)
+		`,
+		// Serves the replay of missed messages on RESUME.
+		`
+		ALTER TABLE chat_messages
+		ADD UNIQUE INDEX uq_chat_messages_group_seq (chat_group_id, seq)
+		`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1060) || isMySQLError(err, 1061) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (m AddChatMessageSequence) Down(tx *gorm.DB) error {
+	queries := []string{
+		`ALTER TABLE chat_messages DROP INDEX uq_chat_messages_group_seq`,
+		`ALTER TABLE chat_group_members DROP COLUMN last_delivered_seq`,
+		`ALTER TABLE chat_messages DROP COLUMN seq`,
+		`ALTER TABLE chat_groups DROP COLUMN last_seq`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1091) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type AddChatMemberNotifications struct{}
+
+func (m AddChatMemberNotifications) Version() int {
+	return 12
+}
+
+func (m AddChatMemberNotifications) Up(tx *gorm.DB) error {
+	queries := []string{
+		`
+		ALTER TABLE chat_group_members
+		ADD COLUMN muted_until DATETIME NULL DEFAULT NULL
+		`,
+		`
+		ALTER TABLE chat_group_members
+		ADD COLUMN last_notified_message_id BIGINT NULL DEFAULT NULL
+		`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1060) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (m AddChatMemberNotifications) Down(tx *gorm.DB) error {
+	queries := []string{
+		`ALTER TABLE chat_group_members DROP COLUMN last_notified_message_id`,
+		`ALTER TABLE chat_group_members DROP COLUMN muted_until`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			if isMySQLError(err, 1091) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
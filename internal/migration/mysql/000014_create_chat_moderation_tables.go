@@ -0,0 +1,63 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type CreateChatModerationTables struct{}
+
+func (m CreateChatModerationTables) Version() int {
+	return 13
+}
+
+func (m CreateChatModerationTables) Up(tx *gorm.DB) error {
+	queries := []string{
+		`
+		CREATE TABLE IF NOT EXISTS user_blocks (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			blocker_id INT NOT NULL,
+			blocked_id INT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			UNIQUE KEY uq_user_blocks_pair (blocker_id, blocked_id),
+			KEY idx_user_blocks_blocked (blocked_id)
+		)
+		`,
+		`
+		CREATE TABLE IF NOT EXISTS chat_message_reports (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			chat_message_id BIGINT NOT NULL,
+			chat_group_id BIGINT NOT NULL,
+			reporter_id INT NOT NULL,
+			reason VARCHAR(500) NOT NULL DEFAULT '',
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			reviewed_by INT NULL DEFAULT NULL,
+			reviewed_at DATETIME NULL DEFAULT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			UNIQUE KEY uq_chat_message_reports_reporter (chat_message_id, reporter_id),
+			KEY idx_chat_message_reports_status (status, id)
+		)
+		`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m CreateChatModerationTables) Down(tx *gorm.DB) error {
+	queries := []string{
+		`DROP TABLE IF EXISTS chat_message_reports`,
+		`DROP TABLE IF EXISTS user_blocks`,
+	}
+
+	for _, q := range queries {
+		if err := tx.Exec(q).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type CreateNotificationsTable struct{}
+
+func (m CreateNotificationsTable) Version() int {
+	return 14
+}
+
+func (m CreateNotificationsTable) Up(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE IF NOT EXISTS notifications (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			user_id INT NOT NULL,
+			type VARCHAR(50) NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			body TEXT NOT NULL,
+			link VARCHAR(500) NOT NULL DEFAULT '',
+			read_at DATETIME NULL DEFAULT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_notifications_user (user_id, id),
+			KEY idx_notifications_user_unread (user_id, read_at)
+		)
+	`).Error
+}
+
+func (m CreateNotificationsTable) Down(tx *gorm.DB) error {
+	return tx.Exec(`DROP TABLE IF EXISTS notifications`).Error
+}
@@ -0,0 +1,28 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type AddUserLocale struct{}
+
+func (m AddUserLocale) Version() int {
+	return 15
+}
+
+func (m AddUserLocale) Up(tx *gorm.DB) error {
+	err := tx.Exec(`
+		ALTER TABLE users
+		ADD COLUMN locale VARCHAR(8) NOT NULL DEFAULT 'vi'
+	`).Error
+	if err != nil && !isMySQLError(err, 1060) {
+		return err
+	}
+	return nil
+}
+
+func (m AddUserLocale) Down(tx *gorm.DB) error {
+	err := tx.Exec(`ALTER TABLE users DROP COLUMN locale`).Error
+	if err != nil && !isMySQLError(err, 1091) {
+		return err
+	}
+	return nil
+}
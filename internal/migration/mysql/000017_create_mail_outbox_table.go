@@ -0,0 +1,37 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type CreateMailOutboxTable struct{}
+
+func (m CreateMailOutboxTable) Version() int {
+	return 16
+}
+
+func (m CreateMailOutboxTable) Up(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE IF NOT EXISTS mail_outbox (
+			id BIGINT NOT NULL AUTO_INCREMENT,
+			template VARCHAR(50) NOT NULL,
+			recipient VARCHAR(255) NOT NULL,
+			locale VARCHAR(8) NOT NULL DEFAULT '',
+			payload JSON NOT NULL,
+			subject VARCHAR(255) NOT NULL DEFAULT '',
+			status VARCHAR(20) NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			message_id VARCHAR(255) NOT NULL DEFAULT '',
+			error TEXT NULL,
+			next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			sent_at DATETIME NULL DEFAULT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (id),
+			KEY idx_mail_outbox_due (status, next_attempt_at),
+			KEY idx_mail_outbox_recipient (recipient)
+		)
+	`).Error
+}
+
+func (m CreateMailOutboxTable) Down(tx *gorm.DB) error {
+	return tx.Exec(`DROP TABLE IF EXISTS mail_outbox`).Error
+}
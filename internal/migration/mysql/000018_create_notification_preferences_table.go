@@ -0,0 +1,26 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+type CreateNotificationPreferencesTable struct{}
+
+func (m CreateNotificationPreferencesTable) Version() int {
+	return 17
+}
+
+func (m CreateNotificationPreferencesTable) Up(tx *gorm.DB) error {
+	return tx.Exec(`
+		CREATE TABLE IF NOT EXISTS notification_preferences (
+			user_id INT NOT NULL,
+			category VARCHAR(50) NOT NULL,
+			channel VARCHAR(20) NOT NULL,
+			enabled TINYINT(1) NOT NULL DEFAULT 1,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, category, channel)
+		)
+	`).Error
+}
+
+func (m CreateNotificationPreferencesTable) Down(tx *gorm.DB) error {
+	return tx.Exec(`DROP TABLE IF EXISTS notification_preferences`).Error
+}
@@ -0,0 +1,42 @@
+package mysqlmg
+
+import "gorm.io/gorm"
+
+// BackfillBuildingIDs places existing articles and chat groups in their
+// author's or owner's building when that user belongs to exactly one.
+type BackfillBuildingIDs struct{}
+
+func (m BackfillBuildingIDs) Version() int {
+	return 18
+}
+
+const singleBuildingUsers = `
+	SELECT user_id, MIN(building_id) AS building_id
+	FROM user_roles
+	WHERE is_deleted = 0 AND building_id <> 0
+	GROUP BY user_id
+	HAVING COUNT(DISTINCT building_id) = 1
+`
+
+func (m BackfillBuildingIDs) Up(tx *gorm.DB) error {
+	if err := tx.Exec(`
+		UPDATE chat_groups cg
+		JOIN (` + singleBuildingUsers + `) ub ON ub.user_id = cg.owner_id
+		SET cg.building_id = ub.building_id
+		WHERE cg.building_id = 0
+	`).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		UPDATE articles a
+		JOIN (` + singleBuildingUsers + `) ub ON ub.user_id = a.user_id
+		SET a.building_id = ub.building_id
+		WHERE a.building_id = 0
+	`).Error
+}
+
+// Down keeps the backfilled values; they are valid under the old schema.
+func (m BackfillBuildingIDs) Down(tx *gorm.DB) error {
+	return nil
+}
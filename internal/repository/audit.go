@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+type auditRepository struct {
+	logger     *xlogger.Logger
+	auditTable *gorm.DB
+}
+
+func NewAuditRepository(logger *xlogger.Logger, db *gorm.DB) repository.AuditRepository {
+	return &auditRepository{
+		logger:     logger,
+		auditTable: db.Table("audit_logs"),
+	}
+}
+
+func (r *auditRepository) CreateAuditLog(ctx context.Context, log *model.AuditLog) error {
+	log.CreatedAt = xutils.GetTimeNow()
+
+	result := r.auditTable.WithContext(ctx).Create(log)
+	if result.Error != nil {
+		r.logger.Error("Create audit log failed", xlogger.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("create audit log failed")
+	}
+
+	return nil
+}
+
+func (r *auditRepository) ListAuditLogs(ctx context.Context, req *model.ListAuditLogRequest) ([]*model.AuditLog, int64, error) {
+	var (
+		logs  []*model.AuditLog
+		total int64
+	)
+
+	query := r.auditTable.WithContext(ctx)
+
+	if req.ActorID != 0 {
+		query = query.Where("actor_id = ?", req.ActorID)
+	}
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	if req.TargetType != "" {
+		query = query.Where("target_type = ?", req.TargetType)
+	}
+	if req.TargetID != 0 {
+		query = query.Where("target_id = ?", req.TargetID)
+	}
+	if req.FromDate != "" {
+		query = query.Where("created_at >= ?", req.FromDate+" 00:00:00")
+	}
+	if req.ToDate != "" {
+		query = query.Where("created_at <= ?", req.ToDate+" 23:59:59")
+	}
+
+	if !req.ExcludeTotal {
+		if err := query.Count(&total).Error; err != nil {
+			r.logger.Error("Count audit logs failed", xlogger.Error(err))
+			return nil, 0, err
+		}
+	}
+
+	query = xutils.ApplyPagination(query, req.Page, req.Limit)
+
+	if err := query.Order("id DESC").Find(&logs).Error; err != nil {
+		r.logger.Error("List audit logs failed", xlogger.Error(err))
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
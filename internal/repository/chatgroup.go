@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"sort"
+	"time"
 
 	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model"
 	"thomas.vn/apartment_service/internal/domain/model/chatgroup"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xsoftdelete "thomas.vn/apartment_service/pkg/softdelete"
+	xtenant "thomas.vn/apartment_service/pkg/tenant"
 	xutils "thomas.vn/apartment_service/pkg/utils"
 )
 
@@ -16,6 +20,8 @@ type ChatGroupRepository struct {
 	logger               *xlogger.Logger
 	chatGroupTable       *gorm.DB
 	chatGroupMemberTable *gorm.DB
+	groupSoftDelete      *xsoftdelete.Table
+	memberSoftDelete     *xsoftdelete.Table
 }
 
 func NewChatGroupRepository(logger *xlogger.Logger, db *gorm.DB) *ChatGroupRepository {
@@ -23,66 +29,149 @@ func NewChatGroupRepository(logger *xlogger.Logger, db *gorm.DB) *ChatGroupRepos
 		logger:               logger,
 		chatGroupTable:       db.Table("chat_groups"),
 		chatGroupMemberTable: db.Table("chat_group_members"),
+		groupSoftDelete:      xsoftdelete.New(db, "chat_groups"),
+		memberSoftDelete:     xsoftdelete.New(db, "chat_group_members"),
 	}
 }
 
-func (r *ChatGroupRepository) ListChatGroupsWithMembers(ctx context.Context, req *chatgroup.ListChatGroupRequest) ([]*chatgroup.ListResponse, int64, error) {
+// ListChatGroupsWithMembers returns the conversations of the user, most
+// recently active first, with a preview of the latest message and the
+// number of messages from others the user has not read yet.
+func (r *ChatGroupRepository) ListChatGroupsWithMembers(ctx context.Context, userID int64, req *chatgroup.ListChatGroupRequest) ([]*chatgroup.ListResponse, int64, error) {
 
-	var rows []*chatgroup.Row
+	var rows []*chatgroup.ConversationRow
 	var total int64
 
-	query := r.chatGroupTable.WithContext(ctx).
-		Table("chat_groups cg").
-		Select(`
-			cg.id   as group_id,
-			cg.name as group_name,
-			u.id    as user_id,
-			u.full_name,
-			u.avatar
-		`).
-		Joins("JOIN chat_group_members cgm ON cgm.chat_group_id = cg.id").
-		Joins("JOIN users u ON u.id = cgm.user_id").
-		Where("cg.is_deleted = 0")
+	query := r.chatGroupMemberTable.WithContext(ctx).
+		Table("chat_group_members cgm").
+		Joins("JOIN chat_groups cg ON cg.id = cgm.chat_group_id").
+		Where("cgm.user_id = ?", userID).
+		Scopes(
+			xsoftdelete.Scope("cgm.is_deleted", false),
+			xsoftdelete.Scope("cg.is_deleted", req.IsDeleted == xsoftdelete.Deleted),
+		)
 
 	if req.IsOne {
 		query = query.Where("cg.key_for_chat_one != ''")
 	}
 
-	if err := query.Find(&rows).Error; err != nil {
-		return nil, 0, err
+	if !req.ExcludeTotal {
+		if err := query.Count(&total).Error; err != nil {
+			r.logger.Error("Count conversations failed", xlogger.Error(err))
+			return nil, 0, err
+		}
+	}
+
+	page, limit := req.Page, req.Limit
+	if page <= 0 {
+		page = 1
 	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	query = query.
+		Select(`
+			cg.id   as group_id,
+			cg.name as group_name,
+			cgm.last_read_message_id,
+			lm.id             as last_message_id,
+			COALESCE(lm.message_text, '')   as last_message_text,
+			COALESCE(lm.message_type, '')   as last_message_type,
+			COALESCE(lm.user_id_sender, 0)  as last_message_sender_id,
+			COALESCE(lm.created_at, cg.created_at) as last_message_at,
+			COALESCE(lm.created_at, cg.created_at) as last_activity_at,
+			(
+				SELECT COUNT(*) FROM chat_messages um
+				WHERE um.chat_group_id = cg.id
+				AND um.is_deleted = 0
+				AND um.id > COALESCE(cgm.last_read_message_id, 0)
+				AND um.user_id_sender <> cgm.user_id
+			) as unread_count
+		`).
+		Joins(`LEFT JOIN chat_messages lm ON lm.id = (
+			SELECT MAX(m.id) FROM chat_messages m
+			WHERE m.chat_group_id = cg.id AND m.is_deleted = 0
+		)`).
+		Order("last_activity_at DESC, cg.id DESC")
 
-	groupMap := map[int64]*chatgroup.ListResponse{}
+	if err := xutils.ApplyPagination(query, page, limit).Scan(&rows).Error; err != nil {
+		r.logger.Error("List conversations failed", xlogger.Error(err))
+		return nil, 0, err
+	}
 
-	for _, r := range rows {
-		if _, ok := groupMap[r.GroupID]; !ok {
-			groupMap[r.GroupID] = &chatgroup.ListResponse{
-				ID:   r.GroupID,
-				Name: r.GroupName,
+	result := make([]*chatgroup.ListResponse, 0, len(rows))
+	groupMap := make(map[int64]*chatgroup.ListResponse, len(rows))
+	groupIDs := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		item := &chatgroup.ListResponse{
+			ID:               row.GroupID,
+			Name:             row.GroupName,
+			UnreadCount:      row.UnreadCount,
+			LastActivityAt:   row.LastActivityAt,
+			ChatGroupMembers: []chatgroup.MemberResponse{},
+		}
+		if row.LastReadMessageID != nil {
+			item.LastReadMessageID = *row.LastReadMessageID
+		}
+		if row.LastMessageID != nil {
+			item.LastMessage = &chatgroup.LastMessage{
+				ID:          *row.LastMessageID,
+				MessageText: row.LastMessageText,
+				MessageType: row.LastMessageType,
+				SenderID:    row.LastMessageSenderID,
+				CreatedAt:   row.LastMessageAt,
 			}
 		}
 
-		groupMap[r.GroupID].ChatGroupMembers = append(
-			groupMap[r.GroupID].ChatGroupMembers,
-			chatgroup.MemberResponse{
-				UserID: r.UserID,
-				Users: chatgroup.UserResponse{
-					ID:       r.UserID,
-					FullName: r.FullName,
-					Avatar:   r.Avatar,
-				},
-			},
-		)
+		result = append(result, item)
+		groupMap[row.GroupID] = item
+		groupIDs = append(groupIDs, row.GroupID)
+	}
+
+	if len(groupIDs) == 0 {
+		return result, total, nil
 	}
 
-	result := make([]*chatgroup.ListResponse, 0, len(groupMap))
-	for _, v := range groupMap {
-		result = append(result, v)
+	var members []*chatgroup.Row
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Table("chat_group_members cgm").
+		Select(`
+			cgm.chat_group_id as group_id,
+			cgm.role,
+			u.id as user_id,
+			u.full_name,
+			u.avatar
+		`).
+		Joins("JOIN users u ON u.id = cgm.user_id").
+		Where("cgm.chat_group_id IN ?", groupIDs).
+		Scopes(
+			xsoftdelete.Scope("cgm.is_deleted", false),
+			xsoftdelete.Scope("u.is_deleted", false),
+		).
+		Order("cgm.id ASC").
+		Scan(&members).Error
+	if err != nil {
+		r.logger.Error("List conversation members failed", xlogger.Error(err))
+		return nil, 0, err
+	}
+
+	for _, m := range members {
+		group := groupMap[m.GroupID]
+		group.ChatGroupMembers = append(group.ChatGroupMembers, chatgroup.MemberResponse{
+			UserID: m.UserID,
+			Role:   m.Role,
+			Users: chatgroup.UserResponse{
+				ID:       m.UserID,
+				FullName: m.FullName,
+				Avatar:   m.Avatar,
+			},
+		})
 	}
 
-	total = int64(len(result))
 	return result, total, nil
 }
+
 func (r *ChatGroupRepository) CreateChatGroup(ctx context.Context, chatGroup *chatgroup.ChatGroup) (*chatgroup.ChatGroup, error) {
 
 	chatGroup.CreatedAt = xutils.GetTimeNow()
@@ -102,6 +191,7 @@ func (r *ChatGroupRepository) AddMembers(ctx context.Context, req *chatgroup.Cre
 		members = append(members, &model.ChatGroupMembers{
 			ChatGroupID: req.ChatGroupID,
 			UserID:      uid,
+			Role:        req.Role,
 		})
 	}
 
@@ -126,6 +216,7 @@ func (r *ChatGroupRepository) FindChatOneByUserIDs(ctx context.Context, userIDs
 
 	err := r.chatGroupTable.
 		WithContext(ctx).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
 		Where(`
 			JSON_CONTAINS(key_for_chat_one, JSON_ARRAY(?), '$.user_ids')
 			AND JSON_CONTAINS(key_for_chat_one, JSON_ARRAY(?), '$.user_ids')
@@ -142,3 +233,302 @@ func (r *ChatGroupRepository) FindChatOneByUserIDs(ctx context.Context, userIDs
 
 	return &group, nil
 }
+
+func (r *ChatGroupRepository) IsMember(ctx context.Context, chatGroupID int64, userID int64) (bool, error) {
+	var count int64
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Table("chat_group_members cgm").
+		Joins("JOIN chat_groups cg ON cg.id = cgm.chat_group_id").
+		Where("cgm.chat_group_id = ? AND cgm.user_id = ?", chatGroupID, userID).
+		Scopes(
+			xsoftdelete.Scope("cgm.is_deleted", false),
+			xsoftdelete.Scope("cg.is_deleted", false),
+		).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Check chat group member failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// ListMemberGroupIDs returns the chat groups the user belongs to.
+func (r *ChatGroupRepository) ListMemberGroupIDs(ctx context.Context, userID int64) ([]int64, error) {
+	var ids []int64
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Table("chat_group_members cgm").
+		Joins("JOIN chat_groups cg ON cg.id = cgm.chat_group_id").
+		Where("cgm.user_id = ?", userID).
+		Scopes(
+			xsoftdelete.Scope("cgm.is_deleted", false),
+			xsoftdelete.Scope("cg.is_deleted", false),
+		).
+		Pluck("cgm.chat_group_id", &ids).Error
+	if err != nil {
+		r.logger.Error("List member chat groups failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *ChatGroupRepository) GetChatGroupByID(ctx context.Context, id int64) (*chatgroup.ChatGroup, error) {
+	return r.getChatGroup(ctx, id, xsoftdelete.Scope("is_deleted", false))
+}
+
+func (r *ChatGroupRepository) GetChatGroupByIDWithDeleted(ctx context.Context, id int64) (*chatgroup.ChatGroup, error) {
+	return r.getChatGroup(ctx, id)
+}
+
+func (r *ChatGroupRepository) getChatGroup(ctx context.Context, id int64, scopes ...func(*gorm.DB) *gorm.DB) (*chatgroup.ChatGroup, error) {
+	var group chatgroup.ChatGroup
+	err := r.chatGroupTable.WithContext(ctx).
+		Scopes(scopes...).
+		Scopes(xtenant.Filter(ctx, "building_id")).
+		Where("id = ?", id).
+		First(&group).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Get chat group by id failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+func (r *ChatGroupRepository) GetMember(ctx context.Context, chatGroupID int64, userID int64) (*model.ChatGroupMembers, error) {
+	var member model.ChatGroupMembers
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Where("chat_group_id = ? AND user_id = ?", chatGroupID, userID).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Get chat group member failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// ListMembers returns the live members of the group, oldest first.
+func (r *ChatGroupRepository) ListMembers(ctx context.Context, chatGroupID int64) ([]*model.ChatGroupMembers, error) {
+	var members []*model.ChatGroupMembers
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Where("chat_group_id = ?", chatGroupID).
+		Order("created_at ASC, id ASC").
+		Find(&members).Error
+	if err != nil {
+		r.logger.Error("List chat group members failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *ChatGroupRepository) RemoveMember(ctx context.Context, memberID int) (bool, error) {
+	removed, err := r.memberSoftDelete.Delete(ctx, memberID)
+	if err != nil {
+		r.logger.Error("Remove chat group member failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return removed, nil
+}
+
+func (r *ChatGroupRepository) UpdateMemberRole(ctx context.Context, memberID int, role string) error {
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Where("id = ?", memberID).
+		Updates(map[string]interface{}{
+			"role":       role,
+			"updated_at": xutils.GetTimeNow(),
+		}).Error
+	if err != nil {
+		r.logger.Error("Update chat group member role failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ChatGroupRepository) RenameChatGroup(ctx context.Context, id int64, name string) error {
+	err := r.chatGroupTable.WithContext(ctx).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"name":       name,
+			"updated_at": xutils.GetTimeNow(),
+		}).Error
+	if err != nil {
+		r.logger.Error("Rename chat group failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *ChatGroupRepository) DeleteChatGroup(ctx context.Context, id int64) (bool, error) {
+	deleted, err := r.groupSoftDelete.Delete(ctx, id)
+	if err != nil {
+		r.logger.Error("Delete chat group failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+// MarkRead moves the member's read marker forward to messageID, or to the
+// latest message when messageID is 0. The marker never moves backwards and
+// only points at messages of the group. It returns the stored marker.
+func (r *ChatGroupRepository) MarkRead(ctx context.Context, chatGroupID int64, userID int64, messageID int64) (int64, error) {
+	db := r.chatGroupMemberTable.WithContext(ctx)
+
+	if messageID == 0 {
+		err := db.Table("chat_messages").
+			Select("COALESCE(MAX(id), 0)").
+			Where("chat_group_id = ?", chatGroupID).
+			Scopes(xsoftdelete.Scope("is_deleted", false)).
+			Scan(&messageID).Error
+		if err != nil {
+			r.logger.Error("Get latest chat message failed", xlogger.Error(err))
+			return 0, err
+		}
+	}
+
+	if messageID > 0 {
+		err := r.chatGroupMemberTable.WithContext(ctx).
+			Where("chat_group_id = ? AND user_id = ?", chatGroupID, userID).
+			Where("last_read_message_id IS NULL OR last_read_message_id < ?", messageID).
+			Where("EXISTS (SELECT 1 FROM chat_messages WHERE id = ? AND chat_group_id = ? AND is_deleted = ?)", messageID, chatGroupID, xsoftdelete.NotDeleted).
+			Scopes(xsoftdelete.Scope("is_deleted", false)).
+			Updates(map[string]interface{}{
+				"last_read_message_id": messageID,
+				"updated_at":           xutils.GetTimeNow(),
+			}).Error
+		if err != nil {
+			r.logger.Error("Mark chat messages read failed", xlogger.Error(err))
+			return 0, err
+		}
+	}
+
+	member, err := r.GetMember(ctx, chatGroupID, userID)
+	if err != nil || member == nil || member.LastReadMessageID == nil {
+		return 0, err
+	}
+
+	return *member.LastReadMessageID, nil
+}
+
+// AckDelivery moves the member's delivery marker forward to seq. Like the
+// read marker it never moves backwards; seq is capped at the last message of
+// the group. It returns the stored marker.
+func (r *ChatGroupRepository) AckDelivery(ctx context.Context, chatGroupID int64, userID int64, seq int64) (int64, error) {
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Where("chat_group_id = ? AND user_id = ?", chatGroupID, userID).
+		Where("last_delivered_seq < ?", seq).
+		Where("EXISTS (SELECT 1 FROM chat_groups WHERE id = ? AND last_seq >= ?)", chatGroupID, seq).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Updates(map[string]interface{}{
+			"last_delivered_seq": seq,
+			"updated_at":         xutils.GetTimeNow(),
+		}).Error
+	if err != nil {
+		r.logger.Error("Ack chat messages delivery failed", xlogger.Error(err))
+		return 0, err
+	}
+
+	member, err := r.GetMember(ctx, chatGroupID, userID)
+	if err != nil || member == nil {
+		return 0, err
+	}
+
+	return member.LastDeliveredSeq, nil
+}
+
+// SetMute stores until which time the member muted notifications of the
+// chat group. A nil until lifts the mute.
+func (r *ChatGroupRepository) SetMute(ctx context.Context, chatGroupID int64, userID int64, until *time.Time) error {
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Where("chat_group_id = ? AND user_id = ?", chatGroupID, userID).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Updates(map[string]interface{}{
+			"muted_until": until,
+			"updated_at":  xutils.GetTimeNow(),
+		}).Error
+	if err != nil {
+		r.logger.Error("Mute chat group failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ListDigestCandidates returns, per member and chat group, the text messages
+// of others that the member neither read nor got mailed. Groups muted at now
+// and groups with a message newer than quietBefore are left out, so a digest
+// only goes out once a conversation has calmed down. Rows are ordered by
+// member and group and start after the cursor.
+func (r *ChatGroupRepository) ListDigestCandidates(ctx context.Context, now time.Time, quietBefore time.Time, after chatgroup.DigestCursor, limit int) ([]*chatgroup.DigestRow, error) {
+	var rows []*chatgroup.DigestRow
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Table("chat_group_members cgm").
+		Joins("JOIN chat_groups cg ON cg.id = cgm.chat_group_id").
+		Joins("JOIN users u ON u.id = cgm.user_id").
+		Joins(`JOIN chat_messages cm ON cm.chat_group_id = cgm.chat_group_id
+			AND cm.id > GREATEST(COALESCE(cgm.last_read_message_id, 0), COALESCE(cgm.last_notified_message_id, 0))
+			AND cm.user_id_sender <> cgm.user_id
+			AND cm.message_type = ?
+			AND cm.is_deleted = ?`, consts.ChatMessageTypeText, xsoftdelete.NotDeleted).
+		Select(`
+			cgm.user_id,
+			u.email,
+			u.full_name,
+			u.locale,
+			cgm.chat_group_id,
+			cg.name AS group_name,
+			GREATEST(COALESCE(cgm.last_read_message_id, 0), COALESCE(cgm.last_notified_message_id, 0)) AS after_message_id,
+			MAX(cm.id) AS last_message_id,
+			COUNT(cm.id) AS unread_count`).
+		Where("cgm.muted_until IS NULL OR cgm.muted_until <= ?", now).
+		Where("cgm.user_id > ? OR (cgm.user_id = ? AND cgm.chat_group_id > ?)", after.UserID, after.UserID, after.ChatGroupID).
+		Scopes(
+			xsoftdelete.Scope("cgm.is_deleted", false),
+			xsoftdelete.Scope("cg.is_deleted", false),
+			xsoftdelete.Scope("u.is_deleted", false),
+		).
+		Group("cgm.id, cgm.user_id, u.email, u.full_name, u.locale, cgm.chat_group_id, cg.name, cgm.last_read_message_id, cgm.last_notified_message_id").
+		Having("MAX(cm.created_at) <= ?", quietBefore).
+		Order("cgm.user_id ASC, cgm.chat_group_id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("List chat digest candidates failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// MarkNotified moves the member's digest marker forward to messageID. It
+// never moves backwards.
+func (r *ChatGroupRepository) MarkNotified(ctx context.Context, chatGroupID int64, userID int64, messageID int64) error {
+	err := r.chatGroupMemberTable.WithContext(ctx).
+		Where("chat_group_id = ? AND user_id = ?", chatGroupID, userID).
+		Where("last_notified_message_id IS NULL OR last_notified_message_id < ?", messageID).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Updates(map[string]interface{}{
+			"last_notified_message_id": messageID,
+			"updated_at":               xutils.GetTimeNow(),
+		}).Error
+	if err != nil {
+		r.logger.Error("Mark chat digest notified failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -2,29 +2,61 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
 	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	"thomas.vn/apartment_service/pkg/query"
+	xsoftdelete "thomas.vn/apartment_service/pkg/softdelete"
 	xutils "thomas.vn/apartment_service/pkg/utils"
 )
 
+const chatMessageColumns = `
+	cm.id,
+	cm.seq,
+	cm.chat_group_id,
+	cm.message_text,
+	cm.message_type,
+	cm.parent_id,
+	cm.edited_at,
+	cm.is_deleted,
+	cm.created_at,
+	u.id AS user_id,
+	u.full_name,
+	u.avatar,
+	u.role_id
+`
+
+// errAttachmentsNotLinkable rolls back LinkAttachments.
+var errAttachmentsNotLinkable = errors.New("attachments not linkable")
+
 type chatMessageRepository struct {
 	logger           *xlogger.Logger
+	db               *gorm.DB
 	chatMessageTable *gorm.DB
+	softDelete       *xsoftdelete.Table
 }
 
 func NewChatMessageRepository(logger *xlogger.Logger, db *gorm.DB) repository.ChatMessageRepository {
 	return &chatMessageRepository{
 		logger:           logger,
+		db:               db,
 		chatMessageTable: db.Table("chat_messages"),
+		softDelete:       xsoftdelete.New(db, "chat_messages"),
 	}
 
 }
+
+// ListChatMessages returns a page of messages. Deleted messages stay in the
+// list as placeholders so that replies keep their parent.
 func (r *chatMessageRepository) ListChatMessages(
 	ctx context.Context,
 	req *chatmessage.ListChatMessageRequest,
+	spec *query.Spec,
 ) ([]*chatmessage.Response, int64, error) {
 
 	var rows []*chatmessage.Row
@@ -33,54 +65,146 @@ func (r *chatMessageRepository) ListChatMessages(
 	db := r.chatMessageTable.WithContext(ctx).
 		Table("chat_messages cm").
 		Joins("JOIN users u ON u.id = cm.user_id_sender").
-		Select(`
-			cm.id,
-			cm.chat_group_id,
-			cm.message_text,
-			cm.created_at,
-			u.id AS user_id,
-			u.full_name,
-			u.avatar,
-			u.role_id
-		`)
+		Select(chatMessageColumns).
+		Scopes(spec.Filter())
 
-	if req.ChatGroupID != 0 {
-		db = db.Where("cm.chat_group_id = ?", req.ChatGroupID)
+	// Deleted messages lost their text, so they never match a text search.
+	if spec.Has("message_text") {
+		db = db.Scopes(xsoftdelete.Scope("cm.is_deleted", false))
 	}
 
 	if !req.ExcludeTotal {
 		db.Count(&total)
 	}
 
-	if req.Page > 0 && req.Limit > 0 {
-		db = db.Offset((req.Page - 1) * req.Limit).Limit(req.Limit)
+	db = db.Scopes(spec.Paginate(req.PaginationOptions), spec.Sorting())
+
+	if err := db.Scan(&rows).Error; err != nil {
+		return nil, 0, err
 	}
 
-	if req.SortBy != "" && req.OrderBy != "" {
-		db = db.Order("cm." + req.SortBy + " " + req.OrderBy)
+	res := make([]*chatmessage.Response, 0, len(rows))
+	ids := make([]int, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, r.ToResponse())
+		ids = append(ids, r.ID)
 	}
 
-	if err := db.Scan(&rows).Error; err != nil {
+	if err := r.attachThreadInfo(ctx, res, ids); err != nil {
 		return nil, 0, err
 	}
 
+	return res, total, nil
+}
+
+// ListChatMessagesAfterSeq returns up to limit messages of the group with a
+// seq above afterSeq, in seq order. Deleted messages are included as
+// placeholders so that clients see no gaps.
+func (r *chatMessageRepository) ListChatMessagesAfterSeq(ctx context.Context, chatGroupID int, afterSeq int64, limit int) ([]*chatmessage.Response, error) {
+	var rows []*chatmessage.Row
+	err := r.chatMessageTable.WithContext(ctx).
+		Table("chat_messages cm").
+		Joins("JOIN users u ON u.id = cm.user_id_sender").
+		Select(chatMessageColumns).
+		Where("cm.chat_group_id = ? AND cm.seq > ?", chatGroupID, afterSeq).
+		Order("cm.seq ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("List chat messages after seq failed", xlogger.Error(err))
+		return nil, err
+	}
+
 	res := make([]*chatmessage.Response, 0, len(rows))
+	ids := make([]int, 0, len(rows))
 	for _, r := range rows {
-		res = append(res, &chatmessage.Response{
-			ID:          r.ID,
-			ChatGroupID: r.ChatGroupID,
-			MessageText: r.MessageText,
-			CreatedAt:   r.CreatedAt,
-			Sender: chatmessage.Sender{
-				ID:       r.UserID,
-				FullName: r.FullName,
-				Avatar:   r.Avatar,
-				RoleID:   r.RoleID,
-			},
-		})
+		res = append(res, r.ToResponse())
+		ids = append(ids, r.ID)
 	}
 
-	return res, total, nil
+	if err := r.attachThreadInfo(ctx, res, ids); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ListChatMessagesByIDs returns the messages with the ids that are not
+// deleted, in no particular order.
+func (r *chatMessageRepository) ListChatMessagesByIDs(ctx context.Context, ids []int) ([]*chatmessage.Response, error) {
+	if len(ids) == 0 {
+		return []*chatmessage.Response{}, nil
+	}
+
+	var rows []*chatmessage.Row
+	err := r.chatMessageTable.WithContext(ctx).
+		Table("chat_messages cm").
+		Joins("JOIN users u ON u.id = cm.user_id_sender").
+		Select(chatMessageColumns).
+		Where("cm.id IN ?", ids).
+		Scopes(xsoftdelete.Scope("cm.is_deleted", false)).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("List chat messages by ids failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	res := make([]*chatmessage.Response, 0, len(rows))
+	found := make([]int, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, r.ToResponse())
+		found = append(found, r.ID)
+	}
+
+	if err := r.attachThreadInfo(ctx, res, found); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ListTextMessagesAfterID returns up to limit text messages that are not
+// deleted with an id above afterID, in id order. It feeds the search index.
+func (r *chatMessageRepository) ListTextMessagesAfterID(ctx context.Context, afterID int, limit int) ([]*chatmessage.ChatMessage, error) {
+	var msgs []*chatmessage.ChatMessage
+	err := r.chatMessageTable.WithContext(ctx).
+		Where("id > ? AND message_type = ?", afterID, consts.ChatMessageTypeText).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Order("id ASC").
+		Limit(limit).
+		Find(&msgs).Error
+	if err != nil {
+		r.logger.Error("List chat messages to index failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return msgs, nil
+}
+
+// ListUnreadPreview returns the latest limit text messages of others in the
+// group with an id above afterID, oldest first. It feeds the chat digest.
+func (r *chatMessageRepository) ListUnreadPreview(ctx context.Context, chatGroupID int, userID int, afterID int64, limit int) ([]*chatmessage.Row, error) {
+	var rows []*chatmessage.Row
+	err := r.chatMessageTable.WithContext(ctx).
+		Table("chat_messages cm").
+		Joins("JOIN users u ON u.id = cm.user_id_sender").
+		Select(chatMessageColumns).
+		Where("cm.chat_group_id = ? AND cm.id > ? AND cm.user_id_sender <> ?", chatGroupID, afterID, userID).
+		Where("cm.message_type = ?", consts.ChatMessageTypeText).
+		Scopes(xsoftdelete.Scope("cm.is_deleted", false)).
+		Order("cm.id DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("List unread chat messages failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+
+	return rows, nil
 }
 
 func (r *chatMessageRepository) CreateChatMessage(ctx context.Context, msg *chatmessage.ChatMessage) (*chatmessage.Row, error) {
@@ -89,31 +213,337 @@ func (r *chatMessageRepository) CreateChatMessage(ctx context.Context, msg *chat
 	msg.CreatedAt = now
 	msg.UpdatedAt = now
 
-	if err := r.chatMessageTable.WithContext(ctx).Create(msg).Error; err != nil {
+	// Bumping last_seq locks the group row, so concurrent messages of the
+	// group get consecutive sequence numbers.
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec("UPDATE chat_groups SET last_seq = last_seq + 1 WHERE id = ?", msg.ChatGroupID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Table("chat_groups").Select("last_seq").Where("id = ?", msg.ChatGroupID).Scan(&msg.Seq).Error; err != nil {
+			return err
+		}
+
+		return tx.Table("chat_messages").Create(msg).Error
+	})
+	if err != nil {
 		r.logger.Error("CreateChatMessage failed", xlogger.Error(err))
 		return nil, err
 	}
 
+	return r.GetChatMessageRow(ctx, msg.ID)
+}
+
+func (r *chatMessageRepository) GetChatMessageRow(ctx context.Context, id int) (*chatmessage.Row, error) {
 	var row chatmessage.Row
 	err := r.chatMessageTable.WithContext(ctx).
 		Table("chat_messages cm").
 		Joins("JOIN users u ON u.id = cm.user_id_sender").
-		Select(`
-			cm.id,
-			cm.chat_group_id,
-			cm.message_text,
-			cm.created_at,
-			u.id AS user_id,
-			u.full_name,
-			u.avatar,
-			u.role_id
-		`).
-		Where("cm.id = ?", msg.ID).
-		Scan(&row).Error
-
+		Select(chatMessageColumns).
+		Where("cm.id = ?", id).
+		Take(&row).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Get chat message failed", xlogger.Error(err))
 		return nil, err
 	}
 
 	return &row, nil
 }
+
+func (r *chatMessageRepository) GetChatMessageByID(ctx context.Context, id int) (*chatmessage.ChatMessage, error) {
+	var msg chatmessage.ChatMessage
+	err := r.chatMessageTable.WithContext(ctx).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Where("id = ?", id).
+		First(&msg).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Get chat message by id failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+// EditChatMessage keeps the current text in the edit history and replaces it.
+func (r *chatMessageRepository) EditChatMessage(ctx context.Context, msg *chatmessage.ChatMessage, text string, editorID int) error {
+	now := xutils.GetTimeNow()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		edit := &chatmessage.Edit{
+			ChatMessageID: msg.ID,
+			MessageText:   msg.MessageText,
+			EditedBy:      editorID,
+			CreatedAt:     now,
+		}
+		if err := tx.Create(edit).Error; err != nil {
+			return err
+		}
+
+		return tx.Table("chat_messages").
+			Where("id = ?", msg.ID).
+			Updates(map[string]interface{}{
+				"message_text": text,
+				"edited_at":    now,
+				"updated_at":   now,
+			}).Error
+	})
+	if err != nil {
+		r.logger.Error("Edit chat message failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *chatMessageRepository) ListChatMessageEdits(ctx context.Context, id int) ([]*chatmessage.Edit, error) {
+	var edits []*chatmessage.Edit
+	err := r.db.WithContext(ctx).
+		Where("chat_message_id = ?", id).
+		Order("id ASC").
+		Find(&edits).Error
+	if err != nil {
+		r.logger.Error("List chat message edits failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return edits, nil
+}
+
+func (r *chatMessageRepository) DeleteChatMessage(ctx context.Context, id int) (bool, error) {
+	deleted, err := r.softDelete.Delete(ctx, id)
+	if err != nil {
+		r.logger.Error("Delete chat message failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return deleted, nil
+}
+
+// ToggleReaction adds the reaction, or removes it when the user already
+// reacted with that emoji. It reports whether the reaction was added.
+func (r *chatMessageRepository) ToggleReaction(ctx context.Context, messageID int, userID int, emoji string) (bool, error) {
+	added := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("chat_message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+			Delete(&chatmessage.Reaction{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			return nil
+		}
+
+		added = true
+		return tx.Create(&chatmessage.Reaction{
+			ChatMessageID: messageID,
+			UserID:        userID,
+			Emoji:         emoji,
+			CreatedAt:     xutils.GetTimeNow(),
+		}).Error
+	})
+	if err != nil {
+		r.logger.Error("Toggle chat message reaction failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return added, nil
+}
+
+func (r *chatMessageRepository) ListReactionCounts(ctx context.Context, messageIDs []int) (map[int][]chatmessage.ReactionCount, error) {
+	var rows []struct {
+		ChatMessageID int
+		Emoji         string
+		Count         int64
+	}
+
+	res := make(map[int][]chatmessage.ReactionCount, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return res, nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Table("chat_message_reactions").
+		Select("chat_message_id, emoji, COUNT(*) AS count").
+		Where("chat_message_id IN ?", messageIDs).
+		Group("chat_message_id, emoji").
+		Order("MIN(id) ASC").
+		Scan(&rows).Error
+	if err != nil {
+		r.logger.Error("List chat message reactions failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	for _, row := range rows {
+		res[row.ChatMessageID] = append(res[row.ChatMessageID], chatmessage.ReactionCount{
+			Emoji: row.Emoji,
+			Count: row.Count,
+		})
+	}
+	return res, nil
+}
+
+func (r *chatMessageRepository) CreateAttachment(ctx context.Context, attachment *chatmessage.Attachment) error {
+	now := xutils.GetTimeNow()
+	attachment.CreatedAt = now
+	attachment.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(attachment).Error; err != nil {
+		r.logger.Error("Create chat attachment failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *chatMessageRepository) GetAttachment(ctx context.Context, id int) (*chatmessage.Attachment, error) {
+	var attachment chatmessage.Attachment
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&attachment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Get chat attachment failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// UpdateAttachmentStored records where the file was stored and marks the
+// attachment ready.
+func (r *chatMessageRepository) UpdateAttachmentStored(ctx context.Context, id int, file *service.StoredFile) error {
+	err := r.db.WithContext(ctx).
+		Model(&chatmessage.Attachment{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":        consts.ChatAttachmentStatusReady,
+			"storage_key":   file.Key,
+			"resource_type": file.ResourceType,
+			"format":        file.Format,
+			"width":         file.Width,
+			"height":        file.Height,
+			"updated_at":    xutils.GetTimeNow(),
+		}).Error
+	if err != nil {
+		r.logger.Error("Update chat attachment failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// LinkAttachments links unlinked uploads of the uploader in the group to the
+// message. It reports false, linking nothing, when any id does not qualify.
+func (r *chatMessageRepository) LinkAttachments(ctx context.Context, messageID int, groupID int, uploaderID int, ids []int) (bool, error) {
+	linked := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&chatmessage.Attachment{}).
+			Where("id IN ? AND chat_group_id = ? AND uploaded_by = ? AND chat_message_id IS NULL", ids, groupID, uploaderID).
+			Updates(map[string]interface{}{
+				"chat_message_id": messageID,
+				"updated_at":      xutils.GetTimeNow(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != int64(len(ids)) {
+			return errAttachmentsNotLinkable
+		}
+
+		linked = true
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAttachmentsNotLinkable) {
+		r.logger.Error("Link chat attachments failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return linked, nil
+}
+
+func (r *chatMessageRepository) ListAttachments(ctx context.Context, messageIDs []int) (map[int][]*chatmessage.Attachment, error) {
+	res := make(map[int][]*chatmessage.Attachment, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return res, nil
+	}
+
+	var attachments []*chatmessage.Attachment
+	err := r.db.WithContext(ctx).
+		Where("chat_message_id IN ?", messageIDs).
+		Order("id ASC").
+		Find(&attachments).Error
+	if err != nil {
+		r.logger.Error("List chat attachments failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		res[*attachment.ChatMessageID] = append(res[*attachment.ChatMessageID], attachment)
+	}
+	return res, nil
+}
+
+// attachThreadInfo fills the reaction and reply counts and the attachments
+// of the messages.
+func (r *chatMessageRepository) attachThreadInfo(ctx context.Context, res []*chatmessage.Response, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	reactions, err := r.ListReactionCounts(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	var replies []struct {
+		ParentID int
+		Count    int64
+	}
+	err = r.chatMessageTable.WithContext(ctx).
+		Select("parent_id, COUNT(*) AS count").
+		Where("parent_id IN ?", ids).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Group("parent_id").
+		Scan(&replies).Error
+	if err != nil {
+		r.logger.Error("Count chat message replies failed", xlogger.Error(err))
+		return err
+	}
+
+	attachments, err := r.ListAttachments(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	replyCounts := make(map[int]int64, len(replies))
+	for _, reply := range replies {
+		replyCounts[reply.ParentID] = reply.Count
+	}
+
+	for _, msg := range res {
+		if counts, ok := reactions[msg.ID]; ok {
+			msg.Reactions = counts
+		}
+		msg.ReplyCount = replyCounts[msg.ID]
+		// Files of deleted messages are no longer shown.
+		if msg.IsDeleted {
+			continue
+		}
+		for _, attachment := range attachments[msg.ID] {
+			msg.Attachments = append(msg.Attachments, attachment.ToResponse())
+		}
+	}
+	return nil
+}
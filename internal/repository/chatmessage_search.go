@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/elastic/go-elasticsearch/v6"
+
+	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	xes "thomas.vn/apartment_service/pkg/es"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+const (
+	chatMessageIndex   = "chat_messages"
+	chatMessageDocType = "doc"
+)
+
+type chatMessageSearchRepository struct {
+	logger *xlogger.Logger
+	client *elasticsearch.Client
+}
+
+func NewChatMessageSearchRepository(logger *xlogger.Logger, client *elasticsearch.Client) repository.ChatMessageSearchRepository {
+	return &chatMessageSearchRepository{
+		logger: logger,
+		client: client,
+	}
+}
+
+func (r *chatMessageSearchRepository) IndexMessages(ctx context.Context, docs []*chatmessage.SearchDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	bulk := xes.NewBulkRequest()
+	for _, doc := range docs {
+		bulk.AddIndex(chatMessageIndex, chatMessageDocType, strconv.Itoa(doc.ID), doc)
+	}
+
+	if err := r.executeBulk(ctx, bulk); err != nil {
+		r.logger.Error("Index chat messages failed", xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *chatMessageSearchRepository) DeleteMessages(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	bulk := xes.NewBulkRequest()
+	for _, id := range ids {
+		bulk.AddDelete(chatMessageIndex, chatMessageDocType, strconv.Itoa(id))
+	}
+
+	if err := r.executeBulk(ctx, bulk); err != nil {
+		r.logger.Error("Delete chat messages from index failed", xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Search matches the text with and without diacritics; matches keeping the
+// diacritics of the query rank higher.
+func (r *chatMessageSearchRepository) Search(
+	ctx context.Context,
+	text string,
+	chatGroupIDs []int64,
+	from int,
+	size int,
+) ([]*chatmessage.SearchHit, int64, error) {
+	q := xes.NewQueryBuilder().Bool().
+		Should(map[string]interface{}{
+			"match": map[string]interface{}{
+				"message_text": map[string]interface{}{
+					"query": text,
+					"boost": 2,
+				},
+			},
+		}).
+		Should(xes.MatchQuery("message_text.folded", xutils.RemoveDiacritics(text))).
+		Filter(xes.TermsQuery("chat_group_id", chatGroupIDs)).
+		End().
+		Build()
+	q["bool"].(map[string]interface{})["minimum_should_match"] = 1
+
+	result, err := xes.BasicSearch(ctx, r.client, chatMessageIndex, q, &xes.SearchOptions{
+		From:           &from,
+		Size:           &size,
+		SourceIncludes: []string{"id"},
+		Highlight: map[string]interface{}{
+			// Escape the message text so fragments are safe to render as HTML.
+			"encoder":   "html",
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"fields": map[string]interface{}{
+				"message_text":        map[string]interface{}{"number_of_fragments": 3, "fragment_size": 120},
+				"message_text.folded": map[string]interface{}{"number_of_fragments": 3, "fragment_size": 120},
+			},
+		},
+	})
+	if err != nil {
+		r.logger.Error("Search chat messages failed", xlogger.Error(err))
+		return nil, 0, err
+	}
+
+	total, err := xes.SearchTotal(result)
+	if err != nil {
+		return nil, 0, err
+	}
+	hits, err := xes.SearchHits(result)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*chatmessage.SearchHit, 0, len(hits))
+	for _, hit := range hits {
+		id, _ := hit["_id"].(string)
+		messageID, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+
+		// The exact field highlights better; the folded one covers queries
+		// typed without diacritics.
+		highlights := xes.HitHighlights(hit, "message_text")
+		if len(highlights) == 0 {
+			highlights = xes.HitHighlights(hit, "message_text.folded")
+		}
+
+		res = append(res, &chatmessage.SearchHit{ID: messageID, Highlights: highlights})
+	}
+
+	return res, total, nil
+}
+
+// executeBulk runs the bulk request and fails if any item failed. Deleting
+// a document that is not indexed is not a failure.
+func (r *chatMessageSearchRepository) executeBulk(ctx context.Context, bulk *xes.BulkRequest) error {
+	result, err := xes.ExecuteBulk(ctx, r.client, bulk)
+	if err != nil {
+		return err
+	}
+	if failed, _ := result["errors"].(bool); !failed {
+		return nil
+	}
+
+	items, _ := result["items"].([]interface{})
+	for _, item := range items {
+		actions, _ := item.(map[string]interface{})
+		for action, v := range actions {
+			res, _ := v.(map[string]interface{})
+			status, _ := res["status"].(float64)
+			if status < 300 || (action == "delete" && status == 404) {
+				continue
+			}
+			return fmt.Errorf("bulk %s of document %v failed: %v", action, res["_id"], res["error"])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+type mailOutboxRepository struct {
+	logger          *xlogger.Logger
+	mailOutboxTable *gorm.DB
+}
+
+func NewMailOutboxRepository(logger *xlogger.Logger, db *gorm.DB) repository.MailOutboxRepository {
+	return &mailOutboxRepository{
+		logger:          logger,
+		mailOutboxTable: db.Table("mail_outbox"),
+	}
+}
+
+func (r *mailOutboxRepository) CreateMailOutbox(ctx context.Context, tx *gorm.DB, mail *model.MailOutbox) error {
+	now := xutils.GetTimeNow()
+	mail.NextAttemptAt = now
+	mail.CreatedAt = now
+	mail.UpdatedAt = now
+
+	db := r.mailOutboxTable.WithContext(ctx)
+	if tx != nil {
+		db = tx.WithContext(ctx).Table("mail_outbox")
+	}
+
+	result := db.Create(mail)
+	if result.Error != nil {
+		r.logger.Error("Create mail outbox failed", xlogger.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("create mail outbox failed")
+	}
+
+	return nil
+}
+
+func (r *mailOutboxRepository) GetMailOutbox(ctx context.Context, id int64) (*model.MailOutbox, error) {
+	var mail model.MailOutbox
+	result := r.mailOutboxTable.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&mail)
+	if result.Error != nil {
+		r.logger.Error("Get mail outbox failed", xlogger.Error(result.Error))
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &mail, nil
+}
+
+func (r *mailOutboxRepository) ListMailOutbox(ctx context.Context, req *model.ListMailOutboxRequest) ([]*model.MailOutbox, int64, error) {
+	var (
+		mails []*model.MailOutbox
+		total int64
+	)
+
+	query := r.mailOutboxTable.WithContext(ctx)
+
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Template != "" {
+		query = query.Where("template = ?", req.Template)
+	}
+	if req.Recipient != "" {
+		query = query.Where("recipient = ?", req.Recipient)
+	}
+	if req.FromDate != "" {
+		query = query.Where("created_at >= ?", req.FromDate+" 00:00:00")
+	}
+	if req.ToDate != "" {
+		query = query.Where("created_at <= ?", req.ToDate+" 23:59:59")
+	}
+
+	if !req.ExcludeTotal {
+		if err := query.Count(&total).Error; err != nil {
+			r.logger.Error("Count mail outbox failed", xlogger.Error(err))
+			return nil, 0, err
+		}
+	}
+
+	query = xutils.ApplyPagination(query, req.Page, req.Limit)
+
+	if err := query.Order("id DESC").Find(&mails).Error; err != nil {
+		r.logger.Error("List mail outbox failed", xlogger.Error(err))
+		return nil, 0, err
+	}
+
+	return mails, total, nil
+}
+
+func (r *mailOutboxRepository) ListDueMailOutbox(ctx context.Context, now time.Time, staleBefore time.Time, limit int) ([]*model.MailOutbox, error) {
+	var mails []*model.MailOutbox
+	err := r.mailOutboxTable.WithContext(ctx).
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND updated_at < ?)",
+			consts.MailStatusPending, now, consts.MailStatusSending, staleBefore).
+		Order("id ASC").
+		Limit(limit).
+		Find(&mails).Error
+	if err != nil {
+		r.logger.Error("List due mail outbox failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return mails, nil
+}
+
+func (r *mailOutboxRepository) ClaimMailOutbox(ctx context.Context, mail *model.MailOutbox) (bool, error) {
+	now := xutils.GetTimeNow()
+	result := r.mailOutboxTable.WithContext(ctx).
+		Where("id = ? AND status = ? AND updated_at = ?", mail.ID, mail.Status, mail.UpdatedAt).
+		Updates(map[string]interface{}{
+			"status":     consts.MailStatusSending,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		r.logger.Error("Claim mail outbox failed", xlogger.Error(result.Error))
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	mail.Status = consts.MailStatusSending
+	mail.Attempts++
+	mail.UpdatedAt = now
+	return true, nil
+}
+
+func (r *mailOutboxRepository) MarkMailSent(ctx context.Context, id int64, result *model.MailResult) error {
+	now := xutils.GetTimeNow()
+	err := r.mailOutboxTable.WithContext(ctx).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     consts.MailStatusSent,
+			"subject":    result.Subject,
+			"message_id": result.MessageID,
+			"error":      "",
+			"sent_at":    now,
+			"updated_at": now,
+		}).Error
+	if err != nil {
+		r.logger.Error("Mark mail sent failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *mailOutboxRepository) MarkMailFailed(ctx context.Context, id int64, status string, reason string, nextAttemptAt time.Time) error {
+	err := r.mailOutboxTable.WithContext(ctx).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"error":           reason,
+			"next_attempt_at": nextAttemptAt,
+			"updated_at":      xutils.GetTimeNow(),
+		}).Error
+	if err != nil {
+		r.logger.Error("Record mail failure failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *mailOutboxRepository) ResetMailOutbox(ctx context.Context, id int64) (bool, error) {
+	now := xutils.GetTimeNow()
+	result := r.mailOutboxTable.WithContext(ctx).
+		Where("id = ? AND status = ?", id, consts.MailStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          consts.MailStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		r.logger.Error("Reset mail outbox failed", xlogger.Error(result.Error))
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model/moderation"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xtenant "thomas.vn/apartment_service/pkg/tenant"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+type moderationRepository struct {
+	logger      *xlogger.Logger
+	blockTable  *gorm.DB
+	reportTable *gorm.DB
+}
+
+func NewModerationRepository(logger *xlogger.Logger, db *gorm.DB) repository.ModerationRepository {
+	return &moderationRepository{
+		logger:      logger,
+		blockTable:  db.Table("user_blocks"),
+		reportTable: db.Table("chat_message_reports"),
+	}
+}
+
+// BlockUser records the block. Blocking a user twice is a no-op.
+func (r *moderationRepository) BlockUser(ctx context.Context, blockerID int, blockedID int) error {
+	block := &moderation.Block{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: xutils.GetTimeNow(),
+	}
+
+	err := r.blockTable.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(block).Error
+	if err != nil {
+		r.logger.Error("Block user failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *moderationRepository) UnblockUser(ctx context.Context, blockerID int, blockedID int) (bool, error) {
+	res := r.blockTable.WithContext(ctx).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&moderation.Block{})
+	if res.Error != nil {
+		r.logger.Error("Unblock user failed", xlogger.Error(res.Error))
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (r *moderationRepository) ListBlockedUsers(ctx context.Context, blockerID int) ([]*moderation.BlockedUser, error) {
+	var users []*moderation.BlockedUser
+	err := r.blockTable.WithContext(ctx).
+		Table("user_blocks ub").
+		Joins("JOIN users u ON u.id = ub.blocked_id").
+		Select("u.id AS user_id, u.full_name, u.avatar, ub.created_at AS blocked_at").
+		Where("ub.blocker_id = ?", blockerID).
+		Order("ub.id DESC").
+		Scan(&users).Error
+	if err != nil {
+		r.logger.Error("List blocked users failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// IsBlocked reports whether either user blocked the other.
+func (r *moderationRepository) IsBlocked(ctx context.Context, userID int, otherID int) (bool, error) {
+	var count int64
+	err := r.blockTable.WithContext(ctx).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Check user block failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// CreateReport stores the report. It reports false when the reporter
+// already reported the message.
+func (r *moderationRepository) CreateReport(ctx context.Context, report *moderation.Report) (bool, error) {
+	now := xutils.GetTimeNow()
+	report.Status = consts.ChatReportStatusPending
+	report.CreatedAt = now
+	report.UpdatedAt = now
+
+	res := r.reportTable.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(report)
+	if res.Error != nil {
+		r.logger.Error("Create chat message report failed", xlogger.Error(res.Error))
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (r *moderationRepository) GetReport(ctx context.Context, id int64) (*moderation.Report, error) {
+	var report moderation.Report
+	err := r.reportTable.WithContext(ctx).
+		Where("id = ?", id).
+		First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.Error("Get chat message report failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// ListReports returns the reports with the reported message, oldest first so
+// that moderators work through the queue in order. Only reports from groups
+// in the caller's buildings are listed.
+func (r *moderationRepository) ListReports(ctx context.Context, req *moderation.ListReportRequest) ([]*moderation.ReportResponse, int64, error) {
+	var (
+		reports []*moderation.ReportResponse
+		total   int64
+	)
+
+	query := r.reportTable.WithContext(ctx).
+		Table("chat_message_reports cr").
+		Joins("JOIN chat_messages cm ON cm.id = cr.chat_message_id").
+		Joins("JOIN users ru ON ru.id = cr.reporter_id").
+		Joins("JOIN users su ON su.id = cm.user_id_sender").
+		Joins("JOIN chat_groups cg ON cg.id = cr.chat_group_id").
+		Scopes(xtenant.Filter(ctx, "cg.building_id"))
+
+	if req.Status != "" {
+		query = query.Where("cr.status = ?", req.Status)
+	}
+
+	if !req.ExcludeTotal {
+		if err := query.Count(&total).Error; err != nil {
+			r.logger.Error("Count chat message reports failed", xlogger.Error(err))
+			return nil, 0, err
+		}
+	}
+
+	query = xutils.ApplyPagination(query, req.Page, req.Limit)
+
+	err := query.
+		Select(`
+			cr.id,
+			cr.chat_message_id,
+			cr.chat_group_id,
+			cr.reporter_id,
+			ru.full_name AS reporter_name,
+			cr.reason,
+			cr.status,
+			cr.reviewed_by,
+			cr.reviewed_at,
+			cr.created_at,
+			cm.message_text,
+			cm.user_id_sender AS message_sender_id,
+			su.full_name AS message_sender,
+			cm.is_deleted AS message_deleted,
+			cm.created_at AS message_created_at`).
+		Order("cr.id ASC").
+		Scan(&reports).Error
+	if err != nil {
+		r.logger.Error("List chat message reports failed", xlogger.Error(err))
+		return nil, 0, err
+	}
+
+	return reports, total, nil
+}
+
+// ResolveReports closes every pending report of the message with the status.
+// It returns the number of reports closed.
+func (r *moderationRepository) ResolveReports(ctx context.Context, chatMessageID int, status string, reviewerID int) (int64, error) {
+	now := xutils.GetTimeNow()
+	res := r.reportTable.WithContext(ctx).
+		Where("chat_message_id = ? AND status = ?", chatMessageID, consts.ChatReportStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewerID,
+			"reviewed_at": now,
+			"updated_at":  now,
+		})
+	if res.Error != nil {
+		r.logger.Error("Resolve chat message reports failed", xlogger.Error(res.Error))
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+type notificationRepository struct {
+	logger            *xlogger.Logger
+	notificationTable *gorm.DB
+}
+
+func NewNotificationRepository(logger *xlogger.Logger, db *gorm.DB) repository.NotificationRepository {
+	return &notificationRepository{
+		logger:            logger,
+		notificationTable: db.Table("notifications"),
+	}
+}
+
+func (r *notificationRepository) CreateNotification(ctx context.Context, notification *model.Notification) error {
+	notification.CreatedAt = xutils.GetTimeNow()
+
+	result := r.notificationTable.WithContext(ctx).Create(notification)
+	if result.Error != nil {
+		r.logger.Error("Create notification failed", xlogger.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("create notification failed")
+	}
+
+	return nil
+}
+
+// ListNotifications returns the user's notifications, newest first.
+func (r *notificationRepository) ListNotifications(ctx context.Context, userID int, req *model.ListNotificationRequest) ([]*model.Notification, int64, error) {
+	var (
+		notifications []*model.Notification
+		total         int64
+	)
+
+	query := r.notificationTable.WithContext(ctx).
+		Where("user_id = ?", userID)
+	if req.Unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if !req.ExcludeTotal {
+		if err := query.Count(&total).Error; err != nil {
+			r.logger.Error("Count notifications failed", xlogger.Error(err))
+			return nil, 0, err
+		}
+	}
+
+	query = xutils.ApplyPagination(query, req.Page, req.Limit)
+
+	if err := query.Order("id DESC").Find(&notifications).Error; err != nil {
+		r.logger.Error("List notifications failed", xlogger.Error(err))
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID int) (int64, error) {
+	var count int64
+	err := r.notificationTable.WithContext(ctx).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Count unread notifications failed", xlogger.Error(err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// MarkRead marks the user's notification read. It reports false when the
+// notification does not exist or belongs to another user; marking a read
+// notification again keeps its first read time.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID int, id int64) (bool, error) {
+	var count int64
+	err := r.notificationTable.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Get notification failed", xlogger.Error(err))
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	err = r.notificationTable.WithContext(ctx).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", xutils.GetTimeNow()).Error
+	if err != nil {
+		r.logger.Error("Mark notification read failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return true, nil
+}
+
+// MarkAllRead marks every unread notification of the user read. It returns
+// the number of notifications marked.
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	res := r.notificationTable.WithContext(ctx).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", xutils.GetTimeNow())
+	if res.Error != nil {
+		r.logger.Error("Mark all notifications read failed", xlogger.Error(res.Error))
+		return 0, res.Error
+	}
+
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+type notificationPreferenceRepository struct {
+	logger          *xlogger.Logger
+	preferenceTable *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(logger *xlogger.Logger, db *gorm.DB) repository.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{
+		logger:          logger,
+		preferenceTable: db.Table("notification_preferences"),
+	}
+}
+
+func (r *notificationPreferenceRepository) ListPreferences(ctx context.Context, userID int) ([]*model.NotificationPreference, error) {
+	var prefs []*model.NotificationPreference
+	err := r.preferenceTable.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Find(&prefs).Error
+	if err != nil {
+		r.logger.Error("List notification preferences failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+func (r *notificationPreferenceRepository) SavePreferences(ctx context.Context, prefs []*model.NotificationPreference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+
+	now := xutils.GetTimeNow()
+	for _, p := range prefs {
+		p.UpdatedAt = now
+	}
+
+	err := r.preferenceTable.WithContext(ctx).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"})}).
+		Create(prefs).Error
+	if err != nil {
+		r.logger.Error("Save notification preferences failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *notificationPreferenceRepository) IsEnabled(ctx context.Context, userID int, category string, channel string) (bool, error) {
+	var prefs []*model.NotificationPreference
+	err := r.preferenceTable.WithContext(ctx).
+		Where("user_id = ? AND category = ? AND channel = ?", userID, category, channel).
+		Limit(1).
+		Find(&prefs).Error
+	if err != nil {
+		r.logger.Error("Get notification preference failed", xlogger.Error(err))
+		return false, err
+	}
+	if len(prefs) == 0 {
+		return true, nil
+	}
+
+	return prefs[0].Enabled, nil
+}
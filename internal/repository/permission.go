@@ -6,8 +6,10 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xtenant "thomas.vn/apartment_service/pkg/tenant"
 	xutils "thomas.vn/apartment_service/pkg/utils"
 )
 
@@ -87,3 +89,125 @@ func (r *PermissionRepository) GetPermissionByID(ctx context.Context, permission
 	}
 	return &permission, nil
 }
+
+// ResolvePermissionScope returns the buildings in which the user is granted
+// the requested endpoint. The user's base role (users.role_id) and role
+// assignments without a building grant a global scope; building-scoped
+// assignments only grant their own building.
+func (r *PermissionRepository) ResolvePermissionScope(ctx context.Context, request model.CheckPermissionRequest) (*xtenant.Scope, error) {
+	global, err := r.HasPermission(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if global {
+		return &xtenant.Scope{Global: true}, nil
+	}
+
+	var buildingIDs []int64
+	query := r.db.WithContext(ctx).
+		Table("user_roles ur").
+		Joins("JOIN role_permission rp ON rp.role_id = ur.role_id AND rp.is_active = 1").
+		Joins("JOIN permissions p ON p.id = rp.permission_id").
+		Where(`
+			ur.user_id = ?
+			AND ur.is_deleted = 0
+			AND p.method = ?
+			AND p.endpoint = ?
+		`, request.UserID, request.Method, request.Endpoint)
+
+	if request.BuildingID != consts.GlobalBuildingID {
+		query = query.Where("ur.building_id IN ?", []int64{consts.GlobalBuildingID, request.BuildingID})
+	}
+
+	if err := query.Distinct().Pluck("ur.building_id", &buildingIDs).Error; err != nil {
+		r.logger.Error("Resolve permission scope failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	scope := &xtenant.Scope{BuildingIDs: make([]int64, 0, len(buildingIDs))}
+	for _, id := range buildingIDs {
+		if id == consts.GlobalBuildingID {
+			return &xtenant.Scope{Global: true}, nil
+		}
+		scope.BuildingIDs = append(scope.BuildingIDs, id)
+	}
+
+	return scope, nil
+}
+
+// CountMissingPermissions counts the permissions of the target role that the
+// caller does not hold through their base role or a role assignment covering
+// the building.
+func (r *PermissionRepository) CountMissingPermissions(ctx context.Context, request model.RoleGrantRequest) (int64, error) {
+	callerRoles := r.db.
+		Table("user_roles").
+		Select("role_id").
+		Where("user_id = ? AND is_deleted = 0 AND building_id IN ?",
+			request.UserID, []int64{consts.GlobalBuildingID, request.BuildingID})
+
+	held := r.db.
+		Table("role_permission").
+		Select("permission_id").
+		Where("is_active = 1 AND (role_id = ? OR role_id IN (?))", request.RoleID, callerRoles)
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("role_permission").
+		Where("role_id = ? AND is_active = 1 AND permission_id NOT IN (?)", request.TargetRoleID, held).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Count missing permissions failed", xlogger.Error(err))
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PermissionRepository) AssignRole(ctx context.Context, userRole *model.UserRole) (*model.UserRole, error) {
+	userRole.CreatedAt = xutils.GetTimeNow()
+	userRole.UpdatedAt = xutils.GetTimeNow()
+
+	result := r.db.WithContext(ctx).Create(userRole)
+	if result.Error != nil {
+		r.logger.Error("Assign role failed", xlogger.Error(result.Error))
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("Assign role failed, no rows affected")
+	}
+	return userRole, nil
+}
+
+func (r *PermissionRepository) GetUserRoleByID(ctx context.Context, id uint) (*model.UserRole, error) {
+	var userRole model.UserRole
+	result := r.db.WithContext(ctx).Where("id = ? AND is_deleted = 0", id).First(&userRole)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		r.logger.Error("Get user role failed", xlogger.Error(result.Error))
+		return nil, result.Error
+	}
+	return &userRole, nil
+}
+
+func (r *PermissionRepository) RevokeRole(ctx context.Context, id uint, deletedBy int) error {
+	now := xutils.GetTimeNow()
+	result := r.db.WithContext(ctx).
+		Model(&model.UserRole{}).
+		Where("id = ? AND is_deleted = 0", id).
+		Updates(map[string]interface{}{
+			"is_deleted": 1,
+			"deleted_by": deletedBy,
+			"deleted_at": now,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		r.logger.Error("Revoke role failed", xlogger.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Revoke role failed, no rows affected")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type policyRepository struct {
+	logger *xlogger.Logger
+	db     *gorm.DB
+}
+
+func NewPolicyRepository(logger *xlogger.Logger, db *gorm.DB) repository.PolicyRepository {
+	return &policyRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *policyRepository) IsArticleOwner(ctx context.Context, userID int, articleID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("articles").
+		Where("id = ? AND user_id = ?", articleID, userID).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Check article owner failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *policyRepository) IsChatGroupMember(ctx context.Context, userID int, chatGroupID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("chat_group_members").
+		Where("chat_group_id = ? AND user_id = ? AND is_deleted = 0", chatGroupID, userID).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Check chat group member failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xsoftdelete "thomas.vn/apartment_service/pkg/softdelete"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+// anonymizedEmailPattern matches the emails given to anonymised users, so
+// they are not processed twice.
+const anonymizedEmailPattern = "deleted-%@invalid"
+
+type retentionRepository struct {
+	logger *xlogger.Logger
+	db     *gorm.DB
+}
+
+func NewRetentionRepository(logger *xlogger.Logger, db *gorm.DB) repository.RetentionRepository {
+	return &retentionRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *retentionRepository) PurgeDeleted(ctx context.Context, table string, before time.Time) (int64, error) {
+	purged, err := xsoftdelete.New(r.db, table).Purge(ctx, before)
+	if err != nil {
+		r.logger.Error("Purge deleted rows failed", xlogger.String("table", table), xlogger.Error(err))
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func (r *retentionRepository) PurgeDeletedMessages(ctx context.Context, before time.Time) (int64, []*chatmessage.Attachment, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).
+		Table("chat_messages").
+		Where("is_deleted = ? AND deleted_at < ?", xsoftdelete.Deleted, before).
+		Pluck("id", &ids).Error
+	if err != nil {
+		r.logger.Error("List deleted chat messages failed", xlogger.Error(err))
+		return 0, nil, err
+	}
+	if len(ids) == 0 {
+		return 0, nil, nil
+	}
+
+	var (
+		attachments []*chatmessage.Attachment
+		purged      int64
+	)
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("chat_message_id IN ?", ids).Find(&attachments).Error; err != nil {
+			return err
+		}
+		for _, table := range []string{
+			"chat_message_attachments",
+			"chat_message_edits",
+			"chat_message_reactions",
+			"chat_message_reports",
+		} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE chat_message_id IN ?", ids).Error; err != nil {
+				return err
+			}
+		}
+		// Replies outlive their parent as plain messages.
+		if err := tx.Exec("UPDATE chat_messages SET parent_id = NULL WHERE parent_id IN ?", ids).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec("DELETE FROM chat_messages WHERE id IN ?", ids)
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		r.logger.Error("Purge deleted chat messages failed", xlogger.Error(err))
+		return 0, nil, err
+	}
+
+	return purged, attachments, nil
+}
+
+func (r *retentionRepository) AnonymizeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	var users []struct {
+		ID    int
+		Email string
+	}
+	err := r.db.WithContext(ctx).
+		Table("users").
+		Select("id, email").
+		Where("is_deleted = ? AND deleted_at < ? AND email NOT LIKE ?", xsoftdelete.Deleted, before, anonymizedEmailPattern).
+		Find(&users).Error
+	if err != nil {
+		r.logger.Error("List deleted users failed", xlogger.Error(err))
+		return 0, err
+	}
+	if len(users) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int, 0, len(users))
+	emails := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+		emails = append(emails, user.Email)
+	}
+
+	var anonymized int64
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Personal rows nothing else refers to are removed outright.
+		for _, table := range []string{"notifications", "notification_preferences", "user_roles"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE user_id IN ?", ids).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Exec("DELETE FROM user_blocks WHERE blocker_id IN ? OR blocked_id IN ?", ids, ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM mail_outbox WHERE recipient IN ?", emails).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			UPDATE users
+			SET email = CONCAT('deleted-', id, '@invalid'),
+				full_name = 'Deleted user',
+				avatar = '',
+				password = '',
+				facebook_id = NULL,
+				google_id = NULL,
+				totp_secret = NULL,
+				updated_at = ?
+			WHERE id IN ?
+		`, xutils.GetTimeNow(), ids)
+		anonymized = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		r.logger.Error("Anonymize deleted users failed", xlogger.Error(err))
+		return 0, err
+	}
+
+	return anonymized, nil
+}
@@ -6,30 +6,48 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/domain/consts"
 	xuser "thomas.vn/apartment_service/internal/domain/model/user"
 
 	"thomas.vn/apartment_service/internal/domain/repository"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	"thomas.vn/apartment_service/pkg/query"
+	xsoftdelete "thomas.vn/apartment_service/pkg/softdelete"
+	xtenant "thomas.vn/apartment_service/pkg/tenant"
 	xutils "thomas.vn/apartment_service/pkg/utils"
 )
 
 type userRepository struct {
-	logger    *xlogger.Logger
-	userTable *gorm.DB
+	logger        *xlogger.Logger
+	userTable     *gorm.DB
+	userRoleTable *gorm.DB
+	softDelete    *xsoftdelete.Table
 }
 
 func NewUserRepository(logger *xlogger.Logger, db *gorm.DB) repository.UserRepository {
 	return &userRepository{
-		logger:    logger,
-		userTable: db.Table("users"),
+		logger:        logger,
+		userTable:     db.Table("users"),
+		userRoleTable: db.Table("user_roles"),
+		softDelete:    xsoftdelete.New(db, "users"),
 	}
 }
 
 func (r *userRepository) CreateUser(ctx context.Context, user *xuser.User) (*xuser.User, error) {
+	return r.create(r.userTable.WithContext(ctx), user)
+}
+
+// CreateUserTx creates the user within tx, so it is rolled back together
+// with the rest of the transaction.
+func (r *userRepository) CreateUserTx(ctx context.Context, tx *gorm.DB, user *xuser.User) (*xuser.User, error) {
+	return r.create(tx.WithContext(ctx).Table("users"), user)
+}
+
+func (r *userRepository) create(db *gorm.DB, user *xuser.User) (*xuser.User, error) {
 	user.CreatedAt = xutils.GetTimeNow()
 	user.UpdatedAt = xutils.GetTimeNow()
 
-	result := r.userTable.WithContext(ctx).Create(user)
+	result := db.Create(user)
 	if result.Error != nil {
 		r.logger.Error("Create user failed", xlogger.Error(result.Error))
 		return nil, result.Error
@@ -43,7 +61,10 @@ func (r *userRepository) CreateUser(ctx context.Context, user *xuser.User) (*xus
 
 func (r *userRepository) GetUserByID(ctx context.Context, id uint) (*xuser.User, error) {
 	var user xuser.User
-	result := r.userTable.WithContext(ctx).Where("id = ?", id).First(&user)
+	result := r.userTable.WithContext(ctx).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Where("id = ?", id).
+		First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -57,7 +78,10 @@ func (r *userRepository) GetUserByID(ctx context.Context, id uint) (*xuser.User,
 
 func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*xuser.User, error) {
 	var user xuser.User
-	result := r.userTable.WithContext(ctx).Where("email = ?", email).First(&user)
+	result := r.userTable.WithContext(ctx).
+		Scopes(xsoftdelete.Scope("is_deleted", false)).
+		Where("email = ?", email).
+		First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -69,6 +93,19 @@ func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*xus
 	return &user, nil
 }
 
+func (r *userRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.userTable.WithContext(ctx).
+		Where("email = ?", email).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("Check user email failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 func (r *userRepository) UpdateUser(ctx context.Context, user *xuser.User) (*xuser.User, error) {
 	user.UpdatedAt = xutils.GetTimeNow()
 
@@ -85,57 +122,56 @@ func (r *userRepository) UpdateUser(ctx context.Context, user *xuser.User) (*xus
 }
 
 func (r *userRepository) DeleteUser(ctx context.Context, id uint) error {
-	result := r.userTable.WithContext(ctx).Where("id = ?", id).Delete(&xuser.User{})
-	if result.Error != nil {
-		r.logger.Error("Delete user failed", xlogger.Error(result.Error))
-		return result.Error
+	deleted, err := r.softDelete.Delete(ctx, id)
+	if err != nil {
+		r.logger.Error("Delete user failed", xlogger.Error(err))
+		return err
 	}
-	if result.RowsAffected == 0 {
+	if !deleted {
 		return fmt.Errorf("delete user failed")
 	}
 
 	return nil
 }
 
-func (r *userRepository) ListUsers(ctx context.Context, req *xuser.ListUserRequest, filters *xuser.UserFilters) ([]*xuser.User, int64, error) {
+func (r *userRepository) RestoreUser(ctx context.Context, id uint) (bool, error) {
+	restored, err := r.softDelete.Restore(ctx, id)
+	if err != nil {
+		r.logger.Error("Restore user failed", xlogger.Error(err))
+		return false, err
+	}
+
+	return restored, nil
+}
+
+func (r *userRepository) ListUsers(ctx context.Context, req *xuser.ListUserRequest, spec *query.Spec) ([]*xuser.User, int64, error) {
 	var users []*xuser.User
 	var total int64
 
-	query := r.userTable.WithContext(ctx)
-
-	if req.IsDeleted != 0 {
-		query = query.Where("is_deleted = ?", req.IsDeleted)
-	}
-	if filters.FullName != "" {
-		query = query.Where("full_name LIKE ?", "%"+filters.FullName+"%")
-	}
-	if req.FromDate != "" {
-		query = query.Where(req.RangeBy+" >= ?", req.FromDate+" 00:00:00")
-	}
-	if req.ToDate != "" {
-		query = query.Where(req.RangeBy+" <= ?", req.ToDate+" 23:59:59")
-	}
+	db := r.userTable.WithContext(ctx).Scopes(
+		xtenant.FilterBy(ctx, func(db *gorm.DB, buildingIDs []int64) *gorm.DB {
+			members := r.userRoleTable.WithContext(ctx).
+				Select("user_id").
+				Where("building_id IN ? AND is_deleted = 0", buildingIDs)
+			return db.Where("id IN (?)", members)
+		}),
+		xsoftdelete.Scope("is_deleted", req.IsDeleted == xsoftdelete.Deleted),
+		spec.Filter(),
+	)
 
 	// Get total count if not exclude
 	if !req.ExcludeTotal {
-		if err := query.Count(&total).Error; err != nil {
+		if err := db.Count(&total).Error; err != nil {
 			r.logger.Error("Count users failed", xlogger.Error(err))
 			return nil, 0, err
 		}
 	}
 
-	// Apply pagination
-	if req.Page > 0 && req.Limit > 0 {
-		query = query.Offset((req.Page - 1) * req.Limit).Limit(req.Limit)
-	}
-
-	// Apply sorting
-	if req.SortBy != "" && req.OrderBy != "" {
-		query = query.Order(req.SortBy + " " + req.OrderBy)
-	}
+	// Apply pagination and sorting
+	db = db.Scopes(spec.Paginate(req.PaginationOptions), spec.Sorting())
 
 	// Execute query
-	if err := query.Find(&users).Error; err != nil {
+	if err := db.Find(&users).Error; err != nil {
 		r.logger.Error("List users failed", xlogger.Error(err))
 		return nil, 0, err
 	}
@@ -168,3 +204,17 @@ func (r *userRepository) UpdateTotpSecret(ctx context.Context, userID int64, sec
 
 	return nil
 }
+
+func (r *userRepository) ListBuildingIDs(ctx context.Context, userID int64) ([]int64, error) {
+	var buildingIDs []int64
+	err := r.userRoleTable.WithContext(ctx).
+		Where("user_id = ? AND building_id <> ? AND is_deleted = 0", userID, consts.GlobalBuildingID).
+		Distinct().
+		Pluck("building_id", &buildingIDs).Error
+	if err != nil {
+		r.logger.Error("List user buildings failed", xlogger.Error(err))
+		return nil, err
+	}
+
+	return buildingIDs, nil
+}
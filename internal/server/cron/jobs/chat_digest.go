@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/config"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+// ChatDigestJob mails offline members a digest of their unread chat
+// messages.
+type ChatDigestJob struct {
+	logger       *xlogger.Logger
+	cfg          config.ChatDigestConfig
+	chatDigestUC usecase.ChatDigestUsecase
+}
+
+func NewChatDigestJob(
+	logger *xlogger.Logger,
+	cfg config.ChatDigestConfig,
+	chatDigestUC usecase.ChatDigestUsecase,
+) *ChatDigestJob {
+	return &ChatDigestJob{
+		logger:       logger,
+		cfg:          cfg,
+		chatDigestUC: chatDigestUC,
+	}
+}
+
+func (j *ChatDigestJob) Name() string {
+	return "chat_digest_job"
+}
+
+func (j *ChatDigestJob) Schedule() string {
+	return j.cfg.Schedule
+}
+
+func (j *ChatDigestJob) Enabled() bool {
+	return j.cfg.Enabled
+}
+
+func (j *ChatDigestJob) Execute(ctx context.Context) error {
+	if err := j.chatDigestUC.SendDigests(ctx); err != nil {
+		j.logger.Error("send chat digests failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/config"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+// MailOutboxJob delivers the mail waiting in the outbox.
+type MailOutboxJob struct {
+	logger       *xlogger.Logger
+	cfg          config.MailOutboxConfig
+	mailOutboxUC usecase.MailOutboxUsecase
+}
+
+func NewMailOutboxJob(
+	logger *xlogger.Logger,
+	cfg config.MailOutboxConfig,
+	mailOutboxUC usecase.MailOutboxUsecase,
+) *MailOutboxJob {
+	return &MailOutboxJob{
+		logger:       logger,
+		cfg:          cfg,
+		mailOutboxUC: mailOutboxUC,
+	}
+}
+
+func (j *MailOutboxJob) Name() string {
+	return "mail_outbox_job"
+}
+
+func (j *MailOutboxJob) Schedule() string {
+	return j.cfg.Schedule
+}
+
+func (j *MailOutboxJob) Enabled() bool {
+	return j.cfg.Enabled
+}
+
+func (j *MailOutboxJob) Execute(ctx context.Context) error {
+	if err := j.mailOutboxUC.DeliverDue(ctx); err != nil {
+		j.logger.Error("deliver mail outbox failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
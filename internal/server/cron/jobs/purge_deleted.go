@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/config"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+// PurgeDeletedJob permanently removes rows that stayed soft deleted longer
+// than the configured retention period.
+type PurgeDeletedJob struct {
+	logger      *xlogger.Logger
+	cfg         config.RetentionConfig
+	retentionUC usecase.RetentionUsecase
+}
+
+func NewPurgeDeletedJob(
+	logger *xlogger.Logger,
+	cfg config.RetentionConfig,
+	retentionUC usecase.RetentionUsecase,
+) *PurgeDeletedJob {
+	return &PurgeDeletedJob{
+		logger:      logger,
+		cfg:         cfg,
+		retentionUC: retentionUC,
+	}
+}
+
+func (j *PurgeDeletedJob) Name() string {
+	return "purge_deleted_job"
+}
+
+func (j *PurgeDeletedJob) Schedule() string {
+	return j.cfg.Schedule
+}
+
+func (j *PurgeDeletedJob) Enabled() bool {
+	return j.cfg.Enabled
+}
+
+func (j *PurgeDeletedJob) Execute(ctx context.Context) error {
+	if err := j.retentionUC.PurgeDeleted(ctx); err != nil {
+		j.logger.Error("purge deleted rows failed", xlogger.Error(err))
+		return err
+	}
+
+	return nil
+}
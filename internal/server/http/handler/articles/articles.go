@@ -1,9 +1,6 @@
 package articles
 
 import (
-	"encoding/json"
-	"net/url"
-
 	"github.com/labstack/echo/v4"
 	"thomas.vn/apartment_service/internal/domain/model"
 	"thomas.vn/apartment_service/internal/domain/usecase"
@@ -31,9 +28,10 @@ func NewArticleHandler(logger *xlogger.Logger, articlesUC usecase.ArticlesUsecas
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Limit per page"
+// @Param cursor query string false "Opaque cursor from nextCursor, replaces page"
 // @Param sort_by query string false "Sort by field"
 // @Param order_by query string false "Order by asc/desc"
-// @Param filters query string false "JSON encoded filters"
+// @Param filters query string false "JSON encoded filters, example: {\"views\":{\"range\":{\"from\":10}},\"content\":\"news\"}"
 // @Success 200 {object} xhttp.APIResponse{data=[]model.Articles}
 // @Failure 400 {object} xhttp.APIResponse400Err{}
 // @Failure 500 {object} xhttp.APIResponse500Err{}
@@ -43,24 +41,65 @@ func (h *ArticleHandler) List(c echo.Context) error {
 	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
 		return xhttp.BadRequestResponse(c, err)
 	}
-	var filters model.ArticlesFilters
-
-	if req.Filters != "" && req.Filters != "undefined" {
-		decoded, err := url.QueryUnescape(req.Filters)
-		if err != nil {
-			return xhttp.BadRequestResponse(c, err)
-		}
-		if err := json.Unmarshal([]byte(decoded), &filters); err != nil {
-			return xhttp.BadRequestResponse(c, err)
-		}
+	spec, err := model.ArticleQuerySchema.Parse(req.Filters, req.SortOptions, req.DateRangeOptions, req.PaginationOptions)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err.Error())
 	}
 
 	res, total, err := h.articlesUC.ListArticles(
 		c.Request().Context(),
 		&req,
-		&filters)
+		spec)
 	if err != nil {
 		return xhttp.AppErrorResponse(c, err)
 	}
-	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total)
+	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total, xhttp.WithNextCursor(spec.NextCursor(res, req.PaginationOptions)))
+}
+
+// Delete godoc
+// @Summary Delete article
+// @Description Soft delete an article by ID
+// @Tags articles
+// @Produce json
+// @Param id path int true "Article ID"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/article/{id} [delete]
+func (h *ArticleHandler) Delete(c echo.Context) error {
+	var req model.ArticleIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.articlesUC.DeleteArticle(c.Request().Context(), req.ID); err != nil {
+		h.logger.Error("Delete article failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// Restore godoc
+// @Summary Restore article
+// @Description Restore a soft deleted article by ID
+// @Tags articles
+// @Produce json
+// @Param id path int true "Article ID"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/article/{id}/restore [post]
+func (h *ArticleHandler) Restore(c echo.Context) error {
+	var req model.ArticleIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.articlesUC.RestoreArticle(c.Request().Context(), req.ID); err != nil {
+		h.logger.Error("Restore article failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
 }
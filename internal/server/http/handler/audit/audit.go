@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+var auditCSVHeader = []string{
+	"id", "actor_id", "action", "target_type", "target_id",
+	"changes", "ip", "user_agent", "request_id", "created_at",
+}
+
+type AuditHandler struct {
+	logger  *xlogger.Logger
+	auditUC usecase.AuditUsecase
+}
+
+func NewAuditHandler(logger *xlogger.Logger, auditUC usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{
+		logger:  logger,
+		auditUC: auditUC,
+	}
+}
+
+// List godoc
+// @Summary List audit logs
+// @Description List audit logs with pagination and filters
+// @Tags audit-logs
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page"
+// @Param actor_id query int false "Actor user ID"
+// @Param action query string false "Action, e.g. user.update"
+// @Param target_type query string false "Target type, e.g. user"
+// @Param target_id query int false "Target ID"
+// @Param from_date query string false "From date (YYYY-MM-DD)"
+// @Param to_date query string false "To date (YYYY-MM-DD)"
+// @Success 200 {object} xhttp.APIResponse{data=[]model.AuditLog}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Security BearerAuth
+// @Router /api/audit-logs [get]
+func (h *AuditHandler) List(c echo.Context) error {
+	var req model.ListAuditLogRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, total, err := h.auditUC.ListAuditLogs(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("List audit logs failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total)
+}
+
+// Export godoc
+// @Summary Export audit logs
+// @Description Export audit logs matching the filters as CSV
+// @Tags audit-logs
+// @Produce text/csv
+// @Param actor_id query int false "Actor user ID"
+// @Param action query string false "Action, e.g. user.update"
+// @Param target_type query string false "Target type, e.g. user"
+// @Param target_id query int false "Target ID"
+// @Param from_date query string false "From date (YYYY-MM-DD)"
+// @Param to_date query string false "To date (YYYY-MM-DD)"
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Security BearerAuth
+// @Router /api/audit-logs/export [get]
+func (h *AuditHandler) Export(c echo.Context) error {
+	var req model.ListAuditLogRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	logs, err := h.auditUC.ExportAuditLogs(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("Export audit logs failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	rows := make([][]string, 0, len(logs))
+	for _, l := range logs {
+		rows = append(rows, []string{
+			strconv.FormatInt(l.ID, 10),
+			strconv.Itoa(l.ActorID),
+			l.Action,
+			l.TargetType,
+			strconv.FormatInt(l.TargetID, 10),
+			l.Changes,
+			l.IP,
+			l.UserAgent,
+			l.RequestID,
+			l.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	filename := "audit_logs_" + time.Now().Format("20060102150405") + ".csv"
+	return xhttp.CSVResponse(c, filename, auditCSVHeader, rows)
+}
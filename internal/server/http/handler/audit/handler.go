@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type Handler struct {
+	logger       *xlogger.Logger
+	auditHandler *AuditHandler
+}
+
+type HandlerOption func(*Handler)
+
+func WithAuditUsecase(uc usecase.AuditUsecase) HandlerOption {
+	return func(h *Handler) {
+		h.auditHandler = NewAuditHandler(h.logger, uc)
+	}
+}
+
+func NewHandler(logger *xlogger.Logger, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Audit returns the audit log handler
+func (h *Handler) Audit() *AuditHandler {
+	return h.auditHandler
+}
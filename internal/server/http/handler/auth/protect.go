@@ -9,6 +9,7 @@ import (
 
 	"thomas.vn/apartment_service/internal/domain/repository"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xrequestinfo "thomas.vn/apartment_service/pkg/requestinfo"
 )
 
 type contextKey string
@@ -72,6 +73,7 @@ func (m *AuthMiddleware) Protect(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		c.Set(string(UserContextKey), user)
+		c.SetRequest(c.Request().WithContext(xrequestinfo.WithActor(c.Request().Context(), user.ID)))
 
 		return next(c)
 	}
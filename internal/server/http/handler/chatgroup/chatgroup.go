@@ -5,42 +5,49 @@ import (
 	"thomas.vn/apartment_service/internal/domain/model/chatgroup"
 	"thomas.vn/apartment_service/internal/domain/usecase"
 	xhttp "thomas.vn/apartment_service/pkg/http"
+	xcontext "thomas.vn/apartment_service/pkg/http/context"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
 )
 
 type ChatGroupsHandler struct {
 	logger      *xlogger.Logger
 	ChatGroupUC usecase.ChatGroupUsecase
+	ChatUC      usecase.ChatUsecase
 }
 
-func NewChatGroupHandler(logger *xlogger.Logger, chatGroupUc usecase.ChatGroupUsecase) *ChatGroupsHandler {
+func NewChatGroupHandler(logger *xlogger.Logger, chatGroupUc usecase.ChatGroupUsecase, chatUC usecase.ChatUsecase) *ChatGroupsHandler {
 	return &ChatGroupsHandler{
 		logger:      logger,
 		ChatGroupUC: chatGroupUc,
+		ChatUC:      chatUC,
 	}
 }
 
 // List godoc
-// @Summary List chat groups
-// @Description Get list of chat groups with pagination
+// @Summary List conversations
+// @Description Get the chat groups of the current user, most recently active first, with the latest message and unread count
 // @Tags chat-groups
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Limit per page"
-// @Param sort_by query string false "Sort by field"
-// @Param order_by query string false "Order by asc/desc"
-// @Success 200 {object} xhttp.APIResponse{data=[]chatgroup.ChatGroup}
+// @Param isOne query bool false "Only 1-1 chats"
+// @Success 200 {object} xhttp.APIResponse{data=[]chatgroup.ListResponse}
 // @Failure 400 {object} xhttp.APIResponse400Err{}
 // @Failure 500 {object} xhttp.APIResponse500Err{}
-// @Router /api/chat-groups [get]
+// @Router /api/chat-group [get]
 func (h *ChatGroupsHandler) List(c echo.Context) error {
 	var req chatgroup.ListChatGroupRequest
 	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
 		return xhttp.BadRequestResponse(c, err)
 	}
 
-	res, total, err := h.ChatGroupUC.ListChatGroups(c.Request().Context(), &req)
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, total, err := h.ChatGroupUC.ListChatGroups(c.Request().Context(), int64(user.ID), &req)
 	if err != nil {
 		h.logger.Error("List chat messages failed", xlogger.Error(err))
 		return xhttp.AppErrorResponse(c, err)
@@ -48,3 +55,321 @@ func (h *ChatGroupsHandler) List(c echo.Context) error {
 
 	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total)
 }
+
+// Create godoc
+// @Summary Create a chat group
+// @Description Create a chat group owned by the current user. With a single target user this opens the 1-1 chat, returning the existing one if any
+// @Tags chat-groups
+// @Accept json
+// @Produce json
+// @Param body body chatgroup.CreateChatGroupRequest true "Chat group"
+// @Success 200 {object} xhttp.APIResponse{data=chatgroup.ChatGroup}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group [post]
+func (h *ChatGroupsHandler) Create(c echo.Context) error {
+	var req chatgroup.CreateChatGroupRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+	req.OwnerID = int64(user.ID)
+
+	res, err := h.ChatUC.CreateRoom(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("Create chat group failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// AddMembers godoc
+// @Summary Add chat group members
+// @Description Add users to a chat group. Only group admins may add members.
+// @Tags chat-groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Param body body chatgroup.AddMembersRequest true "Users to add"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id}/members [post]
+func (h *ChatGroupsHandler) AddMembers(c echo.Context) error {
+	var req chatgroup.AddMembersRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.ChatGroupUC.AddMembers(c.Request().Context(), int64(user.ID), &req); err != nil {
+		h.logger.Error("Add chat group members failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// RemoveMember godoc
+// @Summary Remove chat group member
+// @Description Remove a user from a chat group. Only group admins may remove members.
+// @Tags chat-groups
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id}/members/{userId} [delete]
+func (h *ChatGroupsHandler) RemoveMember(c echo.Context) error {
+	var req chatgroup.MemberRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.ChatGroupUC.RemoveMember(c.Request().Context(), int64(user.ID), &req); err != nil {
+		h.logger.Error("Remove chat group member failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// UpdateMemberRole godoc
+// @Summary Change chat group member role
+// @Description Promote a member to group admin or demote an admin. Only group admins may change roles.
+// @Tags chat-groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Param userId path int true "User ID"
+// @Param body body chatgroup.UpdateMemberRoleRequest true "New role"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id}/members/{userId}/role [put]
+func (h *ChatGroupsHandler) UpdateMemberRole(c echo.Context) error {
+	var req chatgroup.UpdateMemberRoleRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.ChatGroupUC.UpdateMemberRole(c.Request().Context(), int64(user.ID), &req); err != nil {
+		h.logger.Error("Update chat group member role failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// Rename godoc
+// @Summary Rename chat group
+// @Description Rename a chat group. Only group admins may rename it.
+// @Tags chat-groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Param body body chatgroup.RenameChatGroupRequest true "New name"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id} [put]
+func (h *ChatGroupsHandler) Rename(c echo.Context) error {
+	var req chatgroup.RenameChatGroupRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.ChatGroupUC.RenameChatGroup(c.Request().Context(), int64(user.ID), &req); err != nil {
+		h.logger.Error("Rename chat group failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// Leave godoc
+// @Summary Leave chat group
+// @Description Leave a chat group. The oldest member is promoted when the last admin leaves.
+// @Tags chat-groups
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id}/leave [post]
+func (h *ChatGroupsHandler) Leave(c echo.Context) error {
+	var req chatgroup.ChatGroupIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.ChatGroupUC.LeaveChatGroup(c.Request().Context(), int64(user.ID), req.ID); err != nil {
+		h.logger.Error("Leave chat group failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// Delete godoc
+// @Summary Delete chat group
+// @Description Soft delete a chat group. Only group admins may delete it.
+// @Tags chat-groups
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id} [delete]
+func (h *ChatGroupsHandler) Delete(c echo.Context) error {
+	var req chatgroup.ChatGroupIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.ChatGroupUC.DeleteChatGroup(c.Request().Context(), int64(user.ID), req.ID); err != nil {
+		h.logger.Error("Delete chat group failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// MarkRead godoc
+// @Summary Mark chat group messages read
+// @Description Move the read marker of the current user to a message, or to the latest message when message_id is omitted
+// @Tags chat-groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Param body body chatgroup.MarkReadRequest false "Last read message"
+// @Success 200 {object} xhttp.APIResponse{data=chatgroup.ReadReceipt}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id}/read [post]
+func (h *ChatGroupsHandler) MarkRead(c echo.Context) error {
+	var req chatgroup.MarkReadRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.ChatGroupUC.MarkRead(c.Request().Context(), int64(user.ID), &req)
+	if err != nil {
+		h.logger.Error("Mark chat group read failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// Mute godoc
+// @Summary Mute chat group notifications
+// @Description Mute or unmute the unread message digest mails of a chat group for the current user. Without until a mute lasts until it is lifted
+// @Tags chat-groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Param body body chatgroup.MuteRequest true "Mute setting"
+// @Success 200 {object} xhttp.APIResponse{data=chatgroup.MuteSetting}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id}/mute [put]
+func (h *ChatGroupsHandler) Mute(c echo.Context) error {
+	var req chatgroup.MuteRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.ChatGroupUC.SetMute(c.Request().Context(), int64(user.ID), &req)
+	if err != nil {
+		h.logger.Error("Mute chat group failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// Presence godoc
+// @Summary List chat group member presence
+// @Description Get whether the members of a chat group are online and when they were last seen
+// @Tags chat-groups
+// @Produce json
+// @Param id path int true "Chat group ID"
+// @Success 200 {object} xhttp.APIResponse{data=[]model.UserPresence}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-group/{id}/presence [get]
+func (h *ChatGroupsHandler) Presence(c echo.Context) error {
+	var req chatgroup.ChatGroupIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.ChatGroupUC.ListMemberPresence(c.Request().Context(), int64(user.ID), req.ID)
+	if err != nil {
+		h.logger.Error("List chat group presence failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
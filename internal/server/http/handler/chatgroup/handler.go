@@ -7,6 +7,8 @@ import (
 
 type Handler struct {
 	logger           *xlogger.Logger
+	chatGroupUC      usecase.ChatGroupUsecase
+	chatUC           usecase.ChatUsecase
 	chatGroupHandler *ChatGroupsHandler
 }
 
@@ -14,7 +16,15 @@ type HandlerOption func(*Handler)
 
 func WithChatGroupUsecase(chatGroupUc usecase.ChatGroupUsecase) HandlerOption {
 	return func(h *Handler) {
-		h.chatGroupHandler = NewChatGroupHandler(h.logger, chatGroupUc)
+		h.chatGroupUC = chatGroupUc
+	}
+}
+
+// WithChatUsecase sets the usecase shared with the WebSocket server, used to
+// create rooms.
+func WithChatUsecase(chatUC usecase.ChatUsecase) HandlerOption {
+	return func(h *Handler) {
+		h.chatUC = chatUC
 	}
 }
 
@@ -25,6 +35,8 @@ func NewHandler(logger *xlogger.Logger, opts ...HandlerOption) *Handler {
 	for _, opt := range opts {
 		opt(h)
 	}
+
+	h.chatGroupHandler = NewChatGroupHandler(h.logger, h.chatGroupUC, h.chatUC)
 	return h
 }
 
@@ -1,24 +1,34 @@
 package chatmessage
 
 import (
-	"encoding/json"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
 	"thomas.vn/apartment_service/internal/domain/usecase"
 	xhttp "thomas.vn/apartment_service/pkg/http"
+	xcontext "thomas.vn/apartment_service/pkg/http/context"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
 )
 
+// Size limits of chat attachments.
+const (
+	maxAttachmentImageSize    = 10 << 20
+	maxAttachmentDocumentSize = 20 << 20
+)
+
 type ChatMessagesHandler struct {
 	logger        *xlogger.Logger
 	chatMessageUc usecase.ChatMessageUsecase
+	chatUC        usecase.ChatUsecase
 }
 
-func NewChatMessageHandler(logger *xlogger.Logger, chatMessageUc usecase.ChatMessageUsecase) *ChatMessagesHandler {
+func NewChatMessageHandler(logger *xlogger.Logger, chatMessageUc usecase.ChatMessageUsecase, chatUC usecase.ChatUsecase) *ChatMessagesHandler {
 	return &ChatMessagesHandler{
 		logger:        logger,
 		chatMessageUc: chatMessageUc,
+		chatUC:        chatUC,
 	}
 }
 
@@ -30,6 +40,7 @@ func NewChatMessageHandler(logger *xlogger.Logger, chatMessageUc usecase.ChatMes
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Limit per page"
+// @Param cursor query string false "Opaque cursor from nextCursor, replaces page"
 // @Param sort_by query string false "Sort by field"
 // @Param order_by query string false "Order by asc/desc"
 // @Param filters query string true "Filter JSON, example: {\"chatGroupID\":1}"
@@ -44,26 +55,323 @@ func (h *ChatMessagesHandler) List(c echo.Context) error {
 		return xhttp.BadRequestResponse(c, err)
 	}
 
-	rawFilters := c.QueryParam("filters")
-	if rawFilters != "" {
-		var f struct {
-			ChatGroupID int `json:"chatGroupID"`
-		}
-		if err := json.Unmarshal([]byte(rawFilters), &f); err != nil {
-			return xhttp.BadRequestResponse(c, "filters must be valid JSON")
-		}
-		req.ChatGroupID = f.ChatGroupID
+	spec, err := chatmessage.ChatMessageQuerySchema.Parse(req.Filters, req.SortOptions, req.DateRangeOptions, req.PaginationOptions)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err.Error())
 	}
 
-	if req.ChatGroupID == 0 {
+	if !spec.Has("chatGroupID") {
 		return xhttp.BadRequestResponse(c, "ChatGroupID is required")
 	}
 
-	res, total, err := h.chatMessageUc.ListChatMessages(c.Request().Context(), &req)
+	res, total, err := h.chatMessageUc.ListChatMessages(c.Request().Context(), &req, spec)
 	if err != nil {
 		h.logger.Error("List chat messages failed", xlogger.Error(err))
 		return xhttp.AppErrorResponse(c, err)
 	}
 
+	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total, xhttp.WithNextCursor(spec.NextCursor(res, req.PaginationOptions)))
+}
+
+// Send godoc
+// @Summary Send chat message
+// @Description Send a message to a chat group of the current user. It is pushed to the room like a message sent over the WebSocket
+// @Tags chat-messages
+// @Accept json
+// @Produce json
+// @Param body body chatmessage.CreateChatMessageRequest true "Message"
+// @Success 200 {object} xhttp.APIResponse{data=chatmessage.Response}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message [post]
+func (h *ChatMessagesHandler) Send(c echo.Context) error {
+	var req chatmessage.CreateChatMessageRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+	req.UserIDSender = user.ID
+
+	res, err := h.chatUC.SendMessage(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("Send chat message failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// Edit godoc
+// @Summary Edit chat message
+// @Description Replace the text of your own message. The previous text is kept in the edit history.
+// @Tags chat-messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Chat message ID"
+// @Param body body chatmessage.EditChatMessageRequest true "New text"
+// @Success 200 {object} xhttp.APIResponse{data=chatmessage.Response}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/{id} [put]
+func (h *ChatMessagesHandler) Edit(c echo.Context) error {
+	var req chatmessage.EditChatMessageRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.chatUC.EditMessage(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		h.logger.Error("Edit chat message failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// Delete godoc
+// @Summary Delete chat message
+// @Description Delete a message for everyone. Senders can delete their messages, group admins any message.
+// @Tags chat-messages
+// @Produce json
+// @Param id path int true "Chat message ID"
+// @Success 200 {object} xhttp.APIResponse{data=chatmessage.DeletedEvent}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/{id} [delete]
+func (h *ChatMessagesHandler) Delete(c echo.Context) error {
+	var req chatmessage.ChatMessageIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.chatMessageUc.DeleteMessage(c.Request().Context(), user.ID, req.ID)
+	if err != nil {
+		h.logger.Error("Delete chat message failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// React godoc
+// @Summary Toggle chat message reaction
+// @Description Add an emoji reaction to a message, or remove it when already added
+// @Tags chat-messages
+// @Accept json
+// @Produce json
+// @Param id path int true "Chat message ID"
+// @Param body body chatmessage.ReactRequest true "Emoji"
+// @Success 200 {object} xhttp.APIResponse{data=chatmessage.ReactionEvent}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/{id}/reactions [post]
+func (h *ChatMessagesHandler) React(c echo.Context) error {
+	var req chatmessage.ReactRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.chatMessageUc.React(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		h.logger.Error("React to chat message failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// Edits godoc
+// @Summary List chat message edits
+// @Description Get the previous versions of an edited message, oldest first
+// @Tags chat-messages
+// @Produce json
+// @Param id path int true "Chat message ID"
+// @Success 200 {object} xhttp.APIResponse{data=[]chatmessage.Edit}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/{id}/edits [get]
+func (h *ChatMessagesHandler) Edits(c echo.Context) error {
+	var req chatmessage.ChatMessageIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.chatMessageUc.ListEdits(c.Request().Context(), user.ID, req.ID)
+	if err != nil {
+		h.logger.Error("List chat message edits failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// UploadAttachment godoc
+// @Summary Upload chat attachment
+// @Description Upload an image (jpg, jpeg, png, gif) or document (pdf, doc, docx, xls, xlsx, txt) to a chat group. The upload is processed in the background; send the returned id in attachment_ids of a message.
+// @Tags chat-messages
+// @Accept multipart/form-data
+// @Produce json
+// @Param chat_group_id formData int true "Chat group ID"
+// @Param file formData file true "Attachment file"
+// @Success 200 {object} xhttp.APIResponse{data=chatmessage.AttachmentResponse}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/attachments [post]
+func (h *ChatMessagesHandler) UploadAttachment(c echo.Context) error {
+	var req chatmessage.UploadAttachmentRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return xhttp.AppErrorResponse(c, xhttp.NewAppError(
+			"ERR_FILE_NOT_FOUND",
+			"file",
+			"Attachment file is required",
+			http.StatusBadRequest,
+		))
+	}
+
+	req.File = file
+	req.Kind = consts.ChatAttachmentKindDocument
+	if xhttp.IsImageFile(file) {
+		req.Kind = consts.ChatAttachmentKindImage
+		err = xhttp.ValidateImageFile(file, maxAttachmentImageSize)
+	} else {
+		err = xhttp.ValidateDocumentFile(file, maxAttachmentDocumentSize)
+	}
+	if err != nil {
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	res, err := h.chatMessageUc.UploadAttachment(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		h.logger.Error("Upload chat attachment failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// DownloadAttachment godoc
+// @Summary Download chat attachment
+// @Description Redirect members of the chat group to a short-lived link to the file
+// @Tags chat-messages
+// @Param id path int true "Attachment ID"
+// @Success 302
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/attachments/{id}/download [get]
+func (h *ChatMessagesHandler) DownloadAttachment(c echo.Context) error {
+	var req chatmessage.AttachmentIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	url, err := h.chatMessageUc.AttachmentDownloadURL(c.Request().Context(), user.ID, req.ID)
+	if err != nil {
+		h.logger.Error("Get chat attachment download url failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return c.Redirect(http.StatusFound, url)
+}
+
+// Search godoc
+// @Summary Search chat messages
+// @Description Full-text search of the messages in the caller's chat groups, with or without Vietnamese diacritics. Matches are highlighted with <em> tags.
+// @Tags chat-messages
+// @Produce json
+// @Param q query string true "Search text"
+// @Param chat_group_id query int false "Only search this chat group"
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page (max 50)"
+// @Success 200 {object} xhttp.APIResponse{data=[]chatmessage.SearchResult}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/search [get]
+func (h *ChatMessagesHandler) Search(c echo.Context) error {
+	var req chatmessage.SearchRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, total, err := h.chatMessageUc.SearchMessages(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		h.logger.Error("Search chat messages failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
 	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total)
 }
+
+// Reindex godoc
+// @Summary Rebuild chat message search index
+// @Description Index every chat message again, e.g. after creating the index
+// @Tags chat-messages
+// @Produce json
+// @Success 200 {object} xhttp.APIResponse
+// @Failure 401 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/search/reindex [post]
+func (h *ChatMessagesHandler) Reindex(c echo.Context) error {
+	indexed, err := h.chatMessageUc.ReindexSearch(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Reindex chat messages failed", xlogger.Error(err), xlogger.Int("indexed", indexed))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, map[string]int{
+		"indexed": indexed,
+	})
+}
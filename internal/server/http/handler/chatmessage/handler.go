@@ -7,6 +7,8 @@ import (
 
 type Handler struct {
 	logger             *xlogger.Logger
+	chatMessageUC      usecase.ChatMessageUsecase
+	chatUC             usecase.ChatUsecase
 	chatMessageHandler *ChatMessagesHandler
 }
 
@@ -18,7 +20,15 @@ type HandlerOption func(*Handler)
 
 func WithChatMessageUsecase(uc usecase.ChatMessageUsecase) HandlerOption {
 	return func(h *Handler) {
-		h.chatMessageHandler = NewChatMessageHandler(h.logger, uc)
+		h.chatMessageUC = uc
+	}
+}
+
+// WithChatUsecase sets the usecase shared with the WebSocket server, used to
+// send and edit messages.
+func WithChatUsecase(uc usecase.ChatUsecase) HandlerOption {
+	return func(h *Handler) {
+		h.chatUC = uc
 	}
 }
 
@@ -31,6 +41,7 @@ func NewHandler(logger *xlogger.Logger, opts ...HandlerOption) *Handler {
 		opt(h)
 	}
 
+	h.chatMessageHandler = NewChatMessageHandler(h.logger, h.chatMessageUC, h.chatUC)
 	return h
 }
 
@@ -0,0 +1,34 @@
+package mailoutbox
+
+import (
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type Handler struct {
+	logger            *xlogger.Logger
+	mailOutboxHandler *MailOutboxHandler
+}
+
+type HandlerOption func(*Handler)
+
+func WithMailOutboxUsecase(uc usecase.MailOutboxUsecase) HandlerOption {
+	return func(h *Handler) {
+		h.mailOutboxHandler = NewMailOutboxHandler(h.logger, uc)
+	}
+}
+
+func NewHandler(logger *xlogger.Logger, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// MailOutbox returns the mail outbox handler
+func (h *Handler) MailOutbox() *MailOutboxHandler {
+	return h.mailOutboxHandler
+}
@@ -0,0 +1,104 @@
+package mailoutbox
+
+import (
+	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type MailOutboxHandler struct {
+	logger       *xlogger.Logger
+	mailOutboxUC usecase.MailOutboxUsecase
+}
+
+func NewMailOutboxHandler(logger *xlogger.Logger, mailOutboxUC usecase.MailOutboxUsecase) *MailOutboxHandler {
+	return &MailOutboxHandler{
+		logger:       logger,
+		mailOutboxUC: mailOutboxUC,
+	}
+}
+
+// List godoc
+// @Summary List outgoing mail
+// @Description List the mail outbox with its delivery status, newest first
+// @Tags mail
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page"
+// @Param status query string false "Status (pending, sending, sent, failed)"
+// @Param template query string false "Template name"
+// @Param recipient query string false "Recipient email"
+// @Param from_date query string false "From date (YYYY-MM-DD)"
+// @Param to_date query string false "To date (YYYY-MM-DD)"
+// @Success 200 {object} xhttp.APIResponse{data=[]model.MailOutbox}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/mail/outbox [get]
+func (h *MailOutboxHandler) List(c echo.Context) error {
+	var req model.ListMailOutboxRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, total, err := h.mailOutboxUC.ListMailOutbox(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("List mail outbox failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total)
+}
+
+// Get godoc
+// @Summary Get outgoing mail
+// @Description Get a mail of the outbox with its delivery status
+// @Tags mail
+// @Produce json
+// @Param id path int true "Mail ID"
+// @Success 200 {object} xhttp.APIResponse{data=model.MailOutbox}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/mail/outbox/{id} [get]
+func (h *MailOutboxHandler) Get(c echo.Context) error {
+	var req model.MailOutboxIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.mailOutboxUC.GetMailOutbox(c.Request().Context(), req.ID)
+	if err != nil {
+		h.logger.Error("Get mail outbox failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// Resend godoc
+// @Summary Resend failed mail
+// @Description Queue a failed mail for delivery again
+// @Tags mail
+// @Produce json
+// @Param id path int true "Mail ID"
+// @Success 200 {object} xhttp.APIResponse{data=model.MailOutbox}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/mail/outbox/{id}/resend [post]
+func (h *MailOutboxHandler) Resend(c echo.Context) error {
+	var req model.MailOutboxIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.mailOutboxUC.Resend(c.Request().Context(), req.ID)
+	if err != nil {
+		h.logger.Error("Resend mail failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
@@ -0,0 +1,34 @@
+package mailtemplate
+
+import (
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type Handler struct {
+	logger              *xlogger.Logger
+	mailTemplateHandler *MailTemplateHandler
+}
+
+type HandlerOption func(*Handler)
+
+func WithMailUsecase(uc usecase.MailUsecase) HandlerOption {
+	return func(h *Handler) {
+		h.mailTemplateHandler = NewMailTemplateHandler(h.logger, uc)
+	}
+}
+
+func NewHandler(logger *xlogger.Logger, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// MailTemplate returns the mail template preview handler
+func (h *Handler) MailTemplate() *MailTemplateHandler {
+	return h.mailTemplateHandler
+}
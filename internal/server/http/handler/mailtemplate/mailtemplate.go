@@ -0,0 +1,60 @@
+package mailtemplate
+
+import (
+	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type MailTemplateHandler struct {
+	logger *xlogger.Logger
+	mailUC usecase.MailUsecase
+}
+
+func NewMailTemplateHandler(logger *xlogger.Logger, mailUC usecase.MailUsecase) *MailTemplateHandler {
+	return &MailTemplateHandler{
+		logger: logger,
+		mailUC: mailUC,
+	}
+}
+
+// List godoc
+// @Summary List mail templates
+// @Description List the mail templates and the locales each one is written in
+// @Tags mail
+// @Produce json
+// @Success 200 {object} xhttp.APIResponse{data=[]model.MailTemplate}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Router /api/mail/templates [get]
+func (h *MailTemplateHandler) List(c echo.Context) error {
+	return xhttp.SuccessResponse(c, h.mailUC.ListTemplates(c.Request().Context()))
+}
+
+// Preview godoc
+// @Summary Preview a mail template
+// @Description Render a mail template with sample data in the given locale
+// @Tags mail
+// @Produce json
+// @Param name path string true "Template name"
+// @Param locale query string false "Locale (vi, en)"
+// @Success 200 {object} xhttp.APIResponse{data=model.RenderedMail}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/mail/templates/{name}/preview [get]
+func (h *MailTemplateHandler) Preview(c echo.Context) error {
+	var req model.PreviewMailRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.mailUC.PreviewTemplate(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("Preview mail template failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
@@ -0,0 +1,34 @@
+package moderation
+
+import (
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type Handler struct {
+	logger            *xlogger.Logger
+	moderationHandler *ModerationHandler
+}
+
+type HandlerOption func(*Handler)
+
+func WithModerationUsecase(uc usecase.ModerationUsecase) HandlerOption {
+	return func(h *Handler) {
+		h.moderationHandler = NewModerationHandler(h.logger, uc)
+	}
+}
+
+func NewHandler(logger *xlogger.Logger, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Moderation returns the chat moderation handler
+func (h *Handler) Moderation() *ModerationHandler {
+	return h.moderationHandler
+}
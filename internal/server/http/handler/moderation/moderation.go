@@ -0,0 +1,202 @@
+package moderation
+
+import (
+	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/model/moderation"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xcontext "thomas.vn/apartment_service/pkg/http/context"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type ModerationHandler struct {
+	logger       *xlogger.Logger
+	moderationUC usecase.ModerationUsecase
+}
+
+func NewModerationHandler(logger *xlogger.Logger, moderationUC usecase.ModerationUsecase) *ModerationHandler {
+	return &ModerationHandler{
+		logger:       logger,
+		moderationUC: moderationUC,
+	}
+}
+
+// Block godoc
+// @Summary Block a user
+// @Description Block a user. Blocked users cannot start or continue a 1-1 chat with the current user
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param body body moderation.BlockRequest true "User to block"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/moderation/blocks [post]
+func (h *ModerationHandler) Block(c echo.Context) error {
+	var req moderation.BlockRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.moderationUC.BlockUser(c.Request().Context(), user.ID, &req); err != nil {
+		h.logger.Error("Block user failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// Unblock godoc
+// @Summary Unblock a user
+// @Description Lift a block set by the current user
+// @Tags moderation
+// @Produce json
+// @Param userId path int true "Blocked user ID"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/moderation/blocks/{userId} [delete]
+func (h *ModerationHandler) Unblock(c echo.Context) error {
+	var req moderation.UnblockRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.moderationUC.UnblockUser(c.Request().Context(), user.ID, &req); err != nil {
+		h.logger.Error("Unblock user failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
+// ListBlocks godoc
+// @Summary List blocked users
+// @Description List the users blocked by the current user
+// @Tags moderation
+// @Produce json
+// @Success 200 {object} xhttp.APIResponse{data=[]moderation.BlockedUser}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/moderation/blocks [get]
+func (h *ModerationHandler) ListBlocks(c echo.Context) error {
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.moderationUC.ListBlockedUsers(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger.Error("List blocked users failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// ReportMessage godoc
+// @Summary Report a chat message
+// @Description Flag a message of one of the current user's chat groups for moderator review
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "Chat message ID"
+// @Param body body moderation.ReportMessageRequest false "Report reason"
+// @Success 200 {object} xhttp.APIResponse{data=moderation.Report}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/chat-message/{id}/report [post]
+func (h *ModerationHandler) ReportMessage(c echo.Context) error {
+	var req moderation.ReportMessageRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.moderationUC.ReportMessage(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		h.logger.Error("Report chat message failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// ListReports godoc
+// @Summary List reported chat messages
+// @Description List chat message reports for moderator review, oldest first
+// @Tags moderation
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page"
+// @Param status query string false "Report status: pending, removed or dismissed"
+// @Success 200 {object} xhttp.APIResponse{data=[]moderation.ReportResponse}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Security BearerAuth
+// @Router /api/moderation/reports [get]
+func (h *ModerationHandler) ListReports(c echo.Context) error {
+	var req moderation.ListReportRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, total, err := h.moderationUC.ListReports(c.Request().Context(), &req)
+	if err != nil {
+		h.logger.Error("List chat message reports failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total)
+}
+
+// ReviewReport godoc
+// @Summary Review a chat message report
+// @Description Remove the reported message for everyone or dismiss the report. All pending reports of the message are closed
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param body body moderation.ReviewReportRequest true "Review action"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Security BearerAuth
+// @Router /api/moderation/reports/{id}/review [post]
+func (h *ModerationHandler) ReviewReport(c echo.Context) error {
+	var req moderation.ReviewReportRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	if err := h.moderationUC.ReviewReport(c.Request().Context(), user.ID, &req); err != nil {
+		h.logger.Error("Review chat message report failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
@@ -0,0 +1,46 @@
+package notification
+
+import (
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type Handler struct {
+	logger              *xlogger.Logger
+	notificationHandler *NotificationHandler
+	preferenceHandler   *PreferenceHandler
+}
+
+type HandlerOption func(*Handler)
+
+func WithNotificationUsecase(uc usecase.NotificationUsecase) HandlerOption {
+	return func(h *Handler) {
+		h.notificationHandler = NewNotificationHandler(h.logger, uc)
+	}
+}
+
+func WithNotificationPreferenceUsecase(uc usecase.NotificationPreferenceUsecase) HandlerOption {
+	return func(h *Handler) {
+		h.preferenceHandler = NewPreferenceHandler(h.logger, uc)
+	}
+}
+
+func NewHandler(logger *xlogger.Logger, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		logger: logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Notification returns the notification inbox handler
+func (h *Handler) Notification() *NotificationHandler {
+	return h.notificationHandler
+}
+
+// Preference returns the notification preference handler
+func (h *Handler) Preference() *PreferenceHandler {
+	return h.preferenceHandler
+}
@@ -0,0 +1,133 @@
+package notification
+
+import (
+	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xcontext "thomas.vn/apartment_service/pkg/http/context"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type NotificationHandler struct {
+	logger         *xlogger.Logger
+	notificationUC usecase.NotificationUsecase
+}
+
+func NewNotificationHandler(logger *xlogger.Logger, notificationUC usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{
+		logger:         logger,
+		notificationUC: notificationUC,
+	}
+}
+
+// List godoc
+// @Summary List notifications
+// @Description List the notifications of the current user, newest first
+// @Tags notifications
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page"
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} xhttp.APIResponse{data=[]model.Notification}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/notifications [get]
+func (h *NotificationHandler) List(c echo.Context) error {
+	var req model.ListNotificationRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, total, err := h.notificationUC.ListNotifications(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		h.logger.Error("List notifications failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total)
+}
+
+// Badge godoc
+// @Summary Count unread notifications
+// @Description Get the unread notification count of the current user
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} xhttp.APIResponse{data=model.NotificationBadge}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/notifications/unread-count [get]
+func (h *NotificationHandler) Badge(c echo.Context) error {
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.notificationUC.Badge(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger.Error("Count unread notifications failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// MarkRead godoc
+// @Summary Mark a notification read
+// @Description Mark a notification of the current user read and get the new unread count
+// @Tags notifications
+// @Produce json
+// @Param id path int true "Notification ID"
+// @Success 200 {object} xhttp.APIResponse{data=model.NotificationBadge}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 404 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	var req model.NotificationIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.notificationUC.MarkRead(c.Request().Context(), user.ID, req.ID)
+	if err != nil {
+		h.logger.Error("Mark notification read failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications read
+// @Description Mark every notification of the current user read
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} xhttp.APIResponse{data=model.NotificationBadge}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.notificationUC.MarkAllRead(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger.Error("Mark all notifications read failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
@@ -0,0 +1,117 @@
+package notification
+
+import (
+	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xcontext "thomas.vn/apartment_service/pkg/http/context"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type PreferenceHandler struct {
+	logger       *xlogger.Logger
+	preferenceUC usecase.NotificationPreferenceUsecase
+}
+
+func NewPreferenceHandler(logger *xlogger.Logger, preferenceUC usecase.NotificationPreferenceUsecase) *PreferenceHandler {
+	return &PreferenceHandler{
+		logger:       logger,
+		preferenceUC: preferenceUC,
+	}
+}
+
+// List godoc
+// @Summary List notification preferences
+// @Description List which channels the current user gets each notification category on
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} xhttp.APIResponse{data=[]model.NotificationPreference}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/notifications/preferences [get]
+func (h *PreferenceHandler) List(c echo.Context) error {
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.preferenceUC.ListPreferences(c.Request().Context(), user.ID)
+	if err != nil {
+		h.logger.Error("List notification preferences failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// Update godoc
+// @Summary Update notification preferences
+// @Description Turn notification categories on or off per channel for the current user
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateNotificationPreferencesRequest true "Preferences"
+// @Success 200 {object} xhttp.APIResponse{data=[]model.NotificationPreference}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/notifications/preferences [put]
+func (h *PreferenceHandler) Update(c echo.Context) error {
+	var req model.UpdateNotificationPreferencesRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	res, err := h.preferenceUC.UpdatePreferences(c.Request().Context(), user.ID, &req)
+	if err != nil {
+		h.logger.Error("Update notification preferences failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// CheckUnsubscribe godoc
+// @Summary Check an unsubscribe link
+// @Description Validate a signed unsubscribe link and name its mail category without unsubscribing, so link scanners cannot unsubscribe users. The user confirms with POST.
+// @Tags mail
+// @Produce json
+// @Param token query string true "Unsubscribe token"
+// @Success 200 {object} xhttp.APIResponse{data=model.UnsubscribeResponse}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/mail/unsubscribe [get]
+func (h *PreferenceHandler) CheckUnsubscribe(c echo.Context) error {
+	res, err := h.preferenceUC.CheckUnsubscribe(c.Request().Context(), c.QueryParam("token"))
+	if err != nil {
+		h.logger.Error("Check unsubscribe link failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
+
+// Unsubscribe godoc
+// @Summary Unsubscribe from mail
+// @Description Turn off mail of a category from a signed unsubscribe link. Also serves one-click unsubscribe from mail clients.
+// @Tags mail
+// @Produce json
+// @Param token query string true "Unsubscribe token"
+// @Success 200 {object} xhttp.APIResponse{data=model.UnsubscribeResponse}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/mail/unsubscribe [post]
+func (h *PreferenceHandler) Unsubscribe(c echo.Context) error {
+	res, err := h.preferenceUC.Unsubscribe(c.Request().Context(), c.QueryParam("token"))
+	if err != nil {
+		h.logger.Error("Unsubscribe from mail failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, res)
+}
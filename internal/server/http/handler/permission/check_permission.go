@@ -4,11 +4,13 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model"
 	xuser "thomas.vn/apartment_service/internal/domain/model/user"
 	xhttp "thomas.vn/apartment_service/pkg/http"
 	xcontext "thomas.vn/apartment_service/pkg/http/context"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xtenant "thomas.vn/apartment_service/pkg/tenant"
 
 	"thomas.vn/apartment_service/internal/domain/usecase"
 )
@@ -23,42 +25,100 @@ func NewPermissionMiddleware(logger *xlogger.Logger, permissionUC usecase.Permis
 }
 func (m *PermissionsMiddleware) Check(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		user, ok := c.Get(xcontext.UserContextKey).(*xuser.User)
-		if !ok || user == nil {
-			return xhttp.UnauthorizedResponse(c, "user not authenticated")
+		scope, err := m.resolve(c)
+		if scope == nil {
+			return err
 		}
 
-		req := model.CheckPermissionRequest{
-			RoleID:   user.RoleID,
-			Method:   c.Request().Method,
-			Endpoint: c.Path(),
+		if !scope.Global && len(scope.BuildingIDs) == 0 {
+			return xhttp.ForbiddenResponse(c, "permission denied")
 		}
 
-		hasPermission, err := m.permissionUC.CheckPermission(
-			c.Request().Context(),
-			req,
-		)
-		if err != nil {
-			m.logger.Error(
-				"check permission failed",
-				xlogger.Error(err),
-				xlogger.Int("role_id", user.RoleID),
-				xlogger.String("method", req.Method),
-				xlogger.String("endpoint", req.Endpoint),
-			)
-
-			return xhttp.NewAppError(
-				"ERR_INTERNAL_SERVER",
-				"",
-				"internal server error",
-				http.StatusInternalServerError,
-			)
+		ctx := xtenant.WithScope(c.Request().Context(), scope)
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
+
+// Scope is like Check for public routes: it attaches the caller's scope when
+// the caller holds the permission, and otherwise lets the request through
+// unscoped instead of denying it.
+func (m *PermissionsMiddleware) Scope(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		scope, err := m.resolve(c)
+		if scope == nil {
+			return err
 		}
 
-		if !hasPermission {
-			return xhttp.ForbiddenResponse(c, "permission denied")
+		if scope.Global || len(scope.BuildingIDs) > 0 {
+			ctx := xtenant.WithScope(c.Request().Context(), scope)
+			c.SetRequest(c.Request().WithContext(ctx))
 		}
 
 		return next(c)
 	}
 }
+
+// resolve returns the caller's scope for the current route. A nil scope
+// means the request was already answered; err is then the handler result.
+func (m *PermissionsMiddleware) resolve(c echo.Context) (*xtenant.Scope, error) {
+	user, ok := c.Get(xcontext.UserContextKey).(*xuser.User)
+	if !ok || user == nil {
+		return nil, xhttp.UnauthorizedResponse(c, "user not authenticated")
+	}
+
+	buildingID, err := buildingScopeFromRequest(c)
+	if err != nil {
+		return nil, xhttp.BadRequestResponse(c, err.Error())
+	}
+
+	req := model.CheckPermissionRequest{
+		UserID:     user.ID,
+		RoleID:     user.RoleID,
+		Method:     c.Request().Method,
+		Endpoint:   c.Path(),
+		BuildingID: buildingID,
+	}
+
+	scope, err := m.permissionUC.ResolveScope(
+		c.Request().Context(),
+		req,
+	)
+	if err != nil {
+		m.logger.Error(
+			"check permission failed",
+			xlogger.Error(err),
+			xlogger.Int("role_id", user.RoleID),
+			xlogger.String("method", req.Method),
+			xlogger.String("endpoint", req.Endpoint),
+			xlogger.Int64("building_id", req.BuildingID),
+		)
+
+		return nil, xhttp.NewAppError(
+			"ERR_INTERNAL_SERVER",
+			"",
+			"internal server error",
+			http.StatusInternalServerError,
+		)
+	}
+
+	return scope, nil
+}
+
+// buildingScopeFromRequest reads the building a request is scoped to from
+// the path, the query string or the X-Building-ID header, in that order.
+func buildingScopeFromRequest(c echo.Context) (int64, error) {
+	raw := c.Param(consts.BuildingScopeParam)
+	if raw == "" {
+		raw = c.QueryParam(consts.BuildingScopeParam)
+	}
+	if raw == "" {
+		raw = c.Request().Header.Get(consts.BuildingScopeHeader)
+	}
+	if raw == "" {
+		return consts.GlobalBuildingID, nil
+	}
+
+	return parseResourceID(consts.BuildingScopeParam, raw)
+}
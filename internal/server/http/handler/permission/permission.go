@@ -109,3 +109,68 @@ func (h *PermissionsHandler) Update(c echo.Context) error {
 	return xhttp.SuccessResponse(c, res)
 
 }
+
+// AssignRole godoc
+// @Summary Assign role
+// @Description Assign a role to a user, optionally scoped to a building
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Param data body model.AssignRoleRequest true "Assign role request"
+// @Success 201 {object} xhttp.APIResponse{data=model.UserRole}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 401 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Security BearerAuth
+// @Router /api/permission/assignments [post]
+func (h *PermissionsHandler) AssignRole(c echo.Context) error {
+	var req model.AssignRoleRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.UnauthorizedResponse(c, err)
+	}
+
+	res, err := h.permissionUC.AssignRole(c.Request().Context(), &req, user.ID, user.RoleID)
+	if err != nil {
+		h.logger.Error("Assign role failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.CreatedResponse(c, res)
+}
+
+// RevokeRole godoc
+// @Summary Revoke role
+// @Description Revoke a role assignment
+// @Tags permissions
+// @Produce json
+// @Param id path int true "Role assignment ID"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 404 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Security BearerAuth
+// @Router /api/permission/assignments/{id} [delete]
+func (h *PermissionsHandler) RevokeRole(c echo.Context) error {
+	var req model.UserRoleIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	user, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.UnauthorizedResponse(c, err)
+	}
+
+	if err := h.permissionUC.RevokeRole(c.Request().Context(), req.ID, user.ID); err != nil {
+		h.logger.Error("Revoke role failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
@@ -0,0 +1,121 @@
+package permission
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/model"
+	xuser "thomas.vn/apartment_service/internal/domain/model/user"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xcontext "thomas.vn/apartment_service/pkg/http/context"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	"thomas.vn/apartment_service/pkg/query"
+)
+
+// ResourceResolver extracts the ID of the resource a request targets.
+type ResourceResolver func(c echo.Context) (int64, error)
+
+// FromParam resolves the resource ID from a path parameter.
+func FromParam(name string) ResourceResolver {
+	return func(c echo.Context) (int64, error) {
+		return parseResourceID(name, c.Param(name))
+	}
+}
+
+// FromQuery resolves the resource ID from a query parameter.
+func FromQuery(name string) ResourceResolver {
+	return func(c echo.Context) (int64, error) {
+		return parseResourceID(name, c.QueryParam(name))
+	}
+}
+
+// FromFilter resolves the resource ID from the eq condition on field in the
+// filters query parameter, e.g. filters={"chatGroupID":1}. The filters are
+// parsed with the same schema as the list handler, so both read the same
+// value.
+func FromFilter(schema query.Schema, field string) ResourceResolver {
+	return func(c echo.Context) (int64, error) {
+		spec, err := schema.Parse(c.QueryParam("filters"), query.SortOptions{}, query.DateRangeOptions{}, query.PaginationOptions{})
+		if err != nil {
+			return 0, err
+		}
+
+		value, ok := spec.Value(field)
+		if !ok {
+			return 0, fmt.Errorf("filter %s is required", field)
+		}
+		return parseResourceID(field, fmt.Sprint(value))
+	}
+}
+
+type PolicyMiddleware struct {
+	logger   *xlogger.Logger
+	policyUC usecase.PolicyUsecase
+}
+
+func NewPolicyMiddleware(logger *xlogger.Logger, policyUC usecase.PolicyUsecase) *PolicyMiddleware {
+	return &PolicyMiddleware{logger: logger, policyUC: policyUC}
+}
+
+// Require allows the request when the authenticated user satisfies at
+// least one of the given policies for the resolved resource. It must run
+// after AuthMiddleware.Protect and PermissionsMiddleware.Check.
+func (m *PolicyMiddleware) Require(resolve ResourceResolver, policies ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, ok := c.Get(xcontext.UserContextKey).(*xuser.User)
+			if !ok || user == nil {
+				return xhttp.UnauthorizedResponse(c, "user not authenticated")
+			}
+
+			resourceID, err := resolve(c)
+			if err != nil {
+				return xhttp.BadRequestResponse(c, err.Error())
+			}
+
+			for _, policy := range policies {
+				req := model.PolicyRequest{
+					UserID:     user.ID,
+					RoleID:     user.RoleID,
+					Policy:     policy,
+					ResourceID: resourceID,
+				}
+
+				allowed, err := m.policyUC.Evaluate(c.Request().Context(), req)
+				if err != nil {
+					m.logger.Error(
+						"evaluate policy failed",
+						xlogger.Error(err),
+						xlogger.Int("user_id", user.ID),
+						xlogger.String("policy", policy),
+						xlogger.Int64("resource_id", resourceID),
+					)
+
+					return xhttp.NewAppError(
+						"ERR_INTERNAL_SERVER",
+						"",
+						"internal server error",
+						http.StatusInternalServerError,
+					)
+				}
+
+				if allowed {
+					return next(c)
+				}
+			}
+
+			return xhttp.ForbiddenResponse(c, "access denied")
+		}
+	}
+}
+
+func parseResourceID(name, value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return id, nil
+}
@@ -2,11 +2,18 @@ package root
 
 import (
 	"github.com/labstack/echo/v4"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	xchatmessage "thomas.vn/apartment_service/internal/domain/model/chatmessage"
 	handler2 "thomas.vn/apartment_service/internal/server/http/handler/ai"
 	"thomas.vn/apartment_service/internal/server/http/handler/articles"
+	"thomas.vn/apartment_service/internal/server/http/handler/audit"
 	xAuth "thomas.vn/apartment_service/internal/server/http/handler/auth"
 	"thomas.vn/apartment_service/internal/server/http/handler/chatgroup"
 	"thomas.vn/apartment_service/internal/server/http/handler/chatmessage"
+	"thomas.vn/apartment_service/internal/server/http/handler/mailoutbox"
+	"thomas.vn/apartment_service/internal/server/http/handler/mailtemplate"
+	"thomas.vn/apartment_service/internal/server/http/handler/moderation"
+	"thomas.vn/apartment_service/internal/server/http/handler/notification"
 	"thomas.vn/apartment_service/internal/server/http/handler/permission"
 	xtotp "thomas.vn/apartment_service/internal/server/http/handler/totp"
 	ws "thomas.vn/apartment_service/pkg/websocket"
@@ -23,12 +30,18 @@ type handler struct {
 	ai                   *handler2.Handler
 	authMiddleware       *xAuth.AuthMiddleware
 	permissionMiddleware *permission.PermissionsMiddleware
+	policyMiddleware     *permission.PolicyMiddleware
 	totp                 *xtotp.Handler
 	chatMessage          *chatmessage.Handler
 	chatGroup            *chatgroup.Handler
 	wsHandler            *ws.Handler
 	article              *articles.Handler
 	permission           *permission.Handler
+	audit                *audit.Handler
+	moderation           *moderation.Handler
+	notification         *notification.Handler
+	mailTemplate         *mailtemplate.Handler
+	mailOutbox           *mailoutbox.Handler
 }
 
 func NewHTTPHandler(
@@ -38,12 +51,18 @@ func NewHTTPHandler(
 	ai *handler2.Handler,
 	authMiddleware *xAuth.AuthMiddleware,
 	permissionMiddleware *permission.PermissionsMiddleware,
+	policyMiddleware *permission.PolicyMiddleware,
 	totp *xtotp.Handler,
 	chatMessage *chatmessage.Handler,
 	chatGroup *chatgroup.Handler,
 	wsHandler *ws.Handler,
 	article *articles.Handler,
 	permission *permission.Handler,
+	audit *audit.Handler,
+	moderation *moderation.Handler,
+	notification *notification.Handler,
+	mailTemplate *mailtemplate.Handler,
+	mailOutbox *mailoutbox.Handler,
 ) xhttp.Handler {
 	return &handler{
 		logger:               logger,
@@ -53,11 +72,17 @@ func NewHTTPHandler(
 		ai:                   ai,
 		authMiddleware:       authMiddleware,
 		permissionMiddleware: permissionMiddleware,
+		policyMiddleware:     policyMiddleware,
 		totp:                 totp,
 		chatGroup:            chatGroup,
 		wsHandler:            wsHandler,
 		article:              article,
 		permission:           permission,
+		audit:                audit,
+		moderation:           moderation,
+		notification:         notification,
+		mailTemplate:         mailTemplate,
+		mailOutbox:           mailOutbox,
 	}
 }
 
@@ -95,6 +120,18 @@ func (h *handler) RegisterRoutes(e *echo.Echo) {
 	//Permission routes
 	h.registerPermissionRoutes(api)
 
+	// Audit log routes
+	h.registerAuditRoutes(api)
+
+	// Moderation routes
+	h.registerModerationRoutes(api)
+
+	// Notification routes
+	h.registerNotificationRoutes(api)
+
+	// Mail routes
+	h.registerMailRoutes(api)
+
 	// WebSocket
 	e.GET("/ws", h.wsHandler.Handle())
 
@@ -108,10 +145,11 @@ func (h *handler) registerUserRoutes(e *echo.Group) {
 	users := e.Group("/users")
 	{
 		users.POST("", h.user.User().Create)
-		users.GET("/:id", h.user.User().Get)
-		users.PUT("/:id", h.user.User().Update)
-		users.DELETE("/:id", h.user.User().Delete)
-		users.GET("", h.user.User().List, h.authMiddleware.Protect)
+		users.GET("/:id", h.user.User().Get, h.authMiddleware.Protect, h.permissionMiddleware.Check, h.policyMiddleware.Require(permission.FromParam("id"), consts.PolicySelf))
+		users.PUT("/:id", h.user.User().Update, h.authMiddleware.Protect, h.permissionMiddleware.Check, h.policyMiddleware.Require(permission.FromParam("id"), consts.PolicySelf))
+		users.DELETE("/:id", h.user.User().Delete, h.authMiddleware.Protect, h.permissionMiddleware.Check, h.policyMiddleware.Require(permission.FromParam("id"), consts.PolicySelf))
+		users.POST("/:id/restore", h.user.User().Restore, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		users.GET("", h.user.User().List, h.authMiddleware.Protect, h.permissionMiddleware.Check)
 		users.POST("/avatar-local", h.user.User().UploadLocal, h.authMiddleware.Protect)
 		users.POST("/avatar-cloud", h.user.User().UploadCloud, h.authMiddleware.Protect)
 	}
@@ -151,7 +189,19 @@ func (h *handler) registerTotpRoutes(e *echo.Group) {
 func (h *handler) registerChatMessageRoutes(e *echo.Group) {
 	chatMessage := e.Group("/chat-message")
 	{
-		chatMessage.GET("", h.chatMessage.ChatMessage().List, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		chatMessage.GET("", h.chatMessage.ChatMessage().List, h.authMiddleware.Protect, h.permissionMiddleware.Check, h.policyMiddleware.Require(permission.FromFilter(xchatmessage.ChatMessageQuerySchema, "chatGroupID"), consts.PolicyChatGroupMember))
+		// Sending and message changes are authorized per group (member, sender or admin) in the usecase.
+		chatMessage.POST("", h.chatMessage.ChatMessage().Send, h.authMiddleware.Protect)
+		chatMessage.PUT("/:id", h.chatMessage.ChatMessage().Edit, h.authMiddleware.Protect)
+		chatMessage.DELETE("/:id", h.chatMessage.ChatMessage().Delete, h.authMiddleware.Protect)
+		chatMessage.POST("/:id/reactions", h.chatMessage.ChatMessage().React, h.authMiddleware.Protect)
+		chatMessage.GET("/:id/edits", h.chatMessage.ChatMessage().Edits, h.authMiddleware.Protect)
+		chatMessage.POST("/:id/report", h.moderation.Moderation().ReportMessage, h.authMiddleware.Protect)
+		chatMessage.POST("/attachments", h.chatMessage.ChatMessage().UploadAttachment, h.authMiddleware.Protect)
+		chatMessage.GET("/attachments/:id/download", h.chatMessage.ChatMessage().DownloadAttachment, h.authMiddleware.Protect)
+		// Search is limited to the caller's chat groups in the usecase.
+		chatMessage.GET("/search", h.chatMessage.ChatMessage().Search, h.authMiddleware.Protect)
+		chatMessage.POST("/search/reindex", h.chatMessage.ChatMessage().Reindex, h.authMiddleware.Protect, h.permissionMiddleware.Check)
 	}
 }
 
@@ -159,14 +209,70 @@ func (h *handler) registerChatGroupRoutes(e *echo.Group) {
 	chatGroup := e.Group("/chat-group")
 	{
 		chatGroup.GET("", h.chatGroup.ChatGroup().List, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		chatGroup.POST("", h.chatGroup.ChatGroup().Create, h.authMiddleware.Protect)
+		// Group management is authorized per group (admin role) in the usecase.
+		chatGroup.PUT("/:id", h.chatGroup.ChatGroup().Rename, h.authMiddleware.Protect)
+		chatGroup.DELETE("/:id", h.chatGroup.ChatGroup().Delete, h.authMiddleware.Protect)
+		chatGroup.POST("/:id/leave", h.chatGroup.ChatGroup().Leave, h.authMiddleware.Protect)
+		chatGroup.POST("/:id/read", h.chatGroup.ChatGroup().MarkRead, h.authMiddleware.Protect)
+		chatGroup.PUT("/:id/mute", h.chatGroup.ChatGroup().Mute, h.authMiddleware.Protect)
+		chatGroup.GET("/:id/presence", h.chatGroup.ChatGroup().Presence, h.authMiddleware.Protect)
+		chatGroup.POST("/:id/members", h.chatGroup.ChatGroup().AddMembers, h.authMiddleware.Protect)
+		chatGroup.DELETE("/:id/members/:userId", h.chatGroup.ChatGroup().RemoveMember, h.authMiddleware.Protect)
+		chatGroup.PUT("/:id/members/:userId/role", h.chatGroup.ChatGroup().UpdateMemberRole, h.authMiddleware.Protect)
+	}
+}
+
+func (h *handler) registerModerationRoutes(e *echo.Group) {
+	moderation := e.Group("/moderation")
+	{
+		moderation.GET("/blocks", h.moderation.Moderation().ListBlocks, h.authMiddleware.Protect)
+		moderation.POST("/blocks", h.moderation.Moderation().Block, h.authMiddleware.Protect)
+		moderation.DELETE("/blocks/:userId", h.moderation.Moderation().Unblock, h.authMiddleware.Protect)
+		// The report queue is for moderators only.
+		moderation.GET("/reports", h.moderation.Moderation().ListReports, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		moderation.POST("/reports/:id/review", h.moderation.Moderation().ReviewReport, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+	}
+}
+
+func (h *handler) registerNotificationRoutes(e *echo.Group) {
+	notifications := e.Group("/notifications")
+	{
+		notifications.GET("", h.notification.Notification().List, h.authMiddleware.Protect)
+		notifications.GET("/unread-count", h.notification.Notification().Badge, h.authMiddleware.Protect)
+		notifications.POST("/read-all", h.notification.Notification().MarkAllRead, h.authMiddleware.Protect)
+		notifications.POST("/:id/read", h.notification.Notification().MarkRead, h.authMiddleware.Protect)
+		notifications.GET("/preferences", h.notification.Preference().List, h.authMiddleware.Protect)
+		notifications.PUT("/preferences", h.notification.Preference().Update, h.authMiddleware.Protect)
 	}
 }
 
+func (h *handler) registerMailRoutes(e *echo.Group) {
+	templates := e.Group("/mail/templates")
+	{
+		templates.GET("", h.mailTemplate.MailTemplate().List, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		templates.GET("/:name/preview", h.mailTemplate.MailTemplate().Preview, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+	}
+
+	outbox := e.Group("/mail/outbox")
+	{
+		outbox.GET("", h.mailOutbox.MailOutbox().List, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		outbox.GET("/:id", h.mailOutbox.MailOutbox().Get, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		outbox.POST("/:id/resend", h.mailOutbox.MailOutbox().Resend, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+	}
+
+	// Unsubscribe links are signed, so they work without a login.
+	e.GET("/mail/unsubscribe", h.notification.Preference().CheckUnsubscribe)
+	e.POST("/mail/unsubscribe", h.notification.Preference().Unsubscribe)
+}
+
 func (h *handler) registerArticleRoutes(e *echo.Group) {
 	article := e.Group("/article")
 	{
-		article.GET("/all", h.article.Articles().List, h.authMiddleware.Protect)
+		article.GET("/all", h.article.Articles().List, h.authMiddleware.Protect, h.permissionMiddleware.Scope)
 		article.GET("", h.article.Articles().List, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		article.DELETE("/:id", h.article.Articles().Delete, h.authMiddleware.Protect, h.permissionMiddleware.Check, h.policyMiddleware.Require(permission.FromParam("id"), consts.PolicyArticleOwner))
+		article.POST("/:id/restore", h.article.Articles().Restore, h.authMiddleware.Protect, h.permissionMiddleware.Check, h.policyMiddleware.Require(permission.FromParam("id"), consts.PolicyArticleOwner))
 	}
 }
 
@@ -176,5 +282,15 @@ func (h *handler) registerPermissionRoutes(e *echo.Group) {
 		permissions.GET("/:id", h.permission.Permission().Get, h.authMiddleware.Protect, h.permissionMiddleware.Check)
 		permissions.PUT("/:id", h.permission.Permission().Update, h.authMiddleware.Protect, h.permissionMiddleware.Check)
 		permissions.POST("", h.permission.Permission().Create, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		permissions.POST("/assignments", h.permission.Permission().AssignRole, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		permissions.DELETE("/assignments/:id", h.permission.Permission().RevokeRole, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+	}
+}
+
+func (h *handler) registerAuditRoutes(e *echo.Group) {
+	auditLogs := e.Group("/audit-logs")
+	{
+		auditLogs.GET("", h.audit.Audit().List, h.authMiddleware.Protect, h.permissionMiddleware.Check)
+		auditLogs.GET("/export", h.audit.Audit().Export, h.authMiddleware.Protect, h.permissionMiddleware.Check)
 	}
 }
@@ -1,9 +1,7 @@
 package xuser
 
 import (
-	"encoding/json"
 	"net/http"
-	"net/url"
 
 	"github.com/labstack/echo/v4"
 	xuser "thomas.vn/apartment_service/internal/domain/model/user"
@@ -80,7 +78,7 @@ func (h *UserHandler) Get(c echo.Context) error {
 
 // Update godoc
 // @Summary Update user
-// @Description Update user by ID
+// @Description Update user by ID. Only admins may change role_id and is_active.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -88,6 +86,7 @@ func (h *UserHandler) Get(c echo.Context) error {
 // @Param data body xuser.UpdateUserRequest true "Update user request"
 // @Success 200 {object} xhttp.APIResponse{data=xuser.User}
 // @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 403 {object} xhttp.APIResponse{}
 // @Failure 500 {object} xhttp.APIResponse500Err{}
 // @Router /api/users/{id} [put]
 func (h *UserHandler) Update(c echo.Context) error {
@@ -96,6 +95,12 @@ func (h *UserHandler) Update(c echo.Context) error {
 		return xhttp.BadRequestResponse(c, err)
 	}
 
+	caller, err := xcontext.MustGetUser(c)
+	if err != nil {
+		return xhttp.UnauthorizedResponse(c, err)
+	}
+	req.CallerRoleID = caller.RoleID
+
 	res, err := h.userUC.UpdateUser(c.Request().Context(), &req)
 	if err != nil {
 		h.logger.Error("Update user failed", xlogger.Error(err))
@@ -130,6 +135,31 @@ func (h *UserHandler) Delete(c echo.Context) error {
 	return xhttp.SuccessResponse(c, nil)
 }
 
+// Restore godoc
+// @Summary Restore user
+// @Description Restore a soft deleted user by ID
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} xhttp.APIResponse{}
+// @Failure 400 {object} xhttp.APIResponse400Err{}
+// @Failure 500 {object} xhttp.APIResponse500Err{}
+// @Router /api/users/{id}/restore [post]
+func (h *UserHandler) Restore(c echo.Context) error {
+	var req xuser.UserIDRequest
+	if err := xhttp.ReadAndValidateRequest(c, &req); err != nil {
+		return xhttp.BadRequestResponse(c, err)
+	}
+
+	err := h.userUC.RestoreUser(c.Request().Context(), req.ID)
+	if err != nil {
+		h.logger.Error("Restore user failed", xlogger.Error(err))
+		return xhttp.AppErrorResponse(c, err)
+	}
+
+	return xhttp.SuccessResponse(c, nil)
+}
+
 // List godoc
 // @Summary List users
 // @Description List users with pagination and filters
@@ -138,9 +168,10 @@ func (h *UserHandler) Delete(c echo.Context) error {
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Limit per page"
+// @Param cursor query string false "Opaque cursor from nextCursor, replaces page"
 // @Param sort_by query string false "Sort by field"
 // @Param order_by query string false "Order by asc/desc"
-// @Param filters query string false "JSON encoded filters"
+// @Param filters query string false "JSON encoded filters, example: {\"full_name\":{\"like\":\"john\"},\"role_id\":{\"in\":[1,2]}}"
 // @Success 200 {object} xhttp.APIResponse{data=[]xuser.User}
 // @Failure 400 {object} xhttp.APIResponse400Err{}
 // @Failure 500 {object} xhttp.APIResponse500Err{}
@@ -151,28 +182,20 @@ func (h *UserHandler) List(c echo.Context) error {
 		return xhttp.BadRequestResponse(c, err)
 	}
 
-	var filters xuser.UserFilters
-
-	if req.Filters != "" && req.Filters != "undefined" {
-		decoded, err := url.QueryUnescape(req.Filters)
-		if err != nil {
-			return xhttp.BadRequestResponse(c, err)
-		}
-
-		if err := json.Unmarshal([]byte(decoded), &filters); err != nil {
-			return xhttp.BadRequestResponse(c, err)
-		}
+	spec, err := xuser.UserQuerySchema.Parse(req.Filters, req.SortOptions, req.DateRangeOptions, req.PaginationOptions)
+	if err != nil {
+		return xhttp.BadRequestResponse(c, err.Error())
 	}
 
 	res, total, err := h.userUC.ListUsers(
 		c.Request().Context(),
 		&req,
-		&filters,
+		spec,
 	)
 	if err != nil {
 		return xhttp.AppErrorResponse(c, err)
 	}
-	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total)
+	return xhttp.PaginationListResponse(c, &req.PaginationOptions, res, total, xhttp.WithNextCursor(spec.NextCursor(res, req.PaginationOptions)))
 }
 
 // UploadLocal godoc
@@ -50,11 +50,17 @@ func (j *DeleteCloudinaryAssetJob) Handle(
 		)
 	}
 
+	resourceType := req.ResourceType
+	if resourceType == "" {
+		resourceType = "image"
+	}
+
 	_, err := j.cld.Upload.Destroy(
 		ctx,
 		uploader.DestroyParams{
 			PublicID:     req.PublicID,
-			ResourceType: "image",
+			ResourceType: resourceType,
+			Type:         req.DeliveryType,
 		},
 	)
 
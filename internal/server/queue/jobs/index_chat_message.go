@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xqueue "thomas.vn/apartment_service/pkg/queue"
+)
+
+// IndexChatMessageJob keeps the search index in sync with a sent, edited or
+// deleted chat message.
+type IndexChatMessageJob struct {
+	logger        *xlogger.Logger
+	chatMessageUC usecase.ChatMessageUsecase
+}
+
+func NewIndexChatMessageJob(
+	logger *xlogger.Logger,
+	chatMessageUC usecase.ChatMessageUsecase,
+) *IndexChatMessageJob {
+	return &IndexChatMessageJob{
+		logger:        logger,
+		chatMessageUC: chatMessageUC,
+	}
+}
+
+func (j *IndexChatMessageJob) Name() string {
+	return consts.IndexChatMessageJobName
+}
+
+func (j *IndexChatMessageJob) Type() xqueue.MessageType {
+	return consts.IndexChatMessageJobType
+}
+
+func (j *IndexChatMessageJob) Handle(ctx context.Context, payload interface{}) error {
+	req, ok := payload.(*chatmessage.IndexQueuePayload)
+	if !ok {
+		return xhttp.NewAppError(
+			"ERR_INVALID_PAYLOAD",
+			"chat_message",
+			"invalid payload",
+			http.StatusBadRequest,
+		)
+	}
+
+	if err := j.chatMessageUC.SyncSearchIndex(ctx, req.ChatMessageID); err != nil {
+		j.logger.Error(
+			"Index chat message failed",
+			xlogger.Error(err),
+			xlogger.Int("chat_message_id", req.ChatMessageID),
+		)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xhttp "thomas.vn/apartment_service/pkg/http"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xqueue "thomas.vn/apartment_service/pkg/queue"
+)
+
+type UploadChatAttachmentJob struct {
+	logger        *xlogger.Logger
+	storage       service.AttachmentStorage
+	chatMessageUC usecase.ChatMessageUsecase
+}
+
+func NewUploadChatAttachmentJob(
+	logger *xlogger.Logger,
+	storage service.AttachmentStorage,
+	chatMessageUC usecase.ChatMessageUsecase,
+) *UploadChatAttachmentJob {
+	return &UploadChatAttachmentJob{
+		logger:        logger,
+		storage:       storage,
+		chatMessageUC: chatMessageUC,
+	}
+}
+
+func (j *UploadChatAttachmentJob) Name() string {
+	return consts.UploadChatAttachmentJobName
+}
+
+func (j *UploadChatAttachmentJob) Type() xqueue.MessageType {
+	return consts.UploadChatAttachmentJobType
+}
+
+func (j *UploadChatAttachmentJob) Handle(ctx context.Context, payload interface{}) error {
+	req, ok := payload.(*chatmessage.UploadAttachmentQueuePayload)
+	if !ok {
+		return xhttp.NewAppError(
+			"ERR_INVALID_PAYLOAD",
+			"attachment",
+			"invalid payload",
+			http.StatusBadRequest,
+		)
+	}
+
+	stored, err := j.storage.Upload(ctx, req.File, fmt.Sprintf("chat/%d", req.ChatGroupID), req.Image)
+	if err != nil {
+		j.logger.Error(
+			"Upload chat attachment failed",
+			xlogger.Error(err),
+			xlogger.Int("attachment_id", req.AttachmentID),
+		)
+		return err
+	}
+
+	return j.chatMessageUC.ProcessAttachmentUpload(ctx, req.AttachmentID, stored)
+}
@@ -3,24 +3,68 @@ package usecase
 import (
 	"context"
 
+	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model"
 	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
 	"thomas.vn/apartment_service/internal/domain/usecase"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	"thomas.vn/apartment_service/pkg/query"
 )
 
 type articlesUsecase struct {
 	logger            *xlogger.Logger
 	articleRepository repository.ArticlesRepository
+	auditSvc          service.AuditService
 }
 
-func NewArticlesUsecase(logger *xlogger.Logger, articleRepository repository.ArticlesRepository) usecase.ArticlesUsecase {
+func NewArticlesUsecase(logger *xlogger.Logger, articleRepository repository.ArticlesRepository, auditSvc service.AuditService) usecase.ArticlesUsecase {
 	return &articlesUsecase{
 		logger:            logger,
 		articleRepository: articleRepository,
+		auditSvc:          auditSvc,
 	}
 }
 
-func (u *articlesUsecase) ListArticles(ctx context.Context, req *model.ListArticleRequest, filters *model.ArticlesFilters) ([]*model.Articles, int64, error) {
-	return u.articleRepository.ListArticles(ctx, req, filters)
+func (u *articlesUsecase) ListArticles(ctx context.Context, req *model.ListArticleRequest, spec *query.Spec) ([]*model.Articles, int64, error) {
+	return u.articleRepository.ListArticles(ctx, req, spec)
+}
+
+func (u *articlesUsecase) DeleteArticle(ctx context.Context, id int64) error {
+	deleted, err := u.articleRepository.DeleteArticle(ctx, id)
+	if err != nil {
+		u.logger.Error("Failed to delete article", xlogger.Error(err))
+		return err
+	}
+	if !deleted {
+		return apperror.NotFound("Article with ID %d not found", id)
+	}
+
+	u.recordAudit(ctx, consts.AuditActionArticleDelete, id)
+	return nil
+}
+
+func (u *articlesUsecase) RestoreArticle(ctx context.Context, id int64) error {
+	restored, err := u.articleRepository.RestoreArticle(ctx, id)
+	if err != nil {
+		u.logger.Error("Failed to restore article", xlogger.Error(err))
+		return err
+	}
+	if !restored {
+		return apperror.NotFound("Deleted article with ID %d not found", id)
+	}
+
+	u.recordAudit(ctx, consts.AuditActionArticleRestore, id)
+	return nil
+}
+
+func (u *articlesUsecase) recordAudit(ctx context.Context, action string, id int64) {
+	if err := u.auditSvc.Record(ctx, model.AuditEntry{
+		Action:     action,
+		TargetType: consts.AuditTargetArticle,
+		TargetID:   id,
+	}); err != nil {
+		u.logger.Warn("Record audit log failed", xlogger.Error(err))
+	}
 }
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xrequestinfo "thomas.vn/apartment_service/pkg/requestinfo"
+)
+
+// redactedAuditFields are never written to the audit log in clear text.
+var redactedAuditFields = map[string]bool{
+	"password":    true,
+	"totp_secret": true,
+}
+
+// ignoredAuditFields change on every write and carry no audit value.
+var ignoredAuditFields = map[string]bool{
+	"updated_at": true,
+}
+
+type auditService struct {
+	logger *xlogger.Logger
+	repo   repository.AuditRepository
+}
+
+func NewAuditService(logger *xlogger.Logger, repo repository.AuditRepository) service.AuditService {
+	return &auditService{logger: logger, repo: repo}
+}
+
+func (s *auditService) Record(ctx context.Context, entry model.AuditEntry) error {
+	changes, err := diffAuditSnapshots(entry.Before, entry.After)
+	if err != nil {
+		s.logger.Error("Build audit diff failed", xlogger.Error(err))
+		return err
+	}
+
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	info := xrequestinfo.FromContext(ctx)
+	return s.repo.CreateAuditLog(ctx, &model.AuditLog{
+		ActorID:    info.ActorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Changes:    string(encoded),
+		IP:         info.IP,
+		UserAgent:  info.UserAgent,
+		RequestID:  info.RequestID,
+	})
+}
+
+// diffAuditSnapshots returns the fields whose JSON representation differs
+// between before and after. A nil snapshot is treated as an empty object.
+func diffAuditSnapshots(before, after interface{}) (map[string]model.AuditChange, error) {
+	beforeFields, err := auditSnapshotFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditSnapshotFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]model.AuditChange)
+	for _, fields := range []map[string]interface{}{beforeFields, afterFields} {
+		for key := range fields {
+			if ignoredAuditFields[key] {
+				continue
+			}
+			if _, seen := changes[key]; seen {
+				continue
+			}
+			oldValue, newValue := beforeFields[key], afterFields[key]
+			if reflect.DeepEqual(oldValue, newValue) {
+				continue
+			}
+			if redactedAuditFields[key] {
+				oldValue, newValue = redactAuditValue(oldValue), redactAuditValue(newValue)
+			}
+			changes[key] = model.AuditChange{Before: oldValue, After: newValue}
+		}
+	}
+
+	return changes, nil
+}
+
+func auditSnapshotFields(snapshot interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if snapshot == nil || reflect.ValueOf(snapshot).Kind() == reflect.Ptr && reflect.ValueOf(snapshot).IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func redactAuditValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return "[REDACTED]"
+}
+
+type auditUsecase struct {
+	logger *xlogger.Logger
+	repo   repository.AuditRepository
+}
+
+func NewAuditUsecase(logger *xlogger.Logger, repo repository.AuditRepository) usecase.AuditUsecase {
+	return &auditUsecase{logger: logger, repo: repo}
+}
+
+func (u *auditUsecase) ListAuditLogs(ctx context.Context, req *model.ListAuditLogRequest) ([]*model.AuditLog, int64, error) {
+	return u.repo.ListAuditLogs(ctx, req)
+}
+
+func (u *auditUsecase) ExportAuditLogs(ctx context.Context, req *model.ListAuditLogRequest) ([]*model.AuditLog, error) {
+	exportReq := *req
+	exportReq.Page = 1
+	exportReq.Limit = consts.AuditExportLimit
+	exportReq.ExcludeTotal = true
+
+	logs, _, err := u.repo.ListAuditLogs(ctx, &exportReq)
+	if err != nil {
+		u.logger.Error("Export audit logs failed", xlogger.Error(err))
+		return nil, err
+	}
+	return logs, nil
+}
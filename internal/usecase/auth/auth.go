@@ -19,28 +19,32 @@ import (
 )
 
 type authUsecase struct {
-	logger       *xlogger.Logger
-	userRepo     repository.UserRepository
-	tokenUc      usecase.TokenUsecase
-	queueService service.QueueService
+	logger     *xlogger.Logger
+	userRepo   repository.UserRepository
+	tokenUc    usecase.TokenUsecase
+	txManager  repository.ITransaction
+	mailOutbox service.MailOutbox
+	notifySvc  service.NotificationService
 }
 
-func NewAuthUsecase(logger *xlogger.Logger, userRepo repository.UserRepository, tokenUc usecase.TokenUsecase, queueService service.QueueService) usecase.AuthUsecase {
+func NewAuthUsecase(logger *xlogger.Logger, userRepo repository.UserRepository, tokenUc usecase.TokenUsecase, txManager repository.ITransaction, mailOutbox service.MailOutbox, notifySvc service.NotificationService) usecase.AuthUsecase {
 	return &authUsecase{
-		logger:       logger,
-		userRepo:     userRepo,
-		tokenUc:      tokenUc,
-		queueService: queueService,
+		logger:     logger,
+		userRepo:   userRepo,
+		tokenUc:    tokenUc,
+		txManager:  txManager,
+		mailOutbox: mailOutbox,
+		notifySvc:  notifySvc,
 	}
 }
 func (u *authUsecase) Register(ctx context.Context, req *xuser.CreateUserRequest) (*xuser.User, error) {
 
-	user, err := u.userRepo.GetUserByEmail(ctx, req.Email)
+	exists, err := u.userRepo.EmailExists(ctx, req.Email)
 	if err != nil {
 		return nil, err
 	}
 
-	if user != nil {
+	if exists {
 		return nil, apperror.Conflict("ERR_EMAIL_EXISTS", "email", "Email already exists")
 	}
 
@@ -55,20 +59,40 @@ func (u *authUsecase) Register(ctx context.Context, req *xuser.CreateUserRequest
 		FullName:  req.FullName,
 		IsActive:  consts.UserStatusActive,
 		RoleID:    consts.DefaultUserRoleID,
+		Locale:    req.Locale,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	_ = u.queueService.PublishMessage(
-		ctx,
-		consts.MailJobType,
-		&model.MailPayload{
-			Type:     consts.QueueMailRegister,
-			Email:    req.Email,
-			FullName: req.FullName,
-		},
-	)
 
-	return u.userRepo.CreateUser(ctx, newUser)
+	// The welcome mail is recorded with the user, so neither exists without
+	// the other.
+	tx, err := u.txManager.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	createdUser, err := u.userRepo.CreateUserTx(ctx, tx, newUser)
+	if err != nil {
+		_ = u.txManager.Rollback(ctx, tx)
+		return nil, err
+	}
+
+	err = u.mailOutbox.Enqueue(ctx, tx, &model.MailPayload{
+		Template: consts.MailTemplateRegister,
+		Email:    req.Email,
+		FullName: req.FullName,
+		Locale:   req.Locale,
+	})
+	if err != nil {
+		_ = u.txManager.Rollback(ctx, tx)
+		return nil, err
+	}
+
+	if err := u.txManager.Commit(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	return createdUser, nil
 }
 
 func (u *authUsecase) Login(ctx context.Context, email string, password string, totpToken *string) (*xauth.AuthLoginResult, error) {
@@ -103,15 +127,18 @@ func (u *authUsecase) Login(ctx context.Context, email string, password string,
 			return nil, apperror.BadRequest("Invalid totp token")
 		}
 	}
-	_ = u.queueService.PublishMessage(
-		ctx,
-		consts.MailJobType,
-		&model.MailPayload{
-			Type:     consts.QueueMailLogin,
-			Email:    user.Email,
-			FullName: user.FullName,
-		},
-	)
+	err = u.mailOutbox.Enqueue(ctx, nil, &model.MailPayload{
+		Template: consts.MailTemplateLogin,
+		Email:    user.Email,
+		FullName: user.FullName,
+		Locale:   user.Locale,
+		UserID:   user.ID,
+		Category: consts.NotificationCategorySecurity,
+	})
+	if err != nil {
+		u.logger.Warn("Enqueue login mail failed", xlogger.Error(err), xlogger.Int("user_id", user.ID))
+	}
+	u.notifyLogin(ctx, user.ID)
 
 	return &xauth.AuthLoginResult{
 		AccessToken:  accessToken,
@@ -181,3 +208,18 @@ func (u *authUsecase) GetInfo(_ context.Context, user *xuser.User) (*xauth.AuthI
 		FullName: user.FullName,
 	}, nil
 }
+
+// notifyLogin leaves a login alert in the user's inbox next to the alert mail.
+func (u *authUsecase) notifyLogin(ctx context.Context, userID int) {
+	entry := model.NotificationEntry{
+		UserID:   userID,
+		Type:     consts.NotificationTypeLoginAlert,
+		Category: consts.NotificationCategorySecurity,
+		Title:    "New sign-in to your account",
+		Body:     fmt.Sprintf("Your account was signed in at %s.", time.Now().Format(time.RFC1123)),
+		Link:     "/account/security",
+	}
+	if err := u.notifySvc.Notify(ctx, entry); err != nil {
+		u.logger.Warn("Notify login failed", xlogger.Error(err), xlogger.Int("user_id", userID))
+	}
+}
@@ -23,6 +23,15 @@ func (u *authUsecase) GoogleLogin(ctx context.Context, gUser *model.GoogleUser)
 	googleID := gUser.GoogleID
 
 	if user == nil {
+		// A soft-deleted account still holds the email.
+		exists, err := u.userRepo.EmailExists(ctx, gUser.Email)
+		if err != nil {
+			return "", "", err
+		}
+		if exists {
+			return "", "", apperror.Conflict("ERR_EMAIL_EXISTS", "email", "Email already exists")
+		}
+
 		user = &xuser.User{
 			RoleID:    consts.DefaultUserRoleID,
 			Email:     gUser.Email,
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"thomas.vn/apartment_service/internal/config"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/model/chatgroup"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+// digestBatchSize caps the member groups handled per run. Runs page through
+// the candidates with a cursor, so members skipped for being online do not
+// hold back the members after them.
+const digestBatchSize = 500
+
+type chatDigestUsecase struct {
+	logger          *xlogger.Logger
+	cfg             config.ChatDigestConfig
+	chatGroupRepo   repository.ChatGroupRepository
+	chatMessageRepo repository.ChatMessageRepository
+	presence        service.PresenceService
+	mailOutbox      service.MailOutbox
+
+	mu     sync.Mutex
+	cursor chatgroup.DigestCursor
+}
+
+func NewChatDigestUsecase(
+	logger *xlogger.Logger,
+	cfg config.ChatDigestConfig,
+	chatGroupRepo repository.ChatGroupRepository,
+	chatMessageRepo repository.ChatMessageRepository,
+	presence service.PresenceService,
+	mailOutbox service.MailOutbox,
+) usecase.ChatDigestUsecase {
+	return &chatDigestUsecase{
+		logger:          logger,
+		cfg:             cfg,
+		chatGroupRepo:   chatGroupRepo,
+		chatMessageRepo: chatMessageRepo,
+		presence:        presence,
+		mailOutbox:      mailOutbox,
+	}
+}
+
+// SendDigests mails every offline member the unread messages of the chat
+// groups that stayed quiet for the configured period. Mailed messages are
+// remembered per member, so each message is mailed at most once.
+func (u *chatDigestUsecase) SendDigests(ctx context.Context) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := xutils.GetTimeNow()
+	quietBefore := now.Add(-time.Duration(u.cfg.QuietMinutes) * time.Minute)
+
+	rows, err := u.chatGroupRepo.ListDigestCandidates(ctx, now, quietBefore, u.cursor, digestBatchSize)
+	if err != nil {
+		return err
+	}
+	next := chatgroup.DigestCursor{}
+	if len(rows) == digestBatchSize {
+		rows = holdBackLastMember(rows)
+		last := rows[len(rows)-1]
+		next = chatgroup.DigestCursor{UserID: last.UserID, ChatGroupID: last.ChatGroupID}
+	}
+	if len(rows) == 0 {
+		u.cursor = next
+		return nil
+	}
+
+	byUser := make(map[int64][]*chatgroup.DigestRow)
+	userIDs := make([]int64, 0)
+	for _, row := range rows {
+		if _, ok := byUser[row.UserID]; !ok {
+			userIDs = append(userIDs, row.UserID)
+		}
+		byUser[row.UserID] = append(byUser[row.UserID], row)
+	}
+
+	presences, err := u.presence.UserPresence(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	u.cursor = next
+
+	for _, p := range presences {
+		if p.Online {
+			continue
+		}
+
+		if err := u.sendDigest(ctx, byUser[p.UserID]); err != nil {
+			u.logger.Warn(
+				"Send chat digest failed",
+				xlogger.Int64("user_id", p.UserID),
+				xlogger.Error(err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// holdBackLastMember drops the rows of the last member in a full batch, whose
+// remaining groups may be cut off, so the member gets one digest covering
+// all groups in the next run. A batch with a single member is kept whole.
+func holdBackLastMember(rows []*chatgroup.DigestRow) []*chatgroup.DigestRow {
+	lastUserID := rows[len(rows)-1].UserID
+	for i := len(rows) - 1; i >= 0; i-- {
+		if rows[i].UserID != lastUserID {
+			return rows[:i+1]
+		}
+	}
+	return rows
+}
+
+func (u *chatDigestUsecase) sendDigest(ctx context.Context, rows []*chatgroup.DigestRow) error {
+	if len(rows) == 0 || rows[0].Email == "" {
+		return nil
+	}
+
+	digest := &model.ChatDigest{Groups: make([]model.ChatDigestGroup, 0, len(rows))}
+	for _, row := range rows {
+		msgs, err := u.chatMessageRepo.ListUnreadPreview(ctx, int(row.ChatGroupID), int(row.UserID), row.AfterMessageID, u.cfg.MaxMessages)
+		if err != nil {
+			return err
+		}
+
+		group := model.ChatDigestGroup{
+			ChatGroupID: row.ChatGroupID,
+			Name:        row.GroupName,
+			UnreadCount: row.UnreadCount,
+			Messages:    make([]model.ChatDigestMessage, 0, len(msgs)),
+		}
+		for _, m := range msgs {
+			group.Messages = append(group.Messages, model.ChatDigestMessage{
+				Sender: m.FullName,
+				Text:   m.MessageText,
+				SentAt: m.CreatedAt,
+			})
+		}
+		digest.Groups = append(digest.Groups, group)
+	}
+
+	err := u.mailOutbox.Enqueue(ctx, nil, &model.MailPayload{
+		Template:   consts.MailTemplateChatDigest,
+		Email:      rows[0].Email,
+		FullName:   rows[0].FullName,
+		Locale:     rows[0].Locale,
+		UserID:     int(rows[0].UserID),
+		Category:   consts.NotificationCategoryChat,
+		ChatDigest: digest,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := u.chatGroupRepo.MarkNotified(ctx, row.ChatGroupID, row.UserID, row.LastMessageID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
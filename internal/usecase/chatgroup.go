@@ -2,33 +2,64 @@ package usecase
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"thomas.vn/apartment_service/internal/domain/apperror"
 	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
 	"thomas.vn/apartment_service/internal/domain/model/chatgroup"
+	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
 	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
 	"thomas.vn/apartment_service/internal/domain/usecase"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
 )
 
+// muteForever is stored for mutes without an end.
+var muteForever = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
 type ChatGroupUsecase struct {
 	logger              *xlogger.Logger
 	chatGroupRepositoty repository.ChatGroupRepository
+	chatMessageUC       usecase.ChatMessageUsecase
+	realtime            service.RealtimeService
+	presence            service.PresenceService
+	moderationRepo      repository.ModerationRepository
+	userRepo            repository.UserRepository
+	notifier            service.RealtimeNotifier
 }
 
-func NewChatGroupUsecase(logger *xlogger.Logger, chatGroupRepositoty repository.ChatGroupRepository) usecase.ChatGroupUsecase {
+func NewChatGroupUsecase(
+	logger *xlogger.Logger,
+	chatGroupRepositoty repository.ChatGroupRepository,
+	chatMessageUC usecase.ChatMessageUsecase,
+	realtime service.RealtimeService,
+	presence service.PresenceService,
+	moderationRepo repository.ModerationRepository,
+	userRepo repository.UserRepository,
+	notifier service.RealtimeNotifier,
+) usecase.ChatGroupUsecase {
 	return &ChatGroupUsecase{
 		logger:              logger,
 		chatGroupRepositoty: chatGroupRepositoty,
+		chatMessageUC:       chatMessageUC,
+		realtime:            realtime,
+		presence:            presence,
+		moderationRepo:      moderationRepo,
+		userRepo:            userRepo,
+		notifier:            notifier,
 	}
 }
 
 func (u *ChatGroupUsecase) ListChatGroups(
 	ctx context.Context,
+	userID int64,
 	req *chatgroup.ListChatGroupRequest,
 ) ([]*chatgroup.ListResponse, int64, error) {
 
-	return u.chatGroupRepositoty.ListChatGroupsWithMembers(ctx, req)
+	return u.chatGroupRepositoty.ListChatGroupsWithMembers(ctx, userID, req)
 }
 
 func (u *ChatGroupUsecase) CreateChatGroup(ctx context.Context, req *chatgroup.CreateChatGroupRequest) (*chatgroup.ChatGroup, error) {
@@ -40,14 +71,37 @@ func (u *ChatGroupUsecase) CreateChatGroup(ctx context.Context, req *chatgroup.C
 	userIDs := append(req.TargetUserIDs, req.OwnerID)
 	userIDs = uniqueInt64(userIDs)
 
+	buildingID, err := u.resolveBuilding(ctx, req.OwnerID, req.BuildingID)
+	if err != nil {
+		return nil, err
+	}
+
 	entity := &chatgroup.ChatGroup{
-		Name:      req.Name,
-		OwnerID:   req.OwnerID,
-		IsDeleted: consts.NotDeleted,
+		Name:       req.Name,
+		OwnerID:    req.OwnerID,
+		BuildingID: buildingID,
+		IsDeleted:  consts.NotDeleted,
 	}
 
 	// ================= CHAT 1–1 =================
 	if len(userIDs) == 2 {
+		blocked, err := u.moderationRepo.IsBlocked(ctx, int(userIDs[0]), int(userIDs[1]))
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, apperror.Forbidden("Chat between users %d and %d is blocked", userIDs[0], userIDs[1])
+		}
+
+		existing, err := u.chatGroupRepositoty.FindChatOneByUserIDs(ctx, userIDs)
+		if err != nil {
+			u.logger.Error("FindChatOneByUserIDs failed", xlogger.Error(err))
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+
 		keyObj, err := chatgroup.BuildChatOneKey(userIDs)
 		if err != nil {
 			return nil, err
@@ -62,16 +116,38 @@ func (u *ChatGroupUsecase) CreateChatGroup(ctx context.Context, req *chatgroup.C
 		return nil, err
 	}
 
-	memberReq := &chatgroup.CreateMemberRequest{
-		ChatGroupID: int64(createdGroup.ID),
-		UserIDs:     userIDs,
+	// The owner administers the group, everyone else joins as a member.
+	targetIDs := make([]int64, 0, len(userIDs))
+	for _, id := range userIDs {
+		if id != req.OwnerID {
+			targetIDs = append(targetIDs, id)
+		}
 	}
 
-	if err := u.chatGroupRepositoty.AddMembers(ctx, memberReq); err != nil {
-		u.logger.Error("AddMembers failed", xlogger.Error(err))
-		return nil, err
+	memberReqs := []*chatgroup.CreateMemberRequest{
+		{
+			ChatGroupID: int64(createdGroup.ID),
+			UserIDs:     []int64{req.OwnerID},
+			Role:        consts.ChatGroupRoleAdmin,
+		},
+		{
+			ChatGroupID: int64(createdGroup.ID),
+			UserIDs:     targetIDs,
+			Role:        consts.ChatGroupRoleMember,
+		},
+	}
+
+	for _, memberReq := range memberReqs {
+		if len(memberReq.UserIDs) == 0 {
+			continue
+		}
+		if err := u.chatGroupRepositoty.AddMembers(ctx, memberReq); err != nil {
+			u.logger.Error("AddMembers failed", xlogger.Error(err))
+			return nil, err
+		}
 	}
 
+	u.notifyJoined(ctx, createdGroup, targetIDs)
 	return createdGroup, nil
 }
 
@@ -87,3 +163,469 @@ func uniqueInt64(input []int64) []int64 {
 	}
 	return out
 }
+
+func (u *ChatGroupUsecase) EnsureMember(ctx context.Context, chatGroupID int64, userID int64) error {
+	ok, err := u.chatGroupRepositoty.IsMember(ctx, chatGroupID, userID)
+	if err != nil {
+		u.logger.Error("Check chat group member failed", xlogger.Error(err))
+		return err
+	}
+	if !ok {
+		return apperror.Forbidden("User %d is not a member of chat group %d", userID, chatGroupID)
+	}
+
+	return nil
+}
+
+func (u *ChatGroupUsecase) AddMembers(ctx context.Context, actorID int64, req *chatgroup.AddMembersRequest) error {
+	group, err := u.getMutableGroup(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.requireAdmin(ctx, group, actorID); err != nil {
+		return err
+	}
+
+	userIDs := make([]int64, 0, len(req.UserIDs))
+	for _, userID := range uniqueInt64(req.UserIDs) {
+		member, err := u.chatGroupRepositoty.GetMember(ctx, req.ID, userID)
+		if err != nil {
+			return err
+		}
+		if member == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return apperror.BadRequest("users are already members of chat group %d", req.ID)
+	}
+
+	memberReq := &chatgroup.CreateMemberRequest{
+		ChatGroupID: req.ID,
+		UserIDs:     userIDs,
+		Role:        consts.ChatGroupRoleMember,
+	}
+	if err := u.chatGroupRepositoty.AddMembers(ctx, memberReq); err != nil {
+		return err
+	}
+
+	u.announce(ctx, req.ID, &chatmessage.SystemEvent{
+		Event:   consts.ChatEventMembersAdded,
+		ActorID: actorID,
+		UserIDs: userIDs,
+	})
+	u.notifyJoined(ctx, group, userIDs)
+	return nil
+}
+
+// notifyJoined tells the users on all their connections that they were
+// added to the group.
+func (u *ChatGroupUsecase) notifyJoined(ctx context.Context, group *chatgroup.ChatGroup, userIDs []int64) {
+	for _, userID := range userIDs {
+		if err := u.notifier.NotifyUser(ctx, int(userID), consts.WSEventChatGroupJoined, group); err != nil {
+			u.logger.Warn("Notify chat group member failed", xlogger.Error(err), xlogger.Int64("user_id", userID))
+		}
+	}
+}
+
+func (u *ChatGroupUsecase) RemoveMember(ctx context.Context, actorID int64, req *chatgroup.MemberRequest) error {
+	group, err := u.getMutableGroup(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.requireAdmin(ctx, group, actorID); err != nil {
+		return err
+	}
+	if req.UserID == actorID {
+		return apperror.BadRequest("use leave to exit chat group %d", req.ID)
+	}
+	if req.UserID == group.OwnerID {
+		return apperror.Forbidden("the owner of chat group %d cannot be removed", req.ID)
+	}
+
+	member, err := u.getMember(ctx, req.ID, req.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.chatGroupRepositoty.RemoveMember(ctx, member.ID); err != nil {
+		return err
+	}
+
+	u.announce(ctx, req.ID, &chatmessage.SystemEvent{
+		Event:   consts.ChatEventMemberRemoved,
+		ActorID: actorID,
+		UserIDs: []int64{req.UserID},
+	})
+	u.evict(ctx, req.ID, req.UserID)
+	return nil
+}
+
+func (u *ChatGroupUsecase) UpdateMemberRole(ctx context.Context, actorID int64, req *chatgroup.UpdateMemberRoleRequest) error {
+	group, err := u.getMutableGroup(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.requireAdmin(ctx, group, actorID); err != nil {
+		return err
+	}
+	if req.UserID == group.OwnerID && req.Role != consts.ChatGroupRoleAdmin {
+		return apperror.Forbidden("the owner of chat group %d cannot be demoted", req.ID)
+	}
+
+	member, err := u.getMember(ctx, req.ID, req.UserID)
+	if err != nil {
+		return err
+	}
+	if member.Role == req.Role {
+		return nil
+	}
+	if err := u.chatGroupRepositoty.UpdateMemberRole(ctx, member.ID, req.Role); err != nil {
+		return err
+	}
+
+	u.announce(ctx, req.ID, &chatmessage.SystemEvent{
+		Event:   consts.ChatEventRoleChanged,
+		ActorID: actorID,
+		UserIDs: []int64{req.UserID},
+		Role:    req.Role,
+	})
+	return nil
+}
+
+func (u *ChatGroupUsecase) RenameChatGroup(ctx context.Context, actorID int64, req *chatgroup.RenameChatGroupRequest) error {
+	group, err := u.getGroup(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.requireAdmin(ctx, group, actorID); err != nil {
+		return err
+	}
+
+	if err := u.chatGroupRepositoty.RenameChatGroup(ctx, req.ID, req.Name); err != nil {
+		return err
+	}
+
+	u.announce(ctx, req.ID, &chatmessage.SystemEvent{
+		Event:   consts.ChatEventGroupRenamed,
+		ActorID: actorID,
+		Name:    req.Name,
+	})
+	return nil
+}
+
+// LeaveChatGroup removes the actor from the group. When the last admin
+// leaves, the oldest remaining member is promoted; when nobody is left the
+// group is deleted.
+func (u *ChatGroupUsecase) LeaveChatGroup(ctx context.Context, actorID int64, chatGroupID int64) error {
+	if _, err := u.getMutableGroup(ctx, chatGroupID); err != nil {
+		return err
+	}
+
+	member, err := u.chatGroupRepositoty.GetMember(ctx, chatGroupID, actorID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return apperror.Forbidden("User %d is not a member of chat group %d", actorID, chatGroupID)
+	}
+	if _, err := u.chatGroupRepositoty.RemoveMember(ctx, member.ID); err != nil {
+		return err
+	}
+
+	remaining, err := u.chatGroupRepositoty.ListMembers(ctx, chatGroupID)
+	if err != nil {
+		return err
+	}
+	if len(remaining) == 0 {
+		if _, err := u.chatGroupRepositoty.DeleteChatGroup(ctx, chatGroupID); err != nil {
+			return err
+		}
+		u.evict(ctx, chatGroupID, actorID)
+		return nil
+	}
+
+	u.announce(ctx, chatGroupID, &chatmessage.SystemEvent{
+		Event:   consts.ChatEventMemberLeft,
+		ActorID: actorID,
+		UserIDs: []int64{actorID},
+	})
+	u.evict(ctx, chatGroupID, actorID)
+
+	if member.Role != consts.ChatGroupRoleAdmin || hasAdmin(remaining) {
+		return nil
+	}
+
+	successor := remaining[0]
+	if err := u.chatGroupRepositoty.UpdateMemberRole(ctx, successor.ID, consts.ChatGroupRoleAdmin); err != nil {
+		return err
+	}
+
+	u.announce(ctx, chatGroupID, &chatmessage.SystemEvent{
+		Event:   consts.ChatEventRoleChanged,
+		ActorID: actorID,
+		UserIDs: []int64{successor.UserID},
+		Role:    consts.ChatGroupRoleAdmin,
+	})
+	return nil
+}
+
+func (u *ChatGroupUsecase) DeleteChatGroup(ctx context.Context, actorID int64, chatGroupID int64) error {
+	group, err := u.getGroup(ctx, chatGroupID)
+	if err != nil {
+		return err
+	}
+	if _, err := u.requireAdmin(ctx, group, actorID); err != nil {
+		return err
+	}
+
+	members, err := u.chatGroupRepositoty.ListMembers(ctx, chatGroupID)
+	if err != nil {
+		return err
+	}
+
+	// Announce first so the members still receive the event.
+	u.announce(ctx, chatGroupID, &chatmessage.SystemEvent{
+		Event:   consts.ChatEventGroupDeleted,
+		ActorID: actorID,
+	})
+
+	deleted, err := u.chatGroupRepositoty.DeleteChatGroup(ctx, chatGroupID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apperror.NotFound("Chat group %d not found", chatGroupID)
+	}
+
+	for _, m := range members {
+		u.evict(ctx, chatGroupID, m.UserID)
+	}
+	return nil
+}
+
+// MarkRead records how far the user has read and tells the room, so that
+// other members can show read receipts.
+func (u *ChatGroupUsecase) MarkRead(ctx context.Context, userID int64, req *chatgroup.MarkReadRequest) (*chatgroup.ReadReceipt, error) {
+	if err := u.EnsureMember(ctx, req.ID, userID); err != nil {
+		return nil, err
+	}
+
+	lastRead, err := u.chatGroupRepositoty.MarkRead(ctx, req.ID, userID, req.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	if lastRead < req.MessageID {
+		return nil, apperror.NotFound("Message %d not found in chat group %d", req.MessageID, req.ID)
+	}
+
+	receipt := &chatgroup.ReadReceipt{
+		ChatGroupID:       req.ID,
+		UserID:            userID,
+		LastReadMessageID: lastRead,
+	}
+
+	if err := u.realtime.BroadcastToRoom(ctx, int(req.ID), consts.WSEventMessageRead, receipt); err != nil {
+		u.logger.Warn("Broadcast read receipt failed", xlogger.Error(err), xlogger.Int64("chat_group_id", req.ID))
+	}
+
+	return receipt, nil
+}
+
+// AckDelivery records that a client of the user received the messages of
+// the group up to req.Seq. It returns the stored delivery marker.
+func (u *ChatGroupUsecase) AckDelivery(ctx context.Context, userID int64, req *chatgroup.AckRequest) (int64, error) {
+	if err := u.EnsureMember(ctx, req.ID, userID); err != nil {
+		return 0, err
+	}
+
+	delivered, err := u.chatGroupRepositoty.AckDelivery(ctx, req.ID, userID, req.Seq)
+	if err != nil {
+		return 0, err
+	}
+	if delivered < req.Seq {
+		return 0, apperror.BadRequest("Seq %d is beyond the last message of chat group %d", req.Seq, req.ID)
+	}
+
+	return delivered, nil
+}
+
+// EnsureNotBlocked refuses messages in a 1-1 chat when either user blocked
+// the other. Group chats are not affected by blocks.
+func (u *ChatGroupUsecase) EnsureNotBlocked(ctx context.Context, chatGroupID int64, userID int64) error {
+	group, err := u.chatGroupRepositoty.GetChatGroupByID(ctx, chatGroupID)
+	if err != nil {
+		return err
+	}
+	if group == nil || group.KeyForChatOne == nil {
+		return nil
+	}
+
+	for _, otherID := range group.KeyForChatOne.UserIDs {
+		if otherID == userID {
+			continue
+		}
+		blocked, err := u.moderationRepo.IsBlocked(ctx, int(userID), int(otherID))
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return apperror.Forbidden("Chat between users %d and %d is blocked", userID, otherID)
+		}
+	}
+
+	return nil
+}
+
+// SetMute mutes or unmutes the chat digest mails of a group for the user.
+func (u *ChatGroupUsecase) SetMute(ctx context.Context, userID int64, req *chatgroup.MuteRequest) (*chatgroup.MuteSetting, error) {
+	if err := u.EnsureMember(ctx, req.ID, userID); err != nil {
+		return nil, err
+	}
+
+	var until *time.Time
+	if req.Muted {
+		until = &muteForever
+		if req.Until != nil {
+			if !req.Until.After(xutils.GetTimeNow()) {
+				return nil, apperror.BadRequest("Mute end must be in the future")
+			}
+			until = req.Until
+		}
+	}
+
+	if err := u.chatGroupRepositoty.SetMute(ctx, req.ID, userID, until); err != nil {
+		return nil, err
+	}
+
+	return &chatgroup.MuteSetting{ChatGroupID: req.ID, MutedUntil: until}, nil
+}
+
+// ListMemberPresence returns the online state of the members of a group the
+// user belongs to.
+func (u *ChatGroupUsecase) ListMemberPresence(ctx context.Context, userID int64, chatGroupID int64) ([]*model.UserPresence, error) {
+	if err := u.EnsureMember(ctx, chatGroupID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := u.chatGroupRepositoty.ListMembers(ctx, chatGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(members))
+	for _, m := range members {
+		userIDs = append(userIDs, m.UserID)
+	}
+
+	presence, err := u.presence.UserPresence(ctx, userIDs)
+	if err != nil {
+		u.logger.Error("Get member presence failed", xlogger.Error(err), xlogger.Int64("chat_group_id", chatGroupID))
+		return nil, err
+	}
+
+	return presence, nil
+}
+
+func (u *ChatGroupUsecase) getGroup(ctx context.Context, chatGroupID int64) (*chatgroup.ChatGroup, error) {
+	group, err := u.chatGroupRepositoty.GetChatGroupByID(ctx, chatGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, apperror.NotFound("Chat group %d not found", chatGroupID)
+	}
+
+	return group, nil
+}
+
+// getMutableGroup loads a group whose membership can change, which
+// excludes 1-1 chats.
+func (u *ChatGroupUsecase) getMutableGroup(ctx context.Context, chatGroupID int64) (*chatgroup.ChatGroup, error) {
+	group, err := u.getGroup(ctx, chatGroupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.KeyForChatOne != nil {
+		return nil, apperror.BadRequest("members of 1-1 chat %d cannot be changed", chatGroupID)
+	}
+
+	return group, nil
+}
+
+func (u *ChatGroupUsecase) getMember(ctx context.Context, chatGroupID int64, userID int64) (*model.ChatGroupMembers, error) {
+	member, err := u.chatGroupRepositoty.GetMember(ctx, chatGroupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, apperror.NotFound("User %d is not a member of chat group %d", userID, chatGroupID)
+	}
+
+	return member, nil
+}
+
+func (u *ChatGroupUsecase) requireAdmin(ctx context.Context, group *chatgroup.ChatGroup, actorID int64) (*model.ChatGroupMembers, error) {
+	member, err := u.chatGroupRepositoty.GetMember(ctx, int64(group.ID), actorID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, apperror.Forbidden("User %d is not a member of chat group %d", actorID, group.ID)
+	}
+	if member.Role != consts.ChatGroupRoleAdmin {
+		return nil, apperror.Forbidden("User %d is not an admin of chat group %d", actorID, group.ID)
+	}
+
+	return member, nil
+}
+
+// announce records a system message for the change and pushes it to the
+// room. The change itself is already stored, so failures are only logged.
+func (u *ChatGroupUsecase) announce(ctx context.Context, chatGroupID int64, event *chatmessage.SystemEvent) {
+	msg, err := u.chatMessageUC.SendSystemMessage(ctx, int(chatGroupID), event)
+	if err != nil {
+		u.logger.Warn("Record chat system message failed", xlogger.Error(err), xlogger.String("event", event.Event))
+		return
+	}
+
+	if err := u.realtime.BroadcastToRoom(ctx, int(chatGroupID), consts.WSEventSendMessage, msg); err != nil {
+		u.logger.Warn("Broadcast chat system message failed", xlogger.Error(err), xlogger.String("event", event.Event))
+	}
+}
+
+// resolveBuilding returns the building a new group of the owner belongs to:
+// the requested one, which must be one of the owner's buildings, or else the
+// owner's only building. Owners without exactly one building create global
+// groups.
+func (u *ChatGroupUsecase) resolveBuilding(ctx context.Context, ownerID int64, requested int64) (int64, error) {
+	buildingIDs, err := u.userRepo.ListBuildingIDs(ctx, ownerID)
+	if err != nil {
+		return 0, err
+	}
+
+	if requested != consts.GlobalBuildingID {
+		if !slices.Contains(buildingIDs, requested) {
+			return 0, apperror.Forbidden("User %d is not in building %d", ownerID, requested)
+		}
+		return requested, nil
+	}
+	if len(buildingIDs) == 1 {
+		return buildingIDs[0], nil
+	}
+	return consts.GlobalBuildingID, nil
+}
+
+func (u *ChatGroupUsecase) evict(ctx context.Context, chatGroupID int64, userID int64) {
+	if err := u.realtime.EvictFromRoom(ctx, int(chatGroupID), int(userID)); err != nil {
+		u.logger.Warn("Evict user from chat room failed", xlogger.Error(err), xlogger.Int64("user_id", userID))
+	}
+}
+
+func hasAdmin(members []*model.ChatGroupMembers) bool {
+	for _, m := range members {
+		if m.Role == consts.ChatGroupRoleAdmin {
+			return true
+		}
+	}
+	return false
+}
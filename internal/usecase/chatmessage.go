@@ -2,53 +2,650 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
+	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
 	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
 	"thomas.vn/apartment_service/internal/domain/usecase"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	"thomas.vn/apartment_service/pkg/query"
 )
 
 type chatMessageUsecase struct {
 	logger                *xlogger.Logger
 	chatMessageRepository repository.ChatMessageRepository
+	chatGroupRepository   repository.ChatGroupRepository
+	searchRepository      repository.ChatMessageSearchRepository
+	realtime              service.RealtimeService
+	storage               service.AttachmentStorage
+	queue                 service.QueueService
 }
 
-func NewChatMessageUsecase(logger *xlogger.Logger, chatMessageRepository repository.ChatMessageRepository) usecase.ChatMessageUsecase {
+// replayBatchSize is the most messages sent for one RESUME.
+const replayBatchSize = 100
+
+// Page size limits of message search, and the batch size of reindexing.
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+	reindexBatchSize   = 500
+)
+
+// attachmentDownloadTTL bounds how long a download link handed out by
+// AttachmentDownloadURL stays valid.
+const attachmentDownloadTTL = 5 * time.Minute
+
+func NewChatMessageUsecase(
+	logger *xlogger.Logger,
+	chatMessageRepository repository.ChatMessageRepository,
+	chatGroupRepository repository.ChatGroupRepository,
+	searchRepository repository.ChatMessageSearchRepository,
+	realtime service.RealtimeService,
+	storage service.AttachmentStorage,
+	queue service.QueueService,
+) usecase.ChatMessageUsecase {
 	return &chatMessageUsecase{
 		logger:                logger,
 		chatMessageRepository: chatMessageRepository,
+		chatGroupRepository:   chatGroupRepository,
+		searchRepository:      searchRepository,
+		realtime:              realtime,
+		storage:               storage,
+		queue:                 queue,
 	}
 }
-func (u *chatMessageUsecase) ListChatMessages(ctx context.Context, req *chatmessage.ListChatMessageRequest) ([]*chatmessage.Response, int64, error) {
+func (u *chatMessageUsecase) ListChatMessages(ctx context.Context, req *chatmessage.ListChatMessageRequest, spec *query.Spec) ([]*chatmessage.Response, int64, error) {
+	res, total, err := u.chatMessageRepository.ListChatMessages(ctx, req, spec)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	return u.chatMessageRepository.ListChatMessages(ctx, req)
+	for _, msg := range res {
+		u.fillThumbnails(msg.Attachments)
+	}
+	return res, total, nil
+}
+
+// Replay returns the messages of the group after afterSeq, or after the
+// member's delivery marker when afterSeq is nil.
+func (u *chatMessageUsecase) Replay(ctx context.Context, userID int, chatGroupID int, afterSeq *int64) (*chatmessage.Replay, error) {
+	member, err := u.chatGroupRepository.GetMember(ctx, int64(chatGroupID), int64(userID))
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, apperror.Forbidden("User %d is not a member of chat group %d", userID, chatGroupID)
+	}
+
+	from := member.LastDeliveredSeq
+	if afterSeq != nil {
+		from = *afterSeq
+	}
+
+	// One extra row tells whether another batch follows.
+	msgs, err := u.chatMessageRepository.ListChatMessagesAfterSeq(ctx, chatGroupID, from, replayBatchSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &chatmessage.Replay{
+		ChatGroupID: chatGroupID,
+		Messages:    msgs,
+		LastSeq:     from,
+	}
+	if len(msgs) > replayBatchSize {
+		replay.Messages = msgs[:replayBatchSize]
+		replay.HasMore = true
+	}
+	if n := len(replay.Messages); n > 0 {
+		replay.LastSeq = replay.Messages[n-1].Seq
+	}
+	for _, msg := range replay.Messages {
+		u.fillThumbnails(msg.Attachments)
+	}
+
+	return replay, nil
 }
 
 func (u *chatMessageUsecase) SendMessage(ctx context.Context, req *chatmessage.CreateChatMessageRequest) (*chatmessage.Response, error) {
+	if req.MessageText == "" && len(req.AttachmentIDs) == 0 {
+		return nil, apperror.BadRequest("Message text or attachments are required")
+	}
+
+	attachmentIDs, err := u.checkAttachments(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	entity := &chatmessage.ChatMessage{
 		ChatGroupID:  req.ChatGroupID,
 		UserIDSender: req.UserIDSender,
 		MessageText:  req.MessageText,
+		MessageType:  consts.ChatMessageTypeText,
+	}
+
+	if req.ParentID != nil {
+		parent, err := u.chatMessageRepository.GetChatMessageByID(ctx, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil || parent.ChatGroupID != req.ChatGroupID {
+			return nil, apperror.BadRequest("Parent message %d not found in chat group %d", *req.ParentID, req.ChatGroupID)
+		}
+
+		// Threads are one level deep: replying to a reply joins its thread.
+		entity.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			entity.ParentID = parent.ParentID
+		}
+	}
+
+	resp, err := u.create(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	u.queueIndex(ctx, resp.ID)
+	if len(attachmentIDs) == 0 {
+		return resp, nil
+	}
+
+	linked, err := u.chatMessageRepository.LinkAttachments(ctx, resp.ID, req.ChatGroupID, req.UserIDSender, attachmentIDs)
+	if err != nil {
+		return nil, err
+	}
+	if !linked {
+		// Another message claimed an attachment since checkAttachments.
+		u.logger.Warn("Chat attachments were not linked", xlogger.Int("chat_message_id", resp.ID))
+		return resp, nil
 	}
 
+	attachments, err := u.chatMessageRepository.ListAttachments(ctx, []int{resp.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments[resp.ID] {
+		resp.Attachments = append(resp.Attachments, attachment.ToResponse())
+	}
+	u.fillThumbnails(resp.Attachments)
+
+	return resp, nil
+}
+
+// checkAttachments makes sure every attachment was uploaded by the sender to
+// the chat group and is not part of another message yet. It returns the ids
+// without duplicates.
+func (u *chatMessageUsecase) checkAttachments(ctx context.Context, req *chatmessage.CreateChatMessageRequest) ([]int, error) {
+	ids := make([]int, 0, len(req.AttachmentIDs))
+	seen := make(map[int]bool, len(req.AttachmentIDs))
+	for _, id := range req.AttachmentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		attachment, err := u.chatMessageRepository.GetAttachment(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if attachment == nil || attachment.ChatGroupID != req.ChatGroupID || attachment.UploadedBy != req.UserIDSender {
+			return nil, apperror.BadRequest("Attachment %d not found in chat group %d", id, req.ChatGroupID)
+		}
+		if attachment.ChatMessageID != nil {
+			return nil, apperror.BadRequest("Attachment %d is already sent", id)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func (u *chatMessageUsecase) SendSystemMessage(ctx context.Context, chatGroupID int, event *chatmessage.SystemEvent) (*chatmessage.Response, error) {
+	text, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.create(ctx, &chatmessage.ChatMessage{
+		ChatGroupID:  chatGroupID,
+		UserIDSender: int(event.ActorID),
+		MessageText:  string(text),
+		MessageType:  consts.ChatMessageTypeSystem,
+	})
+}
+
+func (u *chatMessageUsecase) create(ctx context.Context, entity *chatmessage.ChatMessage) (*chatmessage.Response, error) {
 	row, err := u.chatMessageRepository.CreateChatMessage(ctx, entity)
 	if err != nil {
 		u.logger.Error("SendMessage failed", xlogger.Error(err))
 		return nil, err
 	}
 
-	resp := &chatmessage.Response{
-		ID:          row.ID,
-		MessageText: row.MessageText,
-		CreatedAt:   row.CreatedAt,
-		ChatGroupID: row.ChatGroupID,
+	return row.ToResponse(), nil
+}
+
+// EditMessage replaces the text of the user's own message. The previous
+// text is kept in the edit history.
+func (u *chatMessageUsecase) EditMessage(ctx context.Context, userID int, req *chatmessage.EditChatMessageRequest) (*chatmessage.Response, error) {
+	msg, err := u.getMessage(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.ensureMember(ctx, msg.ChatGroupID, userID); err != nil {
+		return nil, err
+	}
+	if msg.UserIDSender != userID || msg.MessageType != consts.ChatMessageTypeText {
+		return nil, apperror.Forbidden("User %d cannot edit message %d", userID, req.ID)
 	}
 
-	resp.Sender.ID = row.UserID
-	resp.Sender.FullName = row.FullName
-	resp.Sender.Avatar = row.Avatar
-	resp.Sender.RoleID = row.RoleID
+	if msg.MessageText != req.MessageText {
+		if err := u.chatMessageRepository.EditChatMessage(ctx, msg, req.MessageText, userID); err != nil {
+			return nil, err
+		}
+		u.queueIndex(ctx, msg.ID)
+	}
 
+	row, err := u.chatMessageRepository.GetChatMessageRow(ctx, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := row.ToResponse()
+
+	attachments, err := u.chatMessageRepository.ListAttachments(ctx, []int{msg.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments[msg.ID] {
+		resp.Attachments = append(resp.Attachments, attachment.ToResponse())
+	}
+	u.fillThumbnails(resp.Attachments)
+
+	counts, err := u.chatMessageRepository.ListReactionCounts(ctx, []int{msg.ID})
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := counts[msg.ID]; ok {
+		resp.Reactions = c
+	}
+
+	u.broadcast(ctx, msg.ChatGroupID, consts.WSEventEditMessage, resp)
 	return resp, nil
 }
+
+// DeleteMessage deletes a message for everyone. Senders may delete their
+// own messages and group admins any message of the group.
+func (u *chatMessageUsecase) DeleteMessage(ctx context.Context, userID int, id int) (*chatmessage.DeletedEvent, error) {
+	msg, err := u.getMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := u.chatGroupRepository.GetMember(ctx, int64(msg.ChatGroupID), int64(userID))
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, apperror.Forbidden("User %d is not a member of chat group %d", userID, msg.ChatGroupID)
+	}
+	if msg.UserIDSender != userID && member.Role != consts.ChatGroupRoleAdmin {
+		return nil, apperror.Forbidden("User %d cannot delete message %d", userID, id)
+	}
+
+	return u.deleteMessage(ctx, msg, userID)
+}
+
+// RemoveMessage deletes a message for everyone on behalf of a moderator.
+// Moderators are authorized by permission, not by group membership.
+func (u *chatMessageUsecase) RemoveMessage(ctx context.Context, moderatorID int, id int) (*chatmessage.DeletedEvent, error) {
+	msg, err := u.getMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.deleteMessage(ctx, msg, moderatorID)
+}
+
+func (u *chatMessageUsecase) deleteMessage(ctx context.Context, msg *chatmessage.ChatMessage, userID int) (*chatmessage.DeletedEvent, error) {
+	deleted, err := u.chatMessageRepository.DeleteChatMessage(ctx, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, apperror.NotFound("Chat message %d not found", msg.ID)
+	}
+	u.queueIndex(ctx, msg.ID)
+
+	event := &chatmessage.DeletedEvent{
+		ChatMessageID: msg.ID,
+		ChatGroupID:   msg.ChatGroupID,
+		DeletedBy:     userID,
+	}
+
+	u.broadcast(ctx, msg.ChatGroupID, consts.WSEventDeleteMessage, event)
+	return event, nil
+}
+
+// React toggles the user's reaction on a message.
+func (u *chatMessageUsecase) React(ctx context.Context, userID int, req *chatmessage.ReactRequest) (*chatmessage.ReactionEvent, error) {
+	msg, err := u.getMessage(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.ensureMember(ctx, msg.ChatGroupID, userID); err != nil {
+		return nil, err
+	}
+
+	added, err := u.chatMessageRepository.ToggleReaction(ctx, msg.ID, userID, req.Emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := u.chatMessageRepository.ListReactionCounts(ctx, []int{msg.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	event := &chatmessage.ReactionEvent{
+		ChatMessageID: msg.ID,
+		ChatGroupID:   msg.ChatGroupID,
+		UserID:        userID,
+		Emoji:         req.Emoji,
+		Added:         added,
+		Reactions:     counts[msg.ID],
+	}
+	if event.Reactions == nil {
+		event.Reactions = []chatmessage.ReactionCount{}
+	}
+
+	u.broadcast(ctx, msg.ChatGroupID, consts.WSEventReact, event)
+	return event, nil
+}
+
+// ListEdits returns the previous versions of a message, oldest first.
+func (u *chatMessageUsecase) ListEdits(ctx context.Context, userID int, id int) ([]*chatmessage.Edit, error) {
+	msg, err := u.getMessage(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.ensureMember(ctx, msg.ChatGroupID, userID); err != nil {
+		return nil, err
+	}
+
+	return u.chatMessageRepository.ListChatMessageEdits(ctx, id)
+}
+
+// UploadAttachment records a pending attachment and queues the upload of the
+// file. The returned id is sent with the message once the user sends it.
+func (u *chatMessageUsecase) UploadAttachment(ctx context.Context, userID int, req *chatmessage.UploadAttachmentRequest) (*chatmessage.AttachmentResponse, error) {
+	if req.File == nil {
+		return nil, apperror.BadRequest("File is required")
+	}
+	if err := u.ensureMember(ctx, req.ChatGroupID, userID); err != nil {
+		return nil, err
+	}
+
+	attachment := &chatmessage.Attachment{
+		ChatGroupID: req.ChatGroupID,
+		UploadedBy:  userID,
+		Kind:        req.Kind,
+		Status:      consts.ChatAttachmentStatusPending,
+		FileName:    req.File.Filename,
+		ContentType: req.File.Header.Get("Content-Type"),
+		Size:        req.File.Size,
+	}
+	if err := u.chatMessageRepository.CreateAttachment(ctx, attachment); err != nil {
+		return nil, err
+	}
+
+	if err := u.queue.PublishMessage(
+		ctx,
+		consts.UploadChatAttachmentJobType,
+		&chatmessage.UploadAttachmentQueuePayload{
+			AttachmentID: attachment.ID,
+			ChatGroupID:  req.ChatGroupID,
+			Image:        req.Kind == consts.ChatAttachmentKindImage,
+			File:         req.File,
+		},
+	); err != nil {
+		u.logger.Error(
+			"Publish upload chat attachment job failed",
+			xlogger.Error(err),
+			xlogger.Int("attachment_id", attachment.ID),
+		)
+		return nil, err
+	}
+
+	return attachment.ToResponse(), nil
+}
+
+// ProcessAttachmentUpload is called by the upload job once the file is
+// stored. Rooms already showing the message are told the file is ready.
+func (u *chatMessageUsecase) ProcessAttachmentUpload(ctx context.Context, attachmentID int, file *service.StoredFile) error {
+	if err := u.chatMessageRepository.UpdateAttachmentStored(ctx, attachmentID, file); err != nil {
+		return err
+	}
+
+	attachment, err := u.chatMessageRepository.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		return err
+	}
+	if attachment == nil {
+		return apperror.NotFound("Chat attachment %d not found", attachmentID)
+	}
+
+	if attachment.ChatMessageID != nil {
+		resp := attachment.ToResponse()
+		u.fillThumbnails([]*chatmessage.AttachmentResponse{resp})
+		u.broadcast(ctx, attachment.ChatGroupID, consts.WSEventAttachmentReady, resp)
+	}
+
+	return nil
+}
+
+// AttachmentDownloadURL returns a short-lived link to the file for members
+// of the chat group of the attachment.
+func (u *chatMessageUsecase) AttachmentDownloadURL(ctx context.Context, userID int, id int) (string, error) {
+	attachment, err := u.chatMessageRepository.GetAttachment(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if attachment == nil {
+		return "", apperror.NotFound("Chat attachment %d not found", id)
+	}
+	if err := u.ensureMember(ctx, attachment.ChatGroupID, userID); err != nil {
+		return "", err
+	}
+
+	// Until it is sent, only the uploader knows about the attachment.
+	if attachment.ChatMessageID == nil {
+		if attachment.UploadedBy != userID {
+			return "", apperror.NotFound("Chat attachment %d not found", id)
+		}
+	} else if _, err := u.getMessage(ctx, *attachment.ChatMessageID); err != nil {
+		return "", err
+	}
+
+	if attachment.Status != consts.ChatAttachmentStatusReady {
+		return "", apperror.BadRequest("Chat attachment %d is still uploading", id)
+	}
+
+	return u.storage.DownloadURL(storedFile(attachment), attachment.FileName, attachmentDownloadTTL)
+}
+
+// SearchMessages searches the text messages of the chat groups the user
+// belongs to, best match first.
+func (u *chatMessageUsecase) SearchMessages(ctx context.Context, userID int, req *chatmessage.SearchRequest) ([]*chatmessage.SearchResult, int64, error) {
+	var groupIDs []int64
+	if req.ChatGroupID > 0 {
+		if err := u.ensureMember(ctx, req.ChatGroupID, userID); err != nil {
+			return nil, 0, err
+		}
+		groupIDs = []int64{int64(req.ChatGroupID)}
+	} else {
+		ids, err := u.chatGroupRepository.ListMemberGroupIDs(ctx, int64(userID))
+		if err != nil {
+			return nil, 0, err
+		}
+		groupIDs = ids
+	}
+	if len(groupIDs) == 0 {
+		return []*chatmessage.SearchResult{}, 0, nil
+	}
+
+	page, limit := req.Page, req.Limit
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	hits, total, err := u.searchRepository.Search(ctx, req.Q, groupIDs, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	msgs, err := u.chatMessageRepository.ListChatMessagesByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[int]*chatmessage.Response, len(msgs))
+	for _, msg := range msgs {
+		byID[msg.ID] = msg
+	}
+
+	// The index may briefly lag behind deletes; those hits are skipped.
+	res := make([]*chatmessage.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		msg, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+		u.fillThumbnails(msg.Attachments)
+
+		highlights := hit.Highlights
+		if highlights == nil {
+			highlights = []string{}
+		}
+		res = append(res, &chatmessage.SearchResult{Message: msg, Highlights: highlights})
+	}
+
+	return res, total, nil
+}
+
+// SyncSearchIndex brings the indexed copy of a message up to date: text
+// messages are indexed, deleted ones removed.
+func (u *chatMessageUsecase) SyncSearchIndex(ctx context.Context, id int) error {
+	msg, err := u.chatMessageRepository.GetChatMessageByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if msg == nil || msg.MessageType != consts.ChatMessageTypeText {
+		return u.searchRepository.DeleteMessages(ctx, []int{id})
+	}
+
+	return u.searchRepository.IndexMessages(ctx, []*chatmessage.SearchDocument{msg.ToSearchDocument()})
+}
+
+// ReindexSearch indexes every text message again, e.g. after the index was
+// created. It returns the number of indexed messages.
+func (u *chatMessageUsecase) ReindexSearch(ctx context.Context) (int, error) {
+	total, afterID := 0, 0
+	for {
+		msgs, err := u.chatMessageRepository.ListTextMessagesAfterID(ctx, afterID, reindexBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(msgs) == 0 {
+			return total, nil
+		}
+
+		docs := make([]*chatmessage.SearchDocument, 0, len(msgs))
+		for _, msg := range msgs {
+			docs = append(docs, msg.ToSearchDocument())
+		}
+		if err := u.searchRepository.IndexMessages(ctx, docs); err != nil {
+			return total, err
+		}
+
+		total += len(msgs)
+		afterID = msgs[len(msgs)-1].ID
+	}
+}
+
+// queueIndex schedules SyncSearchIndex. The message is already stored, so
+// failures are only logged; ReindexSearch repairs the index.
+func (u *chatMessageUsecase) queueIndex(ctx context.Context, id int) {
+	if err := u.queue.PublishMessage(ctx, consts.IndexChatMessageJobType, &chatmessage.IndexQueuePayload{ChatMessageID: id}); err != nil {
+		u.logger.Warn("Publish index chat message job failed", xlogger.Error(err), xlogger.Int("chat_message_id", id))
+	}
+}
+
+// fillThumbnails sets the preview URL of ready images. A failure only
+// leaves the preview out.
+func (u *chatMessageUsecase) fillThumbnails(attachments []*chatmessage.AttachmentResponse) {
+	for _, attachment := range attachments {
+		if attachment.Kind != consts.ChatAttachmentKindImage || attachment.Status != consts.ChatAttachmentStatusReady {
+			continue
+		}
+		url, err := u.storage.ThumbnailURL(service.StoredFile{Key: attachment.StorageKey})
+		if err != nil {
+			u.logger.Warn("Build chat attachment thumbnail failed", xlogger.Error(err), xlogger.Int("attachment_id", attachment.ID))
+			continue
+		}
+		attachment.ThumbnailURL = url
+	}
+}
+
+func storedFile(attachment *chatmessage.Attachment) service.StoredFile {
+	return service.StoredFile{
+		Key:          attachment.StorageKey,
+		ResourceType: attachment.ResourceType,
+		Format:       attachment.Format,
+		Width:        attachment.Width,
+		Height:       attachment.Height,
+	}
+}
+
+func (u *chatMessageUsecase) getMessage(ctx context.Context, id int) (*chatmessage.ChatMessage, error) {
+	msg, err := u.chatMessageRepository.GetChatMessageByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, apperror.NotFound("Chat message %d not found", id)
+	}
+
+	return msg, nil
+}
+
+func (u *chatMessageUsecase) ensureMember(ctx context.Context, chatGroupID int, userID int) error {
+	ok, err := u.chatGroupRepository.IsMember(ctx, int64(chatGroupID), int64(userID))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperror.Forbidden("User %d is not a member of chat group %d", userID, chatGroupID)
+	}
+
+	return nil
+}
+
+// broadcast pushes the change to the room. It is already stored, so
+// failures are only logged.
+func (u *chatMessageUsecase) broadcast(ctx context.Context, chatGroupID int, eventType string, data interface{}) {
+	if err := u.realtime.BroadcastToRoom(ctx, chatGroupID, eventType, data); err != nil {
+		u.logger.Warn("Broadcast chat event failed", xlogger.Error(err), xlogger.String("event", eventType))
+	}
+}
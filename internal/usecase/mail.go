@@ -2,37 +2,210 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
 	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
 	"thomas.vn/apartment_service/internal/domain/usecase"
+	xical "thomas.vn/apartment_service/pkg/ical"
 )
 
 type mailUsecase struct {
-	repo repository.MailRepository
+	repo        repository.MailRepository
+	renderer    service.MailRenderer
+	fileSvc     service.FileService
+	unsubscribe service.UnsubscribeLinks
 }
 
-func NewMailUsecase(repo repository.MailRepository) usecase.MailUsecase {
-	return &mailUsecase{repo: repo}
+func NewMailUsecase(repo repository.MailRepository, renderer service.MailRenderer, fileSvc service.FileService, unsubscribe service.UnsubscribeLinks) usecase.MailUsecase {
+	return &mailUsecase{repo: repo, renderer: renderer, fileSvc: fileSvc, unsubscribe: unsubscribe}
 }
 
-func (u *mailUsecase) SendLoginMail(ctx context.Context, email, fullName string) error {
-	return u.repo.Send(ctx, repository.MailData{
-		Email:    email,
-		Subject:  "Cảnh báo đăng nhập",
-		Title:    "Cảnh báo đăng nhập",
-		Color:    "red",
-		Action:   "đăng nhập vào Apartment Business",
-		FullName: fullName,
+func (u *mailUsecase) Send(ctx context.Context, payload *model.MailPayload) (*model.MailResult, error) {
+	data, err := templateData(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var unsubscribeURL string
+	if payload.Category != "" && payload.UserID != 0 {
+		unsubscribeURL = u.unsubscribe.URL(payload.UserID, payload.Category)
+	}
+
+	mail, err := u.renderer.Render(payload.Template, payload.Locale, data, unsubscribeURL)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := u.attachments(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	messageID, err := u.repo.Send(ctx, repository.MailData{
+		Email:          payload.Email,
+		Subject:        mail.Subject,
+		HTML:           mail.HTML,
+		Text:           mail.Text,
+		Attachments:    attachments,
+		UnsubscribeURL: unsubscribeURL,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MailResult{Subject: mail.Subject, MessageID: messageID}, nil
 }
 
-func (u *mailUsecase) SendRegisterMail(ctx context.Context, email, fullName string) error {
-	return u.repo.Send(ctx, repository.MailData{
-		Email:    email,
-		Subject:  "Thông báo đăng ký",
-		Title:    "Thông báo đăng ký",
-		Color:    "green",
-		Action:   "đăng ký thành công tài khoản Apartment Business",
-		FullName: fullName,
-	})
+// attachments loads the payload's attachments and adds its event as a
+// calendar invite.
+func (u *mailUsecase) attachments(payload *model.MailPayload) ([]repository.MailAttachment, error) {
+	res := make([]repository.MailAttachment, 0, len(payload.Attachments)+1)
+	for _, a := range payload.Attachments {
+		attachment := repository.MailAttachment{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Content:     a.Content,
+		}
+		if attachment.Content == nil && a.URL != "" {
+			file, err := u.fileSvc.Download(a.URL)
+			if err != nil {
+				return nil, fmt.Errorf("download attachment %s: %w", a.Filename, err)
+			}
+			attachment.Content = file.Content
+			if attachment.ContentType == "" {
+				attachment.ContentType = file.ContentType
+			}
+		}
+		res = append(res, attachment)
+	}
+
+	if payload.Event != nil {
+		cal := calendarInvite(payload.Email, payload.FullName, payload.Event)
+		res = append(res, repository.MailAttachment{
+			Filename:    "invite.ics",
+			ContentType: cal.ContentType(),
+			Content:     cal.Bytes(),
+		})
+	}
+
+	return res, nil
+}
+
+func calendarInvite(email, fullName string, event *model.CalendarEvent) *xical.Calendar {
+	e := xical.Event{
+		UID:         event.UID,
+		Sequence:    event.Sequence,
+		Summary:     event.Summary,
+		Description: event.Description,
+		Location:    event.Location,
+		Start:       event.Start,
+		End:         event.End,
+		Attendees:   []xical.Person{{Name: fullName, Email: email}},
+		Cancelled:   event.Cancelled,
+	}
+	if event.OrganizerEmail != "" {
+		e.Organizer = &xical.Person{Name: event.OrganizerName, Email: event.OrganizerEmail}
+	}
+	for _, m := range event.ReminderMinutes {
+		e.Reminders = append(e.Reminders, time.Duration(m)*time.Minute)
+	}
+
+	// Invites that can be answered need an organizer (RFC 5546); without
+	// one the event is only published.
+	method := xical.MethodPublish
+	if e.Organizer != nil {
+		method = xical.MethodRequest
+		if event.Cancelled {
+			method = xical.MethodCancel
+		}
+	}
+	return &xical.Calendar{Method: method, Events: []xical.Event{e}}
+}
+
+// templateData builds the data the payload's template renders.
+func templateData(payload *model.MailPayload) (any, error) {
+	switch payload.Template {
+	case consts.MailTemplateLogin:
+		return &model.LoginMailData{FullName: payload.FullName}, nil
+
+	case consts.MailTemplateRegister:
+		return &model.RegisterMailData{FullName: payload.FullName}, nil
+
+	case consts.MailTemplateChatDigest:
+		if payload.ChatDigest == nil {
+			return nil, fmt.Errorf("chat digest mail without digest")
+		}
+		var unread int64
+		for _, g := range payload.ChatDigest.Groups {
+			unread += g.UnreadCount
+		}
+		return &model.ChatDigestMailData{
+			FullName:    payload.FullName,
+			UnreadCount: unread,
+			Digest:      payload.ChatDigest,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported mail template: %s", payload.Template)
+	}
+}
+
+func (u *mailUsecase) ListTemplates(_ context.Context) []model.MailTemplate {
+	return u.renderer.Templates()
+}
+
+// PreviewTemplate renders a template with sample data without sending it.
+func (u *mailUsecase) PreviewTemplate(_ context.Context, req *model.PreviewMailRequest) (*model.RenderedMail, error) {
+	sample, ok := mailSamples[req.Name]
+	if !ok {
+		return nil, apperror.NotFound("Mail template %s not found", req.Name)
+	}
+
+	locale := req.Locale
+	if locale == "" {
+		locale = consts.LocaleDefault
+	}
+	return u.renderer.Render(req.Name, locale, sample(), "")
+}
+
+// mailSamples builds the preview data of each template. The names contain
+// markup on purpose so previews show that user input is escaped.
+var mailSamples = map[string]func() any{
+	consts.MailTemplateLogin: func() any {
+		return &model.LoginMailData{FullName: "Nguyễn Văn <b>A</b>"}
+	},
+	consts.MailTemplateRegister: func() any {
+		return &model.RegisterMailData{FullName: "Nguyễn Văn <b>A</b>"}
+	},
+	consts.MailTemplateChatDigest: func() any {
+		sentAt := time.Now().Add(-30 * time.Minute)
+		return &model.ChatDigestMailData{
+			FullName:    "Nguyễn Văn <b>A</b>",
+			UnreadCount: 3,
+			Digest: &model.ChatDigest{Groups: []model.ChatDigestGroup{
+				{
+					ChatGroupID: 1,
+					Name:        "Tòa A - Cư dân",
+					UnreadCount: 2,
+					Messages: []model.ChatDigestMessage{
+						{Sender: "Trần Thị B", Text: "Tối nay cắt nước từ 22h nhé.", SentAt: sentAt},
+						{Sender: "Lê Văn C", Text: "<script>alert(1)</script>", SentAt: sentAt.Add(5 * time.Minute)},
+					},
+				},
+				{
+					ChatGroupID: 2,
+					Name:        "Ban quản lý",
+					UnreadCount: 1,
+					Messages: []model.ChatDigestMessage{
+						{Sender: "Ban quản lý", Text: "Phí dịch vụ tháng này đã được cập nhật.", SentAt: sentAt},
+					},
+				},
+			}},
+		}
+	},
 }
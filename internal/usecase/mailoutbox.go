@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"thomas.vn/apartment_service/internal/config"
+	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+type mailOutboxService struct {
+	repo     repository.MailOutboxRepository
+	prefRepo repository.NotificationPreferenceRepository
+}
+
+func NewMailOutboxService(repo repository.MailOutboxRepository, prefRepo repository.NotificationPreferenceRepository) service.MailOutbox {
+	return &mailOutboxService{repo: repo, prefRepo: prefRepo}
+}
+
+// Enqueue drops mail of a category the recipient turned off for email.
+func (s *mailOutboxService) Enqueue(ctx context.Context, tx *gorm.DB, payload *model.MailPayload) error {
+	if payload.Category != "" {
+		enabled, err := s.prefRepo.IsEnabled(ctx, payload.UserID, payload.Category, consts.NotificationChannelEmail)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return nil
+		}
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.CreateMailOutbox(ctx, tx, &model.MailOutbox{
+		Template:  payload.Template,
+		Recipient: payload.Email,
+		Locale:    payload.Locale,
+		Payload:   string(raw),
+		Status:    consts.MailStatusPending,
+	})
+}
+
+type mailOutboxUsecase struct {
+	logger   *xlogger.Logger
+	cfg      config.MailOutboxConfig
+	repo     repository.MailOutboxRepository
+	mailUC   usecase.MailUsecase
+	auditSvc service.AuditService
+}
+
+func NewMailOutboxUsecase(
+	logger *xlogger.Logger,
+	cfg config.MailOutboxConfig,
+	repo repository.MailOutboxRepository,
+	mailUC usecase.MailUsecase,
+	auditSvc service.AuditService,
+) usecase.MailOutboxUsecase {
+	return &mailOutboxUsecase{
+		logger:   logger,
+		cfg:      cfg,
+		repo:     repo,
+		mailUC:   mailUC,
+		auditSvc: auditSvc,
+	}
+}
+
+// DeliverDue sends the pending mail. Each mail is claimed first, so two
+// workers never send the same mail. Failures are retried with a growing
+// delay until MaxAttempts is reached.
+func (u *mailOutboxUsecase) DeliverDue(ctx context.Context) error {
+	now := xutils.GetTimeNow()
+	staleBefore := now.Add(-time.Duration(u.cfg.StaleMinutes) * time.Minute)
+
+	mails, err := u.repo.ListDueMailOutbox(ctx, now, staleBefore, u.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, mail := range mails {
+		claimed, err := u.repo.ClaimMailOutbox(ctx, mail)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		if err := u.deliver(ctx, mail); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deliver sends a claimed mail. Only errors recording the outcome are
+// returned; a failed send is recorded on the mail.
+func (u *mailOutboxUsecase) deliver(ctx context.Context, mail *model.MailOutbox) error {
+	var payload model.MailPayload
+	err := json.Unmarshal([]byte(mail.Payload), &payload)
+	if err == nil {
+		var result *model.MailResult
+		result, err = u.mailUC.Send(ctx, &payload)
+		if err == nil {
+			return u.repo.MarkMailSent(ctx, mail.ID, result)
+		}
+	}
+
+	u.logger.Warn("Deliver mail failed", xlogger.Error(err), xlogger.Int64("mail_id", mail.ID), xlogger.Int("attempts", mail.Attempts))
+
+	status := consts.MailStatusPending
+	if mail.Attempts >= u.cfg.MaxAttempts {
+		status = consts.MailStatusFailed
+	}
+	nextAttemptAt := xutils.GetTimeNow().Add(time.Duration(mail.Attempts*mail.Attempts) * time.Minute)
+	return u.repo.MarkMailFailed(ctx, mail.ID, status, err.Error(), nextAttemptAt)
+}
+
+func (u *mailOutboxUsecase) ListMailOutbox(ctx context.Context, req *model.ListMailOutboxRequest) ([]*model.MailOutbox, int64, error) {
+	return u.repo.ListMailOutbox(ctx, req)
+}
+
+func (u *mailOutboxUsecase) GetMailOutbox(ctx context.Context, id int64) (*model.MailOutbox, error) {
+	mail, err := u.repo.GetMailOutbox(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if mail == nil {
+		return nil, apperror.NotFound("Mail %d not found", id)
+	}
+
+	return mail, nil
+}
+
+// Resend queues a failed mail again. It goes out with the next worker run.
+func (u *mailOutboxUsecase) Resend(ctx context.Context, id int64) (*model.MailOutbox, error) {
+	mail, err := u.GetMailOutbox(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if mail.Status != consts.MailStatusFailed {
+		return nil, apperror.BadRequest("Only failed mail can be resent")
+	}
+
+	reset, err := u.repo.ResetMailOutbox(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !reset {
+		return nil, apperror.BadRequest("Only failed mail can be resent")
+	}
+
+	updated, err := u.GetMailOutbox(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.auditSvc.Record(ctx, model.AuditEntry{
+		Action:     consts.AuditActionMailResend,
+		TargetType: consts.AuditTargetMail,
+		TargetID:   id,
+		Before:     mail,
+		After:      updated,
+	}); err != nil {
+		u.logger.Warn("Record audit log failed", xlogger.Error(err))
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model/moderation"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+// Moderator review actions.
+const (
+	reviewActionRemove  = "remove"
+	reviewActionDismiss = "dismiss"
+)
+
+type moderationUsecase struct {
+	logger          *xlogger.Logger
+	moderationRepo  repository.ModerationRepository
+	userRepo        repository.UserRepository
+	chatMessageRepo repository.ChatMessageRepository
+	chatGroupRepo   repository.ChatGroupRepository
+	chatMessageUC   usecase.ChatMessageUsecase
+}
+
+func NewModerationUsecase(
+	logger *xlogger.Logger,
+	moderationRepo repository.ModerationRepository,
+	userRepo repository.UserRepository,
+	chatMessageRepo repository.ChatMessageRepository,
+	chatGroupRepo repository.ChatGroupRepository,
+	chatMessageUC usecase.ChatMessageUsecase,
+) usecase.ModerationUsecase {
+	return &moderationUsecase{
+		logger:          logger,
+		moderationRepo:  moderationRepo,
+		userRepo:        userRepo,
+		chatMessageRepo: chatMessageRepo,
+		chatGroupRepo:   chatGroupRepo,
+		chatMessageUC:   chatMessageUC,
+	}
+}
+
+func (u *moderationUsecase) BlockUser(ctx context.Context, userID int, req *moderation.BlockRequest) error {
+	if req.UserID == userID {
+		return apperror.BadRequest("Users cannot block themselves")
+	}
+
+	target, err := u.userRepo.GetUserByID(ctx, uint(req.UserID))
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return apperror.NotFound("User %d not found", req.UserID)
+	}
+
+	return u.moderationRepo.BlockUser(ctx, userID, req.UserID)
+}
+
+func (u *moderationUsecase) UnblockUser(ctx context.Context, userID int, req *moderation.UnblockRequest) error {
+	unblocked, err := u.moderationRepo.UnblockUser(ctx, userID, req.UserID)
+	if err != nil {
+		return err
+	}
+	if !unblocked {
+		return apperror.NotFound("User %d is not blocked", req.UserID)
+	}
+
+	return nil
+}
+
+func (u *moderationUsecase) ListBlockedUsers(ctx context.Context, userID int) ([]*moderation.BlockedUser, error) {
+	return u.moderationRepo.ListBlockedUsers(ctx, userID)
+}
+
+// ReportMessage flags a message of a group the user belongs to. Each user
+// reports a message at most once.
+func (u *moderationUsecase) ReportMessage(ctx context.Context, userID int, req *moderation.ReportMessageRequest) (*moderation.Report, error) {
+	msg, err := u.chatMessageRepo.GetChatMessageByID(ctx, req.ChatMessageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, apperror.NotFound("Chat message %d not found", req.ChatMessageID)
+	}
+	if msg.UserIDSender == userID {
+		return nil, apperror.BadRequest("Users cannot report their own messages")
+	}
+
+	ok, err := u.chatGroupRepo.IsMember(ctx, int64(msg.ChatGroupID), int64(userID))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.Forbidden("User %d is not a member of chat group %d", userID, msg.ChatGroupID)
+	}
+
+	report := &moderation.Report{
+		ChatMessageID: msg.ID,
+		ChatGroupID:   msg.ChatGroupID,
+		ReporterID:    userID,
+		Reason:        req.Reason,
+	}
+
+	created, err := u.moderationRepo.CreateReport(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, apperror.BadRequest("Chat message %d is already reported", msg.ID)
+	}
+
+	return report, nil
+}
+
+func (u *moderationUsecase) ListReports(ctx context.Context, req *moderation.ListReportRequest) ([]*moderation.ReportResponse, int64, error) {
+	return u.moderationRepo.ListReports(ctx, req)
+}
+
+// ReviewReport removes the reported message or dismisses the report. Either
+// way every pending report of the message is closed. Reports from groups
+// outside the caller's buildings are treated as missing.
+func (u *moderationUsecase) ReviewReport(ctx context.Context, moderatorID int, req *moderation.ReviewReportRequest) error {
+	report, err := u.moderationRepo.GetReport(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return apperror.NotFound("Report %d not found", req.ID)
+	}
+	// Reports of deleted groups still have to be closed.
+	group, err := u.chatGroupRepo.GetChatGroupByIDWithDeleted(ctx, int64(report.ChatGroupID))
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return apperror.NotFound("Report %d not found", req.ID)
+	}
+	if report.Status != consts.ChatReportStatusPending {
+		return apperror.BadRequest("Report %d is already %s", req.ID, report.Status)
+	}
+
+	var status string
+	switch req.Action {
+	case reviewActionRemove:
+		status = consts.ChatReportStatusRemoved
+		if _, err := u.chatMessageUC.RemoveMessage(ctx, moderatorID, report.ChatMessageID); err != nil {
+			return err
+		}
+	case reviewActionDismiss:
+		status = consts.ChatReportStatusDismissed
+	default:
+		return apperror.BadRequest("Unknown review action %s", req.Action)
+	}
+
+	closed, err := u.moderationRepo.ResolveReports(ctx, report.ChatMessageID, status, moderatorID)
+	if err != nil {
+		return err
+	}
+
+	u.logger.Info(
+		"Reviewed chat message reports",
+		xlogger.Int("chat_message_id", report.ChatMessageID),
+		xlogger.String("status", status),
+		xlogger.Int64("reports", closed),
+	)
+
+	return nil
+}
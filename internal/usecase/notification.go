@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type notificationService struct {
+	logger   *xlogger.Logger
+	repo     repository.NotificationRepository
+	prefRepo repository.NotificationPreferenceRepository
+	notifier service.RealtimeNotifier
+}
+
+func NewNotificationService(logger *xlogger.Logger, repo repository.NotificationRepository, prefRepo repository.NotificationPreferenceRepository, notifier service.RealtimeNotifier) service.NotificationService {
+	return &notificationService{
+		logger:   logger,
+		repo:     repo,
+		prefRepo: prefRepo,
+		notifier: notifier,
+	}
+}
+
+// Notify stores the notification and pushes it with the new unread count,
+// skipping whichever channel the user turned off for the entry's category.
+// Without the inbox, a bare alert is pushed instead. Pushing is best effort:
+// users who are offline see it in their inbox.
+func (s *notificationService) Notify(ctx context.Context, entry model.NotificationEntry) error {
+	inApp, err := s.channelEnabled(ctx, entry, consts.NotificationChannelInApp)
+	if err != nil {
+		return err
+	}
+	push, err := s.channelEnabled(ctx, entry, consts.NotificationChannelWebSocket)
+	if err != nil {
+		return err
+	}
+
+	notification := &model.Notification{
+		UserID: entry.UserID,
+		Type:   entry.Type,
+		Title:  entry.Title,
+		Body:   entry.Body,
+		Link:   entry.Link,
+	}
+	if inApp {
+		if err := s.repo.CreateNotification(ctx, notification); err != nil {
+			return err
+		}
+	}
+	if !push {
+		return nil
+	}
+	if !inApp {
+		alert := &model.NotificationAlert{
+			Type:  entry.Type,
+			Title: entry.Title,
+			Body:  entry.Body,
+			Link:  entry.Link,
+		}
+		if err := s.notifier.NotifyUser(ctx, entry.UserID, consts.WSEventNotificationAlert, alert); err != nil {
+			s.logger.Warn("Push notification alert failed", xlogger.Error(err), xlogger.Int("user_id", entry.UserID))
+		}
+		return nil
+	}
+
+	unread, err := s.repo.CountUnread(ctx, entry.UserID)
+	if err != nil {
+		s.logger.Warn("Count unread notifications failed", xlogger.Error(err))
+		return nil
+	}
+
+	event := &model.NotificationEvent{Notification: notification, UnreadCount: unread}
+	if err := s.notifier.NotifyUser(ctx, entry.UserID, consts.WSEventNotification, event); err != nil {
+		s.logger.Warn("Push notification failed", xlogger.Error(err), xlogger.Int("user_id", entry.UserID))
+	}
+
+	return nil
+}
+
+// channelEnabled reports whether the entry may go out on channel. Entries
+// without a category are always delivered.
+func (s *notificationService) channelEnabled(ctx context.Context, entry model.NotificationEntry, channel string) (bool, error) {
+	if entry.Category == "" {
+		return true, nil
+	}
+
+	return s.prefRepo.IsEnabled(ctx, entry.UserID, entry.Category, channel)
+}
+
+type notificationUsecase struct {
+	logger   *xlogger.Logger
+	repo     repository.NotificationRepository
+	notifier service.RealtimeNotifier
+}
+
+func NewNotificationUsecase(logger *xlogger.Logger, repo repository.NotificationRepository, notifier service.RealtimeNotifier) usecase.NotificationUsecase {
+	return &notificationUsecase{
+		logger:   logger,
+		repo:     repo,
+		notifier: notifier,
+	}
+}
+
+func (u *notificationUsecase) ListNotifications(ctx context.Context, userID int, req *model.ListNotificationRequest) ([]*model.Notification, int64, error) {
+	return u.repo.ListNotifications(ctx, userID, req)
+}
+
+func (u *notificationUsecase) Badge(ctx context.Context, userID int) (*model.NotificationBadge, error) {
+	unread, err := u.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NotificationBadge{UnreadCount: unread}, nil
+}
+
+func (u *notificationUsecase) MarkRead(ctx context.Context, userID int, id int64) (*model.NotificationBadge, error) {
+	found, err := u.repo.MarkRead(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, apperror.NotFound("Notification %d not found", id)
+	}
+
+	return u.syncBadge(ctx, userID)
+}
+
+func (u *notificationUsecase) MarkAllRead(ctx context.Context, userID int) (*model.NotificationBadge, error) {
+	if _, err := u.repo.MarkAllRead(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return u.syncBadge(ctx, userID)
+}
+
+// syncBadge returns the user's unread count and pushes it to their other
+// devices.
+func (u *notificationUsecase) syncBadge(ctx context.Context, userID int) (*model.NotificationBadge, error) {
+	badge, err := u.Badge(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.notifier.NotifyUser(ctx, userID, consts.WSEventNotificationBadge, badge); err != nil {
+		u.logger.Warn("Push notification badge failed", xlogger.Error(err), xlogger.Int("user_id", userID))
+	}
+
+	return badge, nil
+}
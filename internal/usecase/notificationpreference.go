@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+type notificationPreferenceUsecase struct {
+	logger      *xlogger.Logger
+	prefRepo    repository.NotificationPreferenceRepository
+	unsubscribe service.UnsubscribeLinks
+}
+
+func NewNotificationPreferenceUsecase(logger *xlogger.Logger, prefRepo repository.NotificationPreferenceRepository, unsubscribe service.UnsubscribeLinks) usecase.NotificationPreferenceUsecase {
+	return &notificationPreferenceUsecase{
+		logger:      logger,
+		prefRepo:    prefRepo,
+		unsubscribe: unsubscribe,
+	}
+}
+
+func (u *notificationPreferenceUsecase) ListPreferences(ctx context.Context, userID int) ([]*model.NotificationPreference, error) {
+	stored, err := u.prefRepo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make(map[[2]string]bool, len(stored))
+	for _, p := range stored {
+		enabled[[2]string{p.Category, p.Channel}] = p.Enabled
+	}
+
+	prefs := make([]*model.NotificationPreference, 0, len(consts.NotificationCategories)*len(consts.NotificationChannels))
+	for _, category := range consts.NotificationCategories {
+		for _, channel := range consts.NotificationChannels {
+			on, ok := enabled[[2]string{category, channel}]
+			prefs = append(prefs, &model.NotificationPreference{
+				UserID:   userID,
+				Category: category,
+				Channel:  channel,
+				Enabled:  on || !ok,
+			})
+		}
+	}
+
+	return prefs, nil
+}
+
+func (u *notificationPreferenceUsecase) UpdatePreferences(ctx context.Context, userID int, req *model.UpdateNotificationPreferencesRequest) ([]*model.NotificationPreference, error) {
+	prefs := make([]*model.NotificationPreference, 0, len(req.Preferences))
+	for _, item := range req.Preferences {
+		prefs = append(prefs, &model.NotificationPreference{
+			UserID:   userID,
+			Category: item.Category,
+			Channel:  item.Channel,
+			Enabled:  *item.Enabled,
+		})
+	}
+	if err := u.prefRepo.SavePreferences(ctx, prefs); err != nil {
+		return nil, err
+	}
+
+	return u.ListPreferences(ctx, userID)
+}
+
+func (u *notificationPreferenceUsecase) CheckUnsubscribe(_ context.Context, token string) (*model.UnsubscribeResponse, error) {
+	_, category, err := u.parseUnsubscribe(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.UnsubscribeResponse{Category: category}, nil
+}
+
+func (u *notificationPreferenceUsecase) Unsubscribe(ctx context.Context, token string) (*model.UnsubscribeResponse, error) {
+	userID, category, err := u.parseUnsubscribe(token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.prefRepo.SavePreferences(ctx, []*model.NotificationPreference{{
+		UserID:   userID,
+		Category: category,
+		Channel:  consts.NotificationChannelEmail,
+		Enabled:  false,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	u.logger.Info("User unsubscribed from mail", xlogger.Int("user_id", userID), xlogger.String("category", category))
+
+	return &model.UnsubscribeResponse{Category: category, Unsubscribed: true}, nil
+}
+
+func (u *notificationPreferenceUsecase) parseUnsubscribe(token string) (int, string, error) {
+	userID, category, err := u.unsubscribe.Parse(token)
+	if err != nil || !slices.Contains(consts.NotificationCategories, category) {
+		return 0, "", apperror.BadRequest("Invalid unsubscribe link")
+	}
+	return userID, category, nil
+}
@@ -7,24 +7,50 @@ import (
 	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model"
 	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
 	"thomas.vn/apartment_service/internal/domain/usecase"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xtenant "thomas.vn/apartment_service/pkg/tenant"
 )
 
 type permissionUsecase struct {
-	logger *xlogger.Logger
-	repo   repository.PermissionRepository
+	logger   *xlogger.Logger
+	repo     repository.PermissionRepository
+	auditSvc service.AuditService
 }
 
-func NewPermissionUsecase(logger *xlogger.Logger, repo repository.PermissionRepository) usecase.PermissionUsecase {
-	return &permissionUsecase{logger: logger, repo: repo}
+func NewPermissionUsecase(logger *xlogger.Logger, repo repository.PermissionRepository, auditSvc service.AuditService) usecase.PermissionUsecase {
+	return &permissionUsecase{logger: logger, repo: repo, auditSvc: auditSvc}
 }
 
 func (u *permissionUsecase) CheckPermission(ctx context.Context, request model.CheckPermissionRequest) (bool, error) {
-	if request.RoleID == consts.UserAdmin {
-		return true, nil
+	scope, err := u.ResolveScope(ctx, request)
+	if err != nil {
+		return false, err
 	}
-	return u.repo.HasPermission(ctx, request)
+	return scope.Global || len(scope.BuildingIDs) > 0, nil
+}
+
+// ResolveScope returns the buildings the caller may act on for the requested
+// endpoint. An empty, non-global scope means the permission is denied. When
+// the request targets a building, the scope is narrowed to that building.
+func (u *permissionUsecase) ResolveScope(ctx context.Context, request model.CheckPermissionRequest) (*xtenant.Scope, error) {
+	scope := &xtenant.Scope{Global: true}
+	if request.RoleID != consts.UserAdmin {
+		var err error
+		scope, err = u.repo.ResolvePermissionScope(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if request.BuildingID == consts.GlobalBuildingID {
+		return scope, nil
+	}
+	if !scope.Allows(request.BuildingID) {
+		return &xtenant.Scope{}, nil
+	}
+	return &xtenant.Scope{BuildingIDs: []int64{request.BuildingID}}, nil
 }
 func (u *permissionUsecase) CreatePermission(ctx context.Context, req *model.CreatePermissionRequest, userID int) (*model.Permission, error) {
 	permission := &model.Permission{
@@ -35,7 +61,13 @@ func (u *permissionUsecase) CreatePermission(ctx context.Context, req *model.Cre
 		CreatedBy: userID,
 	}
 
-	return u.repo.CreatePermission(ctx, permission)
+	created, err := u.repo.CreatePermission(ctx, permission)
+	if err != nil {
+		return nil, err
+	}
+
+	u.recordAudit(ctx, consts.AuditActionPermissionCreate, consts.AuditTargetPermission, created.ID, nil, created)
+	return created, nil
 }
 func (u *permissionUsecase) GetPermissionByID(ctx context.Context, permissionID uint) (*model.Permission, error) {
 	permission, err := u.repo.GetPermissionByID(ctx, permissionID)
@@ -56,6 +88,7 @@ func (u *permissionUsecase) UpdatePermission(ctx context.Context, req *model.Upd
 		u.logger.Error("Failed to get permission", xlogger.Error(err))
 		return nil, err
 	}
+	before := *permission
 	if req.Name != "" {
 		permission.Name = req.Name
 	}
@@ -70,6 +103,96 @@ func (u *permissionUsecase) UpdatePermission(ctx context.Context, req *model.Upd
 		u.logger.Error("Failed to update permission", xlogger.Error(err))
 		return nil, err
 	}
+
+	u.recordAudit(ctx, consts.AuditActionPermissionUpdate, consts.AuditTargetPermission, updatedPermission.ID, &before, updatedPermission)
 	return updatedPermission, nil
 
 }
+
+func (u *permissionUsecase) AssignRole(ctx context.Context, req *model.AssignRoleRequest, userID int, roleID int) (*model.UserRole, error) {
+	if err := checkAssignmentScope(ctx, req.BuildingID); err != nil {
+		return nil, err
+	}
+	if roleID != consts.UserAdmin {
+		missing, err := u.repo.CountMissingPermissions(ctx, model.RoleGrantRequest{
+			UserID:       userID,
+			RoleID:       roleID,
+			TargetRoleID: req.RoleID,
+			BuildingID:   req.BuildingID,
+		})
+		if err != nil {
+			u.logger.Error("Failed to compare role permissions", xlogger.Error(err))
+			return nil, err
+		}
+		if missing > 0 {
+			return nil, apperror.Forbidden("Role %d grants permissions you do not hold", req.RoleID)
+		}
+	}
+
+	userRole := &model.UserRole{
+		UserID:     req.UserID,
+		RoleID:     req.RoleID,
+		BuildingID: req.BuildingID,
+		CreatedBy:  userID,
+		IsDeleted:  consts.NotDeleted,
+	}
+
+	created, err := u.repo.AssignRole(ctx, userRole)
+	if err != nil {
+		u.logger.Error("Failed to assign role", xlogger.Error(err))
+		return nil, err
+	}
+
+	u.recordAudit(ctx, consts.AuditActionRoleAssign, consts.AuditTargetUserRole, created.ID, nil, created)
+	return created, nil
+}
+
+func (u *permissionUsecase) RevokeRole(ctx context.Context, id uint, userID int) error {
+	userRole, err := u.repo.GetUserRoleByID(ctx, id)
+	if err != nil {
+		u.logger.Error("Failed to get user role", xlogger.Error(err))
+		return err
+	}
+	if userRole == nil {
+		return apperror.NotFound("Role assignment with ID %d not found", id)
+	}
+	if err := checkAssignmentScope(ctx, userRole.BuildingID); err != nil {
+		return err
+	}
+
+	if err := u.repo.RevokeRole(ctx, id, userID); err != nil {
+		u.logger.Error("Failed to revoke role", xlogger.Error(err))
+		return err
+	}
+
+	u.recordAudit(ctx, consts.AuditActionRoleRevoke, consts.AuditTargetUserRole, userRole.ID, userRole, nil)
+	return nil
+}
+
+// checkAssignmentScope rejects role assignments outside the caller's building
+// scope. Only globally scoped callers may touch global assignments.
+func checkAssignmentScope(ctx context.Context, buildingID int64) error {
+	scope, ok := xtenant.FromContext(ctx)
+	if !ok {
+		return apperror.Forbidden("Permission scope is missing")
+	}
+	if buildingID == consts.GlobalBuildingID && !scope.Global {
+		return apperror.Forbidden("Cannot manage global role assignments")
+	}
+	if !scope.Allows(buildingID) {
+		return apperror.Forbidden("Building %d is outside your scope", buildingID)
+	}
+	return nil
+}
+
+func (u *permissionUsecase) recordAudit(ctx context.Context, action, targetType string, targetID int64, before, after interface{}) {
+	if err := u.auditSvc.Record(ctx, model.AuditEntry{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+	}); err != nil {
+		u.logger.Warn("Record audit log failed", xlogger.Error(err))
+	}
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+// policyFunc decides whether the caller described by the request
+// satisfies a single policy for the requested resource.
+type policyFunc func(ctx context.Context, request model.PolicyRequest) (bool, error)
+
+type policyUsecase struct {
+	logger   *xlogger.Logger
+	repo     repository.PolicyRepository
+	policies map[string]policyFunc
+}
+
+func NewPolicyUsecase(logger *xlogger.Logger, repo repository.PolicyRepository) usecase.PolicyUsecase {
+	u := &policyUsecase{logger: logger, repo: repo}
+	u.policies = map[string]policyFunc{
+		consts.PolicySelf:            u.isSelf,
+		consts.PolicyArticleOwner:    u.isArticleOwner,
+		consts.PolicyChatGroupMember: u.isChatGroupMember,
+	}
+	return u
+}
+
+func (u *policyUsecase) Evaluate(ctx context.Context, request model.PolicyRequest) (bool, error) {
+	if request.RoleID == consts.UserAdmin {
+		return true, nil
+	}
+
+	fn, ok := u.policies[request.Policy]
+	if !ok {
+		u.logger.Warn("Unknown policy", xlogger.String("policy", request.Policy))
+		return false, nil
+	}
+
+	return fn(ctx, request)
+}
+
+func (u *policyUsecase) isSelf(_ context.Context, request model.PolicyRequest) (bool, error) {
+	return int64(request.UserID) == request.ResourceID, nil
+}
+
+func (u *policyUsecase) isArticleOwner(ctx context.Context, request model.PolicyRequest) (bool, error) {
+	return u.repo.IsArticleOwner(ctx, request.UserID, request.ResourceID)
+}
+
+func (u *policyUsecase) isChatGroupMember(ctx context.Context, request model.PolicyRequest) (bool, error) {
+	return u.repo.IsChatGroupMember(ctx, request.UserID, request.ResourceID)
+}
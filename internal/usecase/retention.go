@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+	xuser "thomas.vn/apartment_service/internal/domain/model/user"
+	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
+	"thomas.vn/apartment_service/internal/domain/usecase"
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+// retentionTables lists the soft deletable tables, children before parents.
+// Chat messages are purged beforehand with their own child rows.
+var retentionTables = []string{
+	"chat_group_members",
+	"chat_groups",
+	"articles",
+}
+
+type retentionUsecase struct {
+	logger *xlogger.Logger
+	repo   repository.RetentionRepository
+	queue  service.QueueService
+	days   int
+}
+
+func NewRetentionUsecase(logger *xlogger.Logger, repo repository.RetentionRepository, queue service.QueueService, days int) usecase.RetentionUsecase {
+	return &retentionUsecase{
+		logger: logger,
+		repo:   repo,
+		queue:  queue,
+		days:   days,
+	}
+}
+
+func (u *retentionUsecase) PurgeDeleted(ctx context.Context) error {
+	if u.days <= 0 {
+		u.logger.Warn("Retention days not configured, skipping purge")
+		return nil
+	}
+
+	before := xutils.GetTimeNow().AddDate(0, 0, -u.days)
+
+	messages, attachments, err := u.repo.PurgeDeletedMessages(ctx, before)
+	if err != nil {
+		return err
+	}
+	u.logger.Info(
+		"Purged deleted rows",
+		xlogger.String("table", "chat_messages"),
+		xlogger.Int64("rows", messages),
+	)
+	u.deleteStoredFiles(ctx, attachments)
+
+	for _, table := range retentionTables {
+		purged, err := u.repo.PurgeDeleted(ctx, table, before)
+		if err != nil {
+			return err
+		}
+
+		u.logger.Info(
+			"Purged deleted rows",
+			xlogger.String("table", table),
+			xlogger.Int64("rows", purged),
+		)
+	}
+
+	// Users are anonymised rather than deleted: messages, memberships and
+	// reports keep pointing at them.
+	anonymized, err := u.repo.AnonymizeDeletedUsers(ctx, before)
+	if err != nil {
+		return err
+	}
+	u.logger.Info("Anonymized deleted users", xlogger.Int64("rows", anonymized))
+
+	return nil
+}
+
+// deleteStoredFiles queues the removal of the files behind purged
+// attachments. Failures only leave the file behind, so they are logged.
+func (u *retentionUsecase) deleteStoredFiles(ctx context.Context, attachments []*chatmessage.Attachment) {
+	for _, attachment := range attachments {
+		if attachment.StorageKey == "" {
+			continue
+		}
+
+		if err := u.queue.PublishMessage(
+			ctx,
+			consts.DeleteCloudinaryAssetJobType,
+			&xuser.DeleteCloudAssetPayload{
+				PublicID:     attachment.StorageKey,
+				ResourceType: attachment.ResourceType,
+				DeliveryType: "authenticated",
+			},
+		); err != nil {
+			u.logger.Warn(
+				"Publish delete attachment file job failed",
+				xlogger.Error(err),
+				xlogger.Int("attachment_id", attachment.ID),
+			)
+		}
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 
 	"thomas.vn/apartment_service/internal/domain/apperror"
+	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
 	xuser "thomas.vn/apartment_service/internal/domain/model/user"
 	"thomas.vn/apartment_service/internal/domain/repository"
+	"thomas.vn/apartment_service/internal/domain/service"
 	utotp "thomas.vn/apartment_service/internal/domain/usecase"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
 	pkgtotp "thomas.vn/apartment_service/pkg/totp"
@@ -14,15 +17,18 @@ import (
 type totpUsecase struct {
 	logger   *xlogger.Logger
 	userRepo repository.UserRepository
+	auditSvc service.AuditService
 }
 
 func NewTotpUsecase(
 	logger *xlogger.Logger,
 	userRepo repository.UserRepository,
+	auditSvc service.AuditService,
 ) utotp.TotpUsecase {
 	return &totpUsecase{
 		logger:   logger,
 		userRepo: userRepo,
+		auditSvc: auditSvc,
 	}
 }
 
@@ -57,7 +63,12 @@ func (u *totpUsecase) Save(
 		return apperror.BadRequest("Invalid token")
 	}
 
-	return u.userRepo.UpdateTotpSecret(ctx, int64(user.ID), &secret)
+	if err := u.userRepo.UpdateTotpSecret(ctx, int64(user.ID), &secret); err != nil {
+		return err
+	}
+
+	u.recordTotpChange(ctx, consts.AuditActionTotpEnable, user, true)
+	return nil
 }
 
 func (u *totpUsecase) Verify(
@@ -89,5 +100,22 @@ func (u *totpUsecase) Disable(
 		return apperror.BadRequest("Invalid token")
 	}
 
-	return u.userRepo.UpdateTotpSecret(ctx, int64(user.ID), nil)
+	if err := u.userRepo.UpdateTotpSecret(ctx, int64(user.ID), nil); err != nil {
+		return err
+	}
+
+	u.recordTotpChange(ctx, consts.AuditActionTotpDisable, user, false)
+	return nil
+}
+
+func (u *totpUsecase) recordTotpChange(ctx context.Context, action string, user *xuser.User, enabled bool) {
+	if err := u.auditSvc.Record(ctx, model.AuditEntry{
+		Action:     action,
+		TargetType: consts.AuditTargetUser,
+		TargetID:   int64(user.ID),
+		Before:     map[string]bool{"totp_enabled": !enabled},
+		After:      map[string]bool{"totp_enabled": enabled},
+	}); err != nil {
+		u.logger.Warn("Record audit log failed", xlogger.Error(err))
+	}
 }
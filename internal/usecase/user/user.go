@@ -7,11 +7,13 @@ import (
 
 	"thomas.vn/apartment_service/internal/domain/apperror"
 	"thomas.vn/apartment_service/internal/domain/consts"
+	"thomas.vn/apartment_service/internal/domain/model"
 	xuser "thomas.vn/apartment_service/internal/domain/model/user"
 	"thomas.vn/apartment_service/internal/domain/repository"
 	"thomas.vn/apartment_service/internal/domain/service"
 	user2 "thomas.vn/apartment_service/internal/domain/usecase"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
+	"thomas.vn/apartment_service/pkg/query"
 )
 
 type userUsecase struct {
@@ -20,25 +22,31 @@ type userUsecase struct {
 	cacheSvc    service.CacheService
 	fileService service.FileService
 	queue       service.QueueService
+	auditSvc    service.AuditService
+	notifier    service.RealtimeNotifier
+	notifySvc   service.NotificationService
 }
 
-func NewUserUsecase(logger *xlogger.Logger, userRepo repository.UserRepository, cacheSvc service.CacheService, fileService service.FileService, queue service.QueueService) user2.UserUsecase {
+func NewUserUsecase(logger *xlogger.Logger, userRepo repository.UserRepository, cacheSvc service.CacheService, fileService service.FileService, queue service.QueueService, auditSvc service.AuditService, notifier service.RealtimeNotifier, notifySvc service.NotificationService) user2.UserUsecase {
 	return &userUsecase{
 		logger:      logger,
 		userRepo:    userRepo,
 		cacheSvc:    cacheSvc,
 		fileService: fileService,
 		queue:       queue,
+		auditSvc:    auditSvc,
+		notifier:    notifier,
+		notifySvc:   notifySvc,
 	}
 }
 
 func (u *userUsecase) CreateUser(ctx context.Context, req *xuser.CreateUserRequest) (*xuser.User, error) {
-	existingUser, err := u.userRepo.GetUserByEmail(ctx, req.Email)
+	exists, err := u.userRepo.EmailExists(ctx, req.Email)
 	if err != nil {
 		u.logger.Error("Failed to check existing user", xlogger.Error(err))
 		return nil, err
 	}
-	if existingUser != nil {
+	if exists {
 		return nil, consts.EmailAlreadyExistsError(req.Email)
 	}
 
@@ -73,11 +81,16 @@ func (u *userUsecase) GetUser(ctx context.Context, id uint) (*xuser.User, error)
 }
 
 func (u *userUsecase) UpdateUser(ctx context.Context, req *xuser.UpdateUserRequest) (*xuser.User, error) {
+	if req.CallerRoleID != consts.UserAdmin && (req.RoleID != nil || req.IsActive != nil) {
+		return nil, apperror.Forbidden("Only admins can change role_id or is_active")
+	}
+
 	user, err := u.GetUser(ctx, req.ID)
 	if err != nil {
 		u.logger.Error("Failed to get user", xlogger.Error(err))
 		return nil, err
 	}
+	before := *user
 
 	if req.Password != "" {
 		// Note: Password should be hashed before storing
@@ -86,11 +99,14 @@ func (u *userUsecase) UpdateUser(ctx context.Context, req *xuser.UpdateUserReque
 	if req.FullName != "" {
 		user.FullName = req.FullName
 	}
-	if req.RoleID != 0 {
-		user.RoleID = req.RoleID
+	if req.RoleID != nil {
+		user.RoleID = *req.RoleID
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
 	}
-	if req.IsActive != 0 {
-		user.IsActive = req.IsActive
+	if req.Locale != "" {
+		user.Locale = req.Locale
 	}
 
 	updatedUser, err := u.userRepo.UpdateUser(ctx, user)
@@ -99,6 +115,16 @@ func (u *userUsecase) UpdateUser(ctx context.Context, req *xuser.UpdateUserReque
 		return nil, err
 	}
 
+	if err := u.auditSvc.Record(ctx, model.AuditEntry{
+		Action:     consts.AuditActionUserUpdate,
+		TargetType: consts.AuditTargetUser,
+		TargetID:   int64(updatedUser.ID),
+		Before:     &before,
+		After:      updatedUser,
+	}); err != nil {
+		u.logger.Warn("Record audit log failed", xlogger.Error(err))
+	}
+
 	return updatedUser, nil
 }
 
@@ -114,11 +140,41 @@ func (u *userUsecase) DeleteUser(ctx context.Context, id uint) error {
 		return err
 	}
 
+	if err := u.auditSvc.Record(ctx, model.AuditEntry{
+		Action:     consts.AuditActionUserDelete,
+		TargetType: consts.AuditTargetUser,
+		TargetID:   int64(user.ID),
+		Before:     user,
+	}); err != nil {
+		u.logger.Warn("Record audit log failed", xlogger.Error(err))
+	}
+
 	return nil
 }
 
-func (u *userUsecase) ListUsers(ctx context.Context, req *xuser.ListUserRequest, filters *xuser.UserFilters) ([]*xuser.User, int64, error) {
-	return u.userRepo.ListUsers(ctx, req, filters)
+func (u *userUsecase) RestoreUser(ctx context.Context, id uint) error {
+	restored, err := u.userRepo.RestoreUser(ctx, id)
+	if err != nil {
+		u.logger.Error("Failed to restore user", xlogger.Error(err))
+		return err
+	}
+	if !restored {
+		return apperror.NotFound("Deleted user with ID %d not found", id)
+	}
+
+	if err := u.auditSvc.Record(ctx, model.AuditEntry{
+		Action:     consts.AuditActionUserRestore,
+		TargetType: consts.AuditTargetUser,
+		TargetID:   int64(id),
+	}); err != nil {
+		u.logger.Warn("Record audit log failed", xlogger.Error(err))
+	}
+
+	return nil
+}
+
+func (u *userUsecase) ListUsers(ctx context.Context, req *xuser.ListUserRequest, spec *query.Spec) ([]*xuser.User, int64, error) {
+	return u.userRepo.ListUsers(ctx, req, spec)
 }
 
 func (u *userUsecase) DeleteUsersCreatedBefore(_ context.Context, days time.Time) error {
@@ -170,6 +226,7 @@ func (u *userUsecase) ProcessUploadLocal(ctx context.Context, req *xuser.UploadA
 		_ = u.fileService.Delete(oldPath)
 	}
 
+	u.notifyAvatarUpdated(ctx, req.UserID, userEntity.Avatar)
 	return nil
 }
 
@@ -236,5 +293,27 @@ func (u *userUsecase) ProcessUploadCloud(ctx context.Context, req *xuser.UploadA
 		}
 	}
 
+	u.notifyAvatarUpdated(ctx, req.UserID, user.Avatar)
 	return nil
 }
+
+// notifyAvatarUpdated tells the user's open sessions that the upload they
+// started has finished.
+func (u *userUsecase) notifyAvatarUpdated(ctx context.Context, userID uint, avatar string) {
+	event := &xuser.AvatarUpdatedEvent{UserID: userID, Avatar: avatar}
+	if err := u.notifier.NotifyUser(ctx, int(userID), consts.WSEventAvatarUpdated, event); err != nil {
+		u.logger.Warn("Notify avatar updated failed", xlogger.Error(err), xlogger.Uint("user_id", userID))
+	}
+
+	entry := model.NotificationEntry{
+		UserID:   int(userID),
+		Type:     consts.NotificationTypeAvatarUpdated,
+		Category: consts.NotificationCategoryAccount,
+		Title:    "Avatar updated",
+		Body:     "Your new avatar has finished processing.",
+		Link:     "/account/profile",
+	}
+	if err := u.notifySvc.Notify(ctx, entry); err != nil {
+		u.logger.Warn("Store avatar notification failed", xlogger.Error(err), xlogger.Uint("user_id", userID))
+	}
+}
@@ -3,8 +3,10 @@ package usecase
 import (
 	"context"
 
+	"thomas.vn/apartment_service/internal/domain/consts"
 	"thomas.vn/apartment_service/internal/domain/model/chatgroup"
 	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
+	"thomas.vn/apartment_service/internal/domain/service"
 	"thomas.vn/apartment_service/internal/domain/usecase"
 	xlogger "thomas.vn/apartment_service/pkg/logger"
 )
@@ -13,31 +15,58 @@ type ChatUcase struct {
 	logger        *xlogger.Logger
 	chatGroupUC   usecase.ChatGroupUsecase
 	chatMessageUC usecase.ChatMessageUsecase
+	realtime      service.RealtimeService
+	// filters run in order on the text of every sent or edited message.
+	filters []service.MessageFilter
 }
 
 func NewChatUcase(
 	logger *xlogger.Logger,
 	chatGroupUC usecase.ChatGroupUsecase,
 	chatMessageUC usecase.ChatMessageUsecase,
+	realtime service.RealtimeService,
+	filters ...service.MessageFilter,
 ) *ChatUcase {
 	return &ChatUcase{
 		logger:        logger,
 		chatGroupUC:   chatGroupUC,
 		chatMessageUC: chatMessageUC,
+		realtime:      realtime,
+		filters:       filters,
 	}
 }
-func (u *ChatUcase) CreateRoom(ctx context.Context, req *chatgroup.CreateChatGroupRequest) (int, error) {
+
+// CreateRoom creates a chat group for the WebSocket and REST APIs.
+func (u *ChatUcase) CreateRoom(ctx context.Context, req *chatgroup.CreateChatGroupRequest) (*chatgroup.ChatGroup, error) {
 
 	group, err := u.chatGroupUC.CreateChatGroup(ctx, req)
 	if err != nil {
 		u.logger.Error("CreateRoom failed", xlogger.Error(err))
-		return 0, err
+		return nil, err
 	}
 
-	return group.ID, nil
+	return group, nil
 }
 
+func (u *ChatUcase) JoinRoom(ctx context.Context, chatGroupID int, userID int) error {
+	return u.chatGroupUC.EnsureMember(ctx, int64(chatGroupID), int64(userID))
+}
+
+// SendMessage stores a message for the WebSocket and REST APIs and pushes
+// it to the room.
 func (u *ChatUcase) SendMessage(ctx context.Context, req *chatmessage.CreateChatMessageRequest) (*chatmessage.Response, error) {
+	if err := u.chatGroupUC.EnsureMember(ctx, int64(req.ChatGroupID), int64(req.UserIDSender)); err != nil {
+		return nil, err
+	}
+	if err := u.chatGroupUC.EnsureNotBlocked(ctx, int64(req.ChatGroupID), int64(req.UserIDSender)); err != nil {
+		return nil, err
+	}
+
+	text, err := u.filter(ctx, req.MessageText)
+	if err != nil {
+		return nil, err
+	}
+	req.MessageText = text
 
 	resp, err := u.chatMessageUC.SendMessage(ctx, req)
 	if err != nil {
@@ -45,5 +74,53 @@ func (u *ChatUcase) SendMessage(ctx context.Context, req *chatmessage.CreateChat
 		return nil, err
 	}
 
+	if err := u.realtime.BroadcastToRoom(ctx, req.ChatGroupID, consts.WSEventSendMessage, resp); err != nil {
+		u.logger.Warn("Broadcast chat message failed", xlogger.Error(err), xlogger.Int("chat_group_id", req.ChatGroupID))
+	}
+
 	return resp, nil
 }
+
+func (u *ChatUcase) MarkRead(ctx context.Context, userID int, req *chatgroup.MarkReadRequest) (*chatgroup.ReadReceipt, error) {
+	return u.chatGroupUC.MarkRead(ctx, int64(userID), req)
+}
+
+// EditMessage runs the new text through the same filters as sent messages,
+// so edits cannot slip banned words past them.
+func (u *ChatUcase) EditMessage(ctx context.Context, userID int, req *chatmessage.EditChatMessageRequest) (*chatmessage.Response, error) {
+	text, err := u.filter(ctx, req.MessageText)
+	if err != nil {
+		return nil, err
+	}
+	req.MessageText = text
+
+	return u.chatMessageUC.EditMessage(ctx, userID, req)
+}
+
+// filter runs text through the message filters in order.
+func (u *ChatUcase) filter(ctx context.Context, text string) (string, error) {
+	for _, f := range u.filters {
+		var err error
+		text, err = f.FilterMessage(ctx, text)
+		if err != nil {
+			return "", err
+		}
+	}
+	return text, nil
+}
+
+func (u *ChatUcase) DeleteMessage(ctx context.Context, userID int, id int) (*chatmessage.DeletedEvent, error) {
+	return u.chatMessageUC.DeleteMessage(ctx, userID, id)
+}
+
+func (u *ChatUcase) React(ctx context.Context, userID int, req *chatmessage.ReactRequest) (*chatmessage.ReactionEvent, error) {
+	return u.chatMessageUC.React(ctx, userID, req)
+}
+
+func (u *ChatUcase) Resume(ctx context.Context, userID int, chatGroupID int, afterSeq *int64) (*chatmessage.Replay, error) {
+	return u.chatMessageUC.Replay(ctx, userID, chatGroupID, afterSeq)
+}
+
+func (u *ChatUcase) Ack(ctx context.Context, userID int, req *chatgroup.AckRequest) (int64, error) {
+	return u.chatGroupUC.AckDelivery(ctx, int64(userID), req)
+}
@@ -44,6 +44,12 @@ func NewRedisCache(cfg *RedisConfig) (*RedisCache, error) {
 	}, nil
 }
 
+// Client returns the underlying Redis client, for features beyond caching
+// such as pub/sub.
+func (c *RedisCache) Client() *redis.Client {
+	return c.client
+}
+
 func (c *RedisCache) Close() error {
 	return c.client.Close()
 }
@@ -18,6 +18,7 @@ type SearchOptions struct {
 	Sort           []map[string]string
 	SourceIncludes []string
 	SourceExcludes []string
+	Highlight      map[string]interface{}
 	Timeout        time.Duration
 }
 
@@ -91,6 +92,38 @@ func SearchTotal(searchResult map[string]interface{}) (int64, error) {
 	return int64(totalValue), nil
 }
 
+// HitHighlights returns the highlighted fragments of a hit for the fields,
+// in field order.
+func HitHighlights(hit map[string]interface{}, fields ...string) []string {
+	highlight, ok := hit["highlight"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var fragments []string
+	for _, field := range fields {
+		values, ok := highlight[field].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, v := range values {
+			if fragment, ok := v.(string); ok {
+				fragments = append(fragments, fragment)
+			}
+		}
+	}
+	return fragments
+}
+
+// TermsQuery create a terms query
+func TermsQuery(field string, values interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"terms": map[string]interface{}{
+			field: values,
+		},
+	}
+}
+
 // MatchQuery create a match query
 func MatchQuery(field string, value interface{}) map[string]interface{} {
 	return map[string]interface{}{
@@ -716,6 +749,9 @@ func buildSearchBody(query map[string]interface{}, options *SearchOptions) ([]by
 				"excludes": options.SourceExcludes,
 			}
 		}
+		if len(options.Highlight) > 0 {
+			body["highlight"] = options.Highlight
+		}
 	}
 
 	return json.Marshal(body)
@@ -23,7 +23,7 @@ func RequestLogging(logger *xlogger.Logger) echo.MiddlewareFunc {
 				xlogger.String("uri", req.RequestURI),
 				xlogger.String("user_agent", req.UserAgent()),
 				xlogger.Int("status", res.Status),
-				xlogger.String("id", req.Header.Get(echo.HeaderXRequestID)),
+				xlogger.String("id", res.Header().Get(echo.HeaderXRequestID)),
 				xlogger.Int64("latency", int64(stop.Sub(start))),
 				xlogger.String("latency_human", stop.Sub(start).String()),
 				xlogger.Int64("bytes_in", req.ContentLength),
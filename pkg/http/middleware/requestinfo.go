@@ -0,0 +1,31 @@
+package xmiddleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	xrequestinfo "thomas.vn/apartment_service/pkg/requestinfo"
+)
+
+// RequestInfo stores the client IP, user agent and request ID in the request
+// context so that layers without access to echo.Context can read them.
+func RequestInfo() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+			if requestID == "" {
+				requestID = req.Header.Get(echo.HeaderXRequestID)
+			}
+
+			ctx := xrequestinfo.WithInfo(req.Context(), xrequestinfo.Info{
+				IP:        c.RealIP(),
+				UserAgent: req.UserAgent(),
+				RequestID: requestID,
+			})
+			c.SetRequest(req.WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
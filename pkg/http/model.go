@@ -73,9 +73,10 @@ type SortOptions struct {
 }
 
 type PaginationResponse struct {
-	Page      int         `json:"page"`
-	PageSize  int         `json:"pageSize"`
-	TotalItem int64       `json:"totalItem"`
-	TotalPage int64       `json:"totalPage"`
-	Items     interface{} `json:"items"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"pageSize"`
+	TotalItem  int64       `json:"totalItem"`
+	TotalPage  int64       `json:"totalPage"`
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"nextCursor,omitempty"`
 }
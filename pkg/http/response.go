@@ -1,8 +1,11 @@
 package xhttp
 
 import (
+	"encoding/csv"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"thomas.vn/apartment_service/pkg/query"
@@ -108,11 +111,22 @@ func AppErrorResponse(c echo.Context, err error) error {
 	return InternalServerErrorResponse(c)
 }
 
+// PaginationOption customizes a pagination response.
+type PaginationOption func(*PaginationResponse)
+
+// WithNextCursor sets the cursor clients pass back to fetch the next page.
+func WithNextCursor(cursor string) PaginationOption {
+	return func(r *PaginationResponse) {
+		r.NextCursor = cursor
+	}
+}
+
 func PaginationListResponse(
 	c echo.Context,
 	req *query.PaginationOptions,
 	items interface{},
 	total int64,
+	opts ...PaginationOption,
 ) error {
 
 	page := req.Page
@@ -130,13 +144,18 @@ func PaginationListResponse(
 		totalPage = (total + int64(limit) - 1) / int64(limit)
 	}
 
-	return DataResponse(c, http.StatusOK, &PaginationResponse{
+	res := &PaginationResponse{
 		Page:      page,
 		PageSize:  limit,
 		TotalItem: total,
 		TotalPage: totalPage,
 		Items:     items,
-	})
+	}
+	for _, opt := range opts {
+		opt(res)
+	}
+
+	return DataResponse(c, http.StatusOK, res)
 }
 
 func OldSuccessResponse(c echo.Context, data interface{}) error {
@@ -176,3 +195,36 @@ func OldBadRequestResponse(c echo.Context, data interface{}) error {
 func OldInternalErrorResponse(c echo.Context) error {
 	return OldErrorResponse(c, "Internal Server Error", 500, "Something went wrong")
 }
+
+// CSVResponse writes header and rows as a CSV file attachment.
+func CSVResponse(c echo.Context, filename string, header []string, rows [][]string) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(res)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		safe := make([]string, len(row))
+		for i, cell := range row {
+			safe[i] = csvSafeCell(cell)
+		}
+		if err := w.Write(safe); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvSafeCell prevents spreadsheet applications from evaluating cell
+// values that start with a formula character.
+func csvSafeCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -27,6 +27,7 @@ func NewHTTPServer(logger *xlogger.Logger, host string, port int, h Handler) *Se
 	// Setup basic middleware
 	e.Use(middleware.RemoveTrailingSlash())
 	e.Use(middleware.Recover())
+	e.Use(middleware.RequestID())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{echo.OPTIONS, echo.HEAD, echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE},
@@ -35,6 +36,7 @@ func NewHTTPServer(logger *xlogger.Logger, host string, port int, h Handler) *Se
 
 	// Setup custom middleware
 	e.Use(xmiddleware.RequestLogging(logger))
+	e.Use(xmiddleware.RequestInfo())
 
 	// Customize Echo server
 	e.HideBanner = true
@@ -51,6 +51,15 @@ var (
 		"image/png":  true,
 		"image/gif":  true,
 	}
+
+	allowedDocumentMime = map[string]string{
+		".pdf":  "application/pdf",
+		".doc":  "application/msword",
+		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		".xls":  "application/vnd.ms-excel",
+		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		".txt":  "text/plain",
+	}
 )
 
 func init() {
@@ -404,3 +413,58 @@ func ValidateImageFile(file *multipart.FileHeader, maxSize int64) error {
 
 	return nil
 }
+
+// IsImageFile reports whether the file has an image extension accepted by
+// ValidateImageFile.
+func IsImageFile(file *multipart.FileHeader) bool {
+	return file != nil && allowedImageExt[strings.ToLower(filepath.Ext(file.Filename))]
+}
+
+// ValidateDocumentFile checks a non-image attachment: pdf, doc, docx, xls,
+// xlsx or txt. Octet-stream content types are trusted on the extension.
+func ValidateDocumentFile(file *multipart.FileHeader, maxSize int64) error {
+	if file == nil {
+		return NewAppError(
+			"ERR_INVALID_DOCUMENT",
+			"file",
+			"Invalid document file",
+			http.StatusBadRequest,
+		)
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	mime, ok := allowedDocumentMime[ext]
+	if !ok {
+		return NewAppError(
+			"ERR_INVALID_DOCUMENT_TYPE",
+			"file",
+			"Only pdf, doc, docx, xls, xlsx, txt files are allowed",
+			http.StatusBadRequest,
+		)
+	}
+
+	contentType := file.Header.Get("Content-Type")
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = strings.TrimSpace(contentType[:i])
+	}
+	if contentType != "" && contentType != mime &&
+		contentType != "application/octet-stream" && contentType != "binary/octet-stream" {
+		return NewAppError(
+			"ERR_INVALID_DOCUMENT_MIME",
+			"file",
+			"Invalid document content type",
+			http.StatusBadRequest,
+		)
+	}
+
+	if file.Size > maxSize {
+		return NewAppError(
+			"ERR_DOCUMENT_TOO_LARGE",
+			"file",
+			"Document size exceeds limit",
+			http.StatusBadRequest,
+		)
+	}
+
+	return nil
+}
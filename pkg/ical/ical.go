@@ -0,0 +1,222 @@
+// Package xical writes iCalendar (RFC 5545) files, such as the .ics
+// invites attached to mail.
+package xical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Methods of an iTIP (RFC 5546) calendar.
+const (
+	MethodPublish = "PUBLISH"
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+)
+
+const (
+	defaultProdID = "-//Apartment Business//Apartment Service//EN"
+	timeFormat    = "20060102T150405Z"
+	// maxLineOctets is the longest content line before it is folded.
+	maxLineOctets = 75
+)
+
+// Person is an organizer or attendee.
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Event is a VEVENT. Times are written in UTC.
+type Event struct {
+	// UID identifies the event across updates; clients replace an event
+	// with the same UID and a higher Sequence.
+	UID         string
+	Sequence    int
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	Organizer   *Person
+	Attendees   []Person
+	// Reminders are how long before Start to show an alarm.
+	Reminders []time.Duration
+	// Cancelled marks the event as cancelled, used with MethodCancel.
+	Cancelled bool
+}
+
+// Calendar is a VCALENDAR holding events.
+type Calendar struct {
+	ProdID string
+	// Method is set for invites sent by mail; leave it empty for plain
+	// calendar files.
+	Method string
+	Events []Event
+}
+
+// ContentType is the MIME type of the calendar, including its method.
+func (c *Calendar) ContentType() string {
+	if c.Method == "" {
+		return "text/calendar; charset=UTF-8"
+	}
+	return "text/calendar; charset=UTF-8; method=" + c.Method
+}
+
+// Bytes encodes the calendar with CRLF line endings and folded lines.
+func (c *Calendar) Bytes() []byte {
+	w := &writer{}
+	prodID := c.ProdID
+	if prodID == "" {
+		prodID = defaultProdID
+	}
+	stamp := time.Now().UTC().Format(timeFormat)
+
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:" + prodID)
+	w.line("CALSCALE:GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD:" + c.Method)
+	}
+	for _, e := range c.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + escapeText(e.UID))
+		w.line("DTSTAMP:" + stamp)
+		w.line("SEQUENCE:" + fmt.Sprint(e.Sequence))
+		w.line("DTSTART:" + e.Start.UTC().Format(timeFormat))
+		w.line("DTEND:" + e.End.UTC().Format(timeFormat))
+		w.line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			w.line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if e.Location != "" {
+			w.line("LOCATION:" + escapeText(e.Location))
+		}
+		if e.URL != "" {
+			w.line("URL:" + stripControl(e.URL))
+		}
+		if e.Organizer != nil {
+			w.line("ORGANIZER" + cnParam(e.Organizer.Name) + ":mailto:" + stripControl(e.Organizer.Email))
+		}
+		for _, a := range e.Attendees {
+			w.line("ATTENDEE" + cnParam(a.Name) + ";ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=TRUE:mailto:" + stripControl(a.Email))
+		}
+		if e.Cancelled {
+			w.line("STATUS:CANCELLED")
+		} else {
+			w.line("STATUS:CONFIRMED")
+		}
+		for _, r := range e.Reminders {
+			w.line("BEGIN:VALARM")
+			w.line("ACTION:DISPLAY")
+			w.line("DESCRIPTION:" + escapeText(e.Summary))
+			w.line("TRIGGER:-" + formatDuration(r))
+			w.line("END:VALARM")
+		}
+		w.line("END:VEVENT")
+	}
+	w.line("END:VCALENDAR")
+
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// line writes a content line, folding it into continuation lines that
+// start with a space once it exceeds 75 octets. Folds never split a UTF-8
+// sequence.
+func (w *writer) line(s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space of a continuation line counts toward its length.
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeText escapes a TEXT value (RFC 5545 section 3.3.11).
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// cnParam returns the CN parameter for name, quoted when it contains
+// characters that are not allowed in a bare parameter value.
+func cnParam(name string) string {
+	name = strings.ReplaceAll(stripControl(name), `"`, "'")
+	if name == "" {
+		return ""
+	}
+	if strings.ContainsAny(name, ";:,") {
+		return `;CN="` + name + `"`
+	}
+	return ";CN=" + name
+}
+
+// stripControl removes control characters, such as CR and LF, from values
+// written without TEXT escaping, so they cannot start a new property.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// formatDuration formats d as an RFC 5545 duration, e.g. P1D or PT1H30M.
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+
+	var b strings.Builder
+	b.WriteString("P")
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if hours > 0 || minutes > 0 || seconds > 0 || days == 0 {
+		b.WriteString("T")
+		if hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes > 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+		if seconds > 0 || (hours == 0 && minutes == 0) {
+			fmt.Fprintf(&b, "%dS", seconds)
+		}
+	}
+	return b.String()
+}
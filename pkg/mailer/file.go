@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"thomas.vn/apartment_service/internal/domain/repository"
+)
+
+// FileMailer writes every mail as an .eml file into a maildir, for local
+// development. Files are written to tmp/ and moved to new/ once complete,
+// so readers never see a half-written mail.
+type FileMailer struct {
+	dir  string
+	from Sender
+}
+
+func NewFileMailer(dir string, from Sender) (*FileMailer, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, data repository.MailData) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	msg, messageID, err := buildMessage(m.from, data)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%d.%s.eml", time.Now().UnixNano(), uuid.NewString())
+	tmp := filepath.Join(m.dir, "tmp", name)
+	if err := os.WriteFile(tmp, msg, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, filepath.Join(m.dir, "new", name)); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
+
+func (m *FileMailer) Close() error {
+	return nil
+}
@@ -2,6 +2,20 @@ package mail
 
 import (
 	"net/smtp"
+	"sync"
+	"time"
+)
+
+// TLS modes of the SMTP transport.
+const (
+	TLSStartTLS = "starttls"
+	TLSImplicit = "implicit"
+	TLSNone     = "none"
+)
+
+const (
+	defaultTimeout  = 30 * time.Second
+	defaultPoolSize = 2
 )
 
 type SMTPConfig struct {
@@ -9,29 +23,60 @@ type SMTPConfig struct {
 	Port string
 	User string
 	Pass string
+	// TLS is starttls (default), implicit for SMTPS (usually port 465) or
+	// none for local relays.
+	TLS string
+	// Timeout bounds dialing and every send. Defaults to 30s.
+	Timeout time.Duration
+	// PoolSize is the number of idle connections kept open between sends.
+	PoolSize int
+}
+
+// Sender is the From address of outgoing mail.
+type Sender struct {
+	Name  string
+	Email string
 }
 
+// Mailer sends mail over SMTP, reusing authenticated connections.
 type Mailer struct {
-	auth        smtp.Auth
-	host        string
-	addr        string
-	from        string // display format: "Name <email@host>"
-	senderEmail string // bare email address for SMTP envelope
+	cfg  SMTPConfig
+	auth smtp.Auth
+	addr string
+	from Sender
+	idle chan *conn
+
+	mu     sync.Mutex
+	closed bool
 }
 
-func NewMailer(cfg SMTPConfig, from string) *Mailer {
-	auth := smtp.PlainAuth(
-		"",
-		cfg.User,
-		cfg.Pass,
-		cfg.Host,
-	)
+func NewMailer(cfg SMTPConfig, fromName string) *Mailer {
+	if cfg.TLS == "" {
+		cfg.TLS = TLSStartTLS
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = defaultPoolSize
+	}
+
+	var auth smtp.Auth
+	if cfg.User != "" {
+		auth = smtp.PlainAuth(
+			"",
+			cfg.User,
+			cfg.Pass,
+			cfg.Host,
+		)
+	}
 
 	return &Mailer{
-		auth:        auth,
-		host:        cfg.Host,
-		addr:        cfg.Host + ":" + cfg.Port,
-		from:        from,
-		senderEmail: cfg.User, // SMTP user is the sender address
+		cfg:  cfg,
+		auth: auth,
+		addr: cfg.Host + ":" + cfg.Port,
+		// SMTP user is the sender address
+		from: Sender{Name: fromName, Email: cfg.User},
+		idle: make(chan *conn, cfg.PoolSize),
 	}
 }
@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"context"
+	"sync"
+
+	"thomas.vn/apartment_service/internal/domain/repository"
+)
+
+// SentMail is a mail captured by MemoryMailer.
+type SentMail struct {
+	repository.MailData
+	MessageID string
+	// Raw is the encoded message as the SMTP transport would send it.
+	Raw []byte
+}
+
+// MemoryMailer keeps sent mail in memory instead of delivering it, so tests
+// can assert on what was sent.
+type MemoryMailer struct {
+	from Sender
+
+	mu   sync.Mutex
+	sent []SentMail
+}
+
+func NewMemoryMailer(from Sender) *MemoryMailer {
+	return &MemoryMailer{from: from}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, data repository.MailData) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	raw, messageID, err := buildMessage(m.from, data)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, SentMail{MailData: data, MessageID: messageID, Raw: raw})
+	return messageID, nil
+}
+
+// Sent returns the captured mail, oldest first.
+func (m *MemoryMailer) Sent() []SentMail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]SentMail(nil), m.sent...)
+}
+
+// SentTo returns the captured mail addressed to email, oldest first.
+func (m *MemoryMailer) SentTo(email string) []SentMail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []SentMail
+	for _, s := range m.sent {
+		if s.Email == email {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Last returns the most recent mail.
+func (m *MemoryMailer) Last() (SentMail, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.sent) == 0 {
+		return SentMail{}, false
+	}
+	return m.sent[len(m.sent)-1], true
+}
+
+// Reset drops the captured mail.
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sent = nil
+}
+
+func (m *MemoryMailer) Close() error {
+	return nil
+}
@@ -0,0 +1,148 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"thomas.vn/apartment_service/internal/domain/repository"
+)
+
+// base64LineLength is the line length of base64 encoded attachments
+// (RFC 2045).
+const base64LineLength = 76
+
+// buildMessage encodes the mail. The body is multipart/alternative with the
+// plain-text part first, so clients pick the HTML part when they can show
+// it; with attachments it is wrapped in multipart/mixed. Every transport
+// sends the same bytes. It also returns the Message-ID.
+func buildMessage(from Sender, data repository.MailData) ([]byte, string, error) {
+	var body bytes.Buffer
+	alt := multipart.NewWriter(&body)
+	if err := writeAlternatives(alt, data); err != nil {
+		return nil, "", err
+	}
+	contentType := "multipart/alternative; boundary=" + quoteBoundary(alt.Boundary())
+
+	if len(data.Attachments) > 0 {
+		var mixedBody bytes.Buffer
+		mixed := multipart.NewWriter(&mixedBody)
+
+		part, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(body.Bytes()); err != nil {
+			return nil, "", err
+		}
+		for _, a := range data.Attachments {
+			if err := writeAttachment(mixed, a); err != nil {
+				return nil, "", err
+			}
+		}
+		if err := mixed.Close(); err != nil {
+			return nil, "", err
+		}
+
+		body = mixedBody
+		contentType = "multipart/mixed; boundary=" + quoteBoundary(mixed.Boundary())
+	}
+
+	messageID := fmt.Sprintf("<%s@%s>", uuid.NewString(), domainOf(from.Email))
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s <%s>\r\n", mime.QEncoding.Encode("utf-8", from.Name), from.Email)
+	fmt.Fprintf(&msg, "To: %s\r\n", data.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", data.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: %s\r\n", messageID)
+	if data.UnsubscribeURL != "" {
+		// One-click unsubscribe (RFC 8058): clients POST to the URL.
+		fmt.Fprintf(&msg, "List-Unsubscribe: <%s>\r\n", data.UnsubscribeURL)
+		msg.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", contentType)
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), messageID, nil
+}
+
+func writeAlternatives(w *multipart.Writer, data repository.MailData) error {
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", data.Text},
+		{"text/html; charset=UTF-8", data.HTML},
+	}
+	for _, p := range parts {
+		if p.content == "" {
+			continue
+		}
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(p.content)); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func writeAttachment(w *multipart.Writer, a repository.MailAttachment) error {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	// The name parameter is for clients that ignore Content-Disposition.
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil {
+		params["name"] = a.Filename
+		contentType = mime.FormatMediaType(mediaType, params)
+	}
+
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.Content)
+	for len(encoded) > base64LineLength {
+		if _, err := part.Write([]byte(encoded[:base64LineLength] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[base64LineLength:]
+	}
+	_, err = part.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+func quoteBoundary(boundary string) string {
+	return `"` + boundary + `"`
+}
+
+func domainOf(email string) string {
+	if i := strings.LastIndexByte(email, '@'); i >= 0 && i < len(email)-1 {
+		return email[i+1:]
+	}
+	return "localhost"
+}
@@ -2,58 +2,174 @@ package mail
 
 import (
 	"context"
-	"fmt"
-	"mime"
+	"crypto/tls"
+	"net"
 	"net/smtp"
+	"time"
 
 	"thomas.vn/apartment_service/internal/domain/repository"
 )
 
+// conn is a pooled SMTP session with its underlying connection, which is
+// kept to set deadlines.
+type conn struct {
+	net.Conn
+	client *smtp.Client
+}
+
 func (m *Mailer) Send(
-	_ context.Context,
+	ctx context.Context,
 	data repository.MailData,
-) error {
-
-	subject := mime.QEncoding.Encode("utf-8", data.Subject)
-	fromName := mime.QEncoding.Encode("utf-8", "Hien CNTT")
-
-	from := fmt.Sprintf("%s <%s>", fromName, m.fromEmail())
-
-	body := fmt.Sprintf(`
-<div>
-	<p style="color:%s">%s</p>
-	<p>
-		Chào <b>%s</b>, %s
-	</p>
-</div>
-`,
-		data.Color,
-		data.Title,
-		data.FullName,
-		data.Action,
-	)
+) (string, error) {
+	msg, messageID, err := buildMessage(m.from, data)
+	if err != nil {
+		return "", err
+	}
 
-	msg := fmt.Sprintf(
-		"From: %s\r\n"+
-			"To: %s\r\n"+
-			"Subject: %s\r\n"+
-			"MIME-Version: 1.0\r\n"+
-			"Content-Type: text/html; charset=UTF-8\r\n\r\n"+
-			"%s",
-		from,
-		data.Email,
-		subject,
-		body,
-	)
+	c, err := m.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Cancelling ctx aborts a send that is blocked on the network.
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := m.send(c, data.Email, msg); err != nil {
+		_ = c.Close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", err
+	}
+
+	m.put(c)
+	return messageID, nil
+}
+
+func (m *Mailer) send(c *conn, to string, msg []byte) error {
+	if err := c.client.Mail(m.from.Email); err != nil {
+		return err
+	}
+	if err := c.client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.client.Reset()
+}
+
+// get returns an idle connection that is still alive or dials a new one.
+func (m *Mailer) get(ctx context.Context) (*conn, error) {
+	for {
+		select {
+		case c := <-m.idle:
+			_ = c.SetDeadline(m.deadline(ctx))
+			if err := c.client.Noop(); err != nil {
+				_ = c.Close()
+				continue
+			}
+			return c, nil
+		default:
+			return m.dial(ctx)
+		}
+	}
+}
 
-	return smtp.SendMail(
-		m.addr,
-		m.auth,
-		m.fromEmail(),
-		[]string{data.Email},
-		[]byte(msg),
+// put parks the connection for the next send, or closes it when the pool
+// is full or the mailer is closed.
+func (m *Mailer) put(c *conn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.closed {
+		select {
+		case m.idle <- c:
+			return
+		default:
+		}
+	}
+	_ = c.client.Quit()
+	_ = c.Close()
+}
+
+func (m *Mailer) dial(ctx context.Context) (*conn, error) {
+	dialer := &net.Dialer{Timeout: m.cfg.Timeout}
+
+	var (
+		nc  net.Conn
+		err error
 	)
+	if m.cfg.TLS == TLSImplicit {
+		nc, err = (&tls.Dialer{NetDialer: dialer, Config: m.tlsConfig()}).DialContext(ctx, "tcp", m.addr)
+	} else {
+		nc, err = dialer.DialContext(ctx, "tcp", m.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = nc.SetDeadline(m.deadline(ctx))
+
+	client, err := smtp.NewClient(nc, m.cfg.Host)
+	if err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	if m.cfg.TLS == TLSStartTLS {
+		if err := client.StartTLS(m.tlsConfig()); err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	}
+	if m.auth != nil {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(m.auth); err != nil {
+				_ = client.Close()
+				return nil, err
+			}
+		}
+	}
+
+	return &conn{Conn: nc, client: client}, nil
 }
-func (m *Mailer) fromEmail() string {
-	return m.senderEmail
+
+// deadline is the timeout from now, or the context deadline when sooner.
+func (m *Mailer) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(m.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		return d
+	}
+	return deadline
+}
+
+func (m *Mailer) tlsConfig() *tls.Config {
+	return &tls.Config{ServerName: m.cfg.Host, MinVersion: tls.VersionTLS12}
+}
+
+// Close quits the idle connections. Sends after Close still work but no
+// longer reuse connections.
+func (m *Mailer) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	for {
+		select {
+		case c := <-m.idle:
+			_ = c.client.Quit()
+			_ = c.Close()
+		default:
+			return nil
+		}
+	}
 }
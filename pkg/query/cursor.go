@@ -0,0 +1,155 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// cursor is the decoded form of the opaque pagination cursor. It records
+// the sort it was issued for and the sort key values of the last item.
+type cursor struct {
+	Sort   string            `json:"s"`
+	Desc   bool              `json:"d"`
+	Values []json.RawMessage `json:"v"`
+}
+
+func (s Schema) parseCursor(spec *Spec, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	if len(spec.Sort) == 0 {
+		return fmt.Errorf("cursor pagination is not supported")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return fmt.Errorf("invalid cursor")
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("invalid cursor")
+	}
+	if c.Sort != spec.Sort[0].Field || c.Desc != spec.Sort[0].Desc || len(c.Values) != len(spec.Sort) {
+		return fmt.Errorf("cursor does not match sort_by and order_by")
+	}
+
+	after := make([]interface{}, 0, len(c.Values))
+	for i, order := range spec.Sort {
+		value, err := parseValue(order.Field, s.Fields[order.Field].Type, c.Values[i], false)
+		if err != nil {
+			return fmt.Errorf("invalid cursor")
+		}
+		after = append(after, value)
+	}
+	spec.after = after
+
+	return nil
+}
+
+// Paginate returns a GORM scope applying the page. With a cursor the rows
+// after the cursor are selected (keyset pagination); otherwise page and
+// limit translate to OFFSET and LIMIT.
+func (s *Spec) Paginate(opts PaginationOptions) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if s == nil || s.after == nil {
+			if opts.Page > 0 && opts.Limit > 0 {
+				return db.Offset((opts.Page - 1) * opts.Limit).Limit(opts.Limit)
+			}
+			return db
+		}
+		sql, args := s.seekCondition()
+		return db.Where(sql, args...).Limit(opts.cursorLimit())
+	}
+}
+
+// seekCondition builds the keyset predicate for the sort keys, e.g.
+// (created_at < ?) OR (created_at = ? AND id < ?) for a descending sort.
+func (s *Spec) seekCondition() (string, []interface{}) {
+	var (
+		or   []string
+		args []interface{}
+	)
+	for i := range s.Sort {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, s.Sort[j].Column+" = ?")
+			args = append(args, s.after[j])
+		}
+		op := " > ?"
+		if s.Sort[i].Desc {
+			op = " < ?"
+		}
+		and = append(and, s.Sort[i].Column+op)
+		args = append(args, s.after[i])
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+
+	return "(" + strings.Join(or, " OR ") + ")", args
+}
+
+// NextCursor returns the cursor for the page following items, a slice of
+// structs or struct pointers whose json tags match the sort fields. It is
+// empty when the page is not full or the items do not expose the sort keys.
+func (s *Spec) NextCursor(items interface{}, opts PaginationOptions) string {
+	if s == nil || len(s.Sort) == 0 {
+		return ""
+	}
+
+	rv := reflect.ValueOf(items)
+	if rv.Kind() != reflect.Slice || rv.Len() == 0 {
+		return ""
+	}
+	limit := opts.Limit
+	if s.after != nil {
+		limit = opts.cursorLimit()
+	}
+	if limit <= 0 || rv.Len() < limit {
+		return ""
+	}
+
+	last := reflect.Indirect(rv.Index(rv.Len() - 1))
+	if last.Kind() != reflect.Struct {
+		return ""
+	}
+
+	c := cursor{Sort: s.Sort[0].Field, Desc: s.Sort[0].Desc}
+	for _, order := range s.Sort {
+		value, ok := jsonField(last, order.Field)
+		if !ok {
+			return ""
+		}
+		if t, isTime := value.(time.Time); isTime {
+			value = t.Format(time.RFC3339Nano)
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+		c.Values = append(c.Values, encoded)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// jsonField returns the value of the top level struct field tagged with
+// the given json name.
+func jsonField(v reflect.Value, name string) (interface{}, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if tag == name {
+			return v.Field(i).Interface(), true
+		}
+	}
+	return nil, false
+}
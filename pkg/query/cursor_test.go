@@ -0,0 +1,91 @@
+package query
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type cursorItem struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	sortOpts := SortOptions{SortBy: "created_at", OrderBy: "desc"}
+	page := PaginationOptions{Limit: 2}
+
+	spec, err := testSchema.Parse("", sortOpts, DateRangeOptions{}, page)
+	if err != nil {
+		t.Fatalf("Parse error = %v", err)
+	}
+
+	last := time.Date(2025, 3, 1, 10, 30, 0, 0, time.Local)
+	items := []cursorItem{{ID: 9, CreatedAt: last.Add(time.Hour)}, {ID: 8, CreatedAt: last}}
+	next := spec.NextCursor(items, page)
+	if next == "" {
+		t.Fatal("NextCursor is empty for a full page")
+	}
+
+	page.Cursor = next
+	spec, err = testSchema.Parse("", sortOpts, DateRangeOptions{}, page)
+	if err != nil {
+		t.Fatalf("Parse with cursor error = %v", err)
+	}
+	if len(spec.after) != 2 || !spec.after[0].(time.Time).Equal(last) || spec.after[1] != int64(8) {
+		t.Fatalf("after = %#v", spec.after)
+	}
+
+	sql, args := spec.seekCondition()
+	wantSQL := "((t.created_at < ?) OR (t.created_at = ? AND t.id < ?))"
+	if sql != wantSQL {
+		t.Errorf("seekCondition = %s, want %s", sql, wantSQL)
+	}
+	if !reflect.DeepEqual(args, []interface{}{spec.after[0], spec.after[0], int64(8)}) {
+		t.Errorf("seek args = %#v", args)
+	}
+}
+
+func TestNextCursorEmpty(t *testing.T) {
+	spec, err := testSchema.Parse("", SortOptions{}, DateRangeOptions{}, PaginationOptions{})
+	if err != nil {
+		t.Fatalf("Parse error = %v", err)
+	}
+
+	if c := spec.NextCursor([]cursorItem{{ID: 1}}, PaginationOptions{Limit: 2}); c != "" {
+		t.Errorf("NextCursor for a partial page = %q, want empty", c)
+	}
+	if c := spec.NextCursor([]struct{ Name string }{{"a"}}, PaginationOptions{Limit: 1}); c != "" {
+		t.Errorf("NextCursor without sort keys = %q, want empty", c)
+	}
+}
+
+func TestParseCursorRejects(t *testing.T) {
+	spec, err := testSchema.Parse("", SortOptions{SortBy: "id"}, DateRangeOptions{}, PaginationOptions{})
+	if err != nil {
+		t.Fatalf("Parse error = %v", err)
+	}
+	issued := spec.NextCursor([]cursorItem{{ID: 1}}, PaginationOptions{Limit: 1})
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   SortOptions
+	}{
+		{"not base64", "!!!", SortOptions{SortBy: "id"}},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("nope")), SortOptions{SortBy: "id"}},
+		{"other sort", issued, SortOptions{SortBy: "created_at"}},
+		{"other order", issued, SortOptions{SortBy: "id", OrderBy: "desc"}},
+		{"bad value", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"id","v":["x"]}`)), SortOptions{SortBy: "id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testSchema.Parse("", tt.sort, DateRangeOptions{}, PaginationOptions{Cursor: tt.cursor})
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -1,7 +1,20 @@
 package query
 
+// DefaultCursorLimit is the page size used by cursor pagination when no
+// limit is given.
+const DefaultCursorLimit = 10
+
 type PaginationOptions struct {
-	Page         int  `query:"page" validate:"omitempty,gt=0"`
-	Limit        int  `query:"limit" validate:"omitempty,gt=0"`
-	ExcludeTotal bool `query:"exclude_total"`
+	Page         int    `query:"page" validate:"omitempty,gt=0"`
+	Limit        int    `query:"limit" validate:"omitempty,gt=0"`
+	ExcludeTotal bool   `query:"exclude_total"`
+	Cursor       string `query:"cursor"`
+}
+
+// cursorLimit returns the page size used in cursor mode.
+func (p PaginationOptions) cursorLimit() int {
+	if p.Limit > 0 {
+		return p.Limit
+	}
+	return DefaultCursorLimit
 }
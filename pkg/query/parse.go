@@ -0,0 +1,304 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// Parse validates the raw filters JSON, sort, date range and cursor params
+// against the schema and returns the resulting spec.
+//
+// Filters map field names to a plain value, which uses the field's default
+// operator, or to an object of operators:
+//
+//	{"full_name":"john","role_id":{"in":[1,2]},"created_at":{"range":{"from":"2025-01-01"}}}
+func (s Schema) Parse(filters string, sortOpts SortOptions, dateRange DateRangeOptions, page PaginationOptions) (*Spec, error) {
+	spec := &Spec{}
+
+	if err := s.parseFilters(spec, filters); err != nil {
+		return nil, err
+	}
+	if err := s.parseDateRange(spec, dateRange); err != nil {
+		return nil, err
+	}
+	if err := s.parseSort(spec, sortOpts); err != nil {
+		return nil, err
+	}
+	if err := s.parseCursor(spec, page.Cursor); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+func (s Schema) parseFilters(spec *Spec, raw string) error {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "undefined" {
+		return nil
+	}
+	// Some clients encode the JSON twice.
+	if !strings.HasPrefix(raw, "{") {
+		decoded, err := url.QueryUnescape(raw)
+		if err != nil {
+			return fmt.Errorf("filters must be valid JSON")
+		}
+		raw = decoded
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return fmt.Errorf("filters must be valid JSON")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field, ok := s.Fields[name]
+		if !ok {
+			return fmt.Errorf("unknown filter field %q", name)
+		}
+
+		if len(field.Operators) == 0 {
+			return fmt.Errorf("field %q cannot be filtered", name)
+		}
+
+		value := bytes.TrimSpace(values[name])
+		if len(value) == 0 || value[0] != '{' {
+			if err := addCondition(spec, name, field, field.Operators[0], value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var ops map[Operator]json.RawMessage
+		if err := json.Unmarshal(value, &ops); err != nil {
+			return fmt.Errorf("invalid filter for %q", name)
+		}
+		for _, op := range []Operator{OpEq, OpIn, OpLike, OpRange} {
+			if opValue, ok := ops[op]; ok {
+				if err := addCondition(spec, name, field, op, opValue); err != nil {
+					return err
+				}
+				delete(ops, op)
+			}
+		}
+		for op := range ops {
+			return fmt.Errorf("unknown operator %q for field %q", op, name)
+		}
+	}
+
+	return nil
+}
+
+func addCondition(spec *Spec, name string, field Field, op Operator, raw json.RawMessage) error {
+	if !field.allows(op) {
+		return fmt.Errorf("operator %q is not allowed for field %q", op, name)
+	}
+
+	cond := Condition{Field: name, Column: field.Column, Operator: op}
+
+	switch op {
+	case OpEq:
+		value, err := parseValue(name, field.Type, raw, false)
+		if err != nil {
+			return err
+		}
+		cond.Values = []interface{}{value}
+
+	case OpLike:
+		value, err := parseValue(name, TypeString, raw, false)
+		if err != nil {
+			return err
+		}
+		cond.Values = []interface{}{"%" + escapeLike(value.(string)) + "%"}
+
+	case OpIn:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return fmt.Errorf("filter %q expects an array", name)
+		}
+		if len(items) == 0 || len(items) > MaxInValues {
+			return fmt.Errorf("filter %q expects between 1 and %d values", name, MaxInValues)
+		}
+		for _, item := range items {
+			value, err := parseValue(name, field.Type, item, false)
+			if err != nil {
+				return err
+			}
+			cond.Values = append(cond.Values, value)
+		}
+
+	case OpRange:
+		var bounds struct {
+			From json.RawMessage `json:"from"`
+			To   json.RawMessage `json:"to"`
+		}
+		if err := json.Unmarshal(raw, &bounds); err != nil {
+			return fmt.Errorf("filter %q expects an object with from and to", name)
+		}
+		from, err := parseBound(name, field.Type, bounds.From, false)
+		if err != nil {
+			return err
+		}
+		to, err := parseBound(name, field.Type, bounds.To, true)
+		if err != nil {
+			return err
+		}
+		if from == nil && to == nil {
+			return fmt.Errorf("filter %q expects from or to", name)
+		}
+		cond.Values = []interface{}{from, to}
+	}
+
+	spec.Conditions = append(spec.Conditions, cond)
+	return nil
+}
+
+func (s Schema) parseDateRange(spec *Spec, opts DateRangeOptions) error {
+	if opts.FromDate == "" && opts.ToDate == "" {
+		return nil
+	}
+
+	name := opts.RangeBy
+	if name == "" {
+		name = s.DefaultRange
+	}
+	field, ok := s.Fields[name]
+	if !ok || !field.allows(OpRange) {
+		return fmt.Errorf("range_by %q is not supported", name)
+	}
+
+	var from, to interface{}
+	var err error
+	if opts.FromDate != "" {
+		if from, err = parseText(name, field.Type, opts.FromDate, false); err != nil {
+			return err
+		}
+	}
+	if opts.ToDate != "" {
+		if to, err = parseText(name, field.Type, opts.ToDate, true); err != nil {
+			return err
+		}
+	}
+
+	spec.Conditions = append(spec.Conditions, Condition{
+		Field:    name,
+		Column:   field.Column,
+		Operator: OpRange,
+		Values:   []interface{}{from, to},
+	})
+	return nil
+}
+
+func (s Schema) parseSort(spec *Spec, opts SortOptions) error {
+	name, order := opts.SortBy, opts.OrderBy
+	if name == "" {
+		name, order = s.DefaultSort, s.DefaultOrder
+	}
+	if name == "" {
+		s.addKeySort(spec, false)
+		return nil
+	}
+
+	field, ok := s.Fields[name]
+	if !ok || !field.Sortable {
+		return fmt.Errorf("sort_by %q is not supported", name)
+	}
+
+	var desc bool
+	switch strings.ToLower(order) {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return fmt.Errorf("order_by must be asc or desc")
+	}
+
+	spec.Sort = append(spec.Sort, Order{Field: name, Column: field.Column, Desc: desc})
+	if name != s.Key {
+		s.addKeySort(spec, desc)
+	}
+	return nil
+}
+
+// addKeySort appends the schema key as tie breaker so that rows sharing a
+// sort value keep a stable order across pages.
+func (s Schema) addKeySort(spec *Spec, desc bool) {
+	if s.Key == "" {
+		return
+	}
+	spec.Sort = append(spec.Sort, Order{Field: s.Key, Column: s.Fields[s.Key].Column, Desc: desc})
+}
+
+func parseBound(name string, typ FieldType, raw json.RawMessage, upper bool) (interface{}, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	return parseValue(name, typ, raw, upper)
+}
+
+// parseValue decodes a single JSON value. Numbers are accepted as JSON
+// numbers or strings so that values copied from query strings keep working.
+func parseValue(name string, typ FieldType, raw json.RawMessage, upper bool) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid value for %q", name)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return parseText(name, typ, v, upper)
+	case json.Number:
+		if typ == TypeInt {
+			return parseText(name, typ, v.String(), upper)
+		}
+	}
+
+	return nil, fmt.Errorf("invalid value for %q", name)
+}
+
+func parseText(name string, typ FieldType, text string, upper bool) (interface{}, error) {
+	switch typ {
+	case TypeInt:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q must be an integer", name)
+		}
+		return n, nil
+
+	case TypeDate:
+		for _, layout := range dateLayouts {
+			t, err := time.ParseInLocation(layout, text, time.Local)
+			if err != nil {
+				continue
+			}
+			// A bare date used as an upper bound covers the whole day.
+			if upper && layout == "2006-01-02" {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+		return nil, fmt.Errorf("%q must be a date (YYYY-MM-DD)", name)
+	}
+
+	return text, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
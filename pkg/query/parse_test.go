@@ -0,0 +1,182 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSchema = Schema{
+	Fields: map[string]Field{
+		"id":         {Column: "t.id", Type: TypeInt, Operators: []Operator{OpEq, OpIn}, Sortable: true},
+		"name":       {Column: "t.name", Operators: []Operator{OpLike, OpEq}, Sortable: true},
+		"created_at": {Column: "t.created_at", Type: TypeDate, Operators: []Operator{OpRange}, Sortable: true},
+		"rank":       {Column: "t.rank", Type: TypeInt, Sortable: true},
+	},
+	Key:          "id",
+	DefaultRange: "created_at",
+}
+
+func parseFilters(t *testing.T, filters string) *Spec {
+	t.Helper()
+	spec, err := testSchema.Parse(filters, SortOptions{}, DateRangeOptions{}, PaginationOptions{})
+	if err != nil {
+		t.Fatalf("Parse(%s) error = %v", filters, err)
+	}
+	return spec
+}
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters string
+		want    []Condition
+	}{
+		{
+			name:    "empty",
+			filters: "",
+			want:    nil,
+		},
+		{
+			name:    "plain value uses the first operator",
+			filters: `{"name":"jo_hn"}`,
+			want:    []Condition{{Field: "name", Column: "t.name", Operator: OpLike, Values: []interface{}{`%jo\_hn%`}}},
+		},
+		{
+			name:    "explicit eq with a number",
+			filters: `{"id":{"eq":7}}`,
+			want:    []Condition{{Field: "id", Column: "t.id", Operator: OpEq, Values: []interface{}{int64(7)}}},
+		},
+		{
+			name:    "number given as string",
+			filters: `{"id":"7"}`,
+			want:    []Condition{{Field: "id", Column: "t.id", Operator: OpEq, Values: []interface{}{int64(7)}}},
+		},
+		{
+			name:    "in",
+			filters: `{"id":{"in":[1,2]}}`,
+			want:    []Condition{{Field: "id", Column: "t.id", Operator: OpIn, Values: []interface{}{int64(1), int64(2)}}},
+		},
+		{
+			name:    "url-escaped filters",
+			filters: `%7B%22id%22%3A3%7D`,
+			want:    []Condition{{Field: "id", Column: "t.id", Operator: OpEq, Values: []interface{}{int64(3)}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := parseFilters(t, tt.filters)
+			if !reflect.DeepEqual(spec.Conditions, tt.want) {
+				t.Errorf("Conditions = %#v, want %#v", spec.Conditions, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFiltersRange(t *testing.T) {
+	spec := parseFilters(t, `{"created_at":{"range":{"from":"2025-01-01","to":"2025-01-31"}}}`)
+	if len(spec.Conditions) != 1 {
+		t.Fatalf("got %d conditions, want 1", len(spec.Conditions))
+	}
+
+	from := spec.Conditions[0].Values[0].(time.Time)
+	to := spec.Conditions[0].Values[1].(time.Time)
+	if got := from.Format("2006-01-02 15:04:05"); got != "2025-01-01 00:00:00" {
+		t.Errorf("from = %s", got)
+	}
+	// A bare date as upper bound covers the whole day.
+	if got := to.Format("2006-01-02 15:04:05"); got != "2025-01-31 23:59:59" {
+		t.Errorf("to = %s", got)
+	}
+}
+
+func TestParseFiltersRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters string
+		wantErr string
+	}{
+		{"invalid JSON", `{"id":`, "filters must be valid JSON"},
+		{"unknown field", `{"password":"x"}`, `unknown filter field "password"`},
+		{"operator not whitelisted", `{"id":{"like":"1"}}`, `operator "like" is not allowed for field "id"`},
+		{"unknown operator", `{"id":{"gt":1}}`, `unknown operator "gt" for field "id"`},
+		{"field without operators", `{"rank":1}`, `field "rank" cannot be filtered`},
+		{"field without operators, explicit", `{"rank":{"eq":1}}`, `field "rank" cannot be filtered`},
+		{"wrong type", `{"id":"abc"}`, `"id" must be an integer`},
+		{"empty in", `{"id":{"in":[]}}`, `filter "id" expects between 1 and 100 values`},
+		{"open range", `{"created_at":{"range":{}}}`, `filter "created_at" expects from or to`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testSchema.Parse(tt.filters, SortOptions{}, DateRangeOptions{}, PaginationOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpecValue(t *testing.T) {
+	spec := parseFilters(t, `{"id":5,"name":"x"}`)
+
+	if v, ok := spec.Value("id"); !ok || v != int64(5) {
+		t.Errorf("Value(id) = %v, %v; want 5, true", v, ok)
+	}
+	// Value only reads eq conditions.
+	if _, ok := spec.Value("name"); ok {
+		t.Error("Value(name) ok for a like condition")
+	}
+	if _, ok := spec.Value("created_at"); ok {
+		t.Error("Value(created_at) ok without a condition")
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    SortOptions
+		want    []Order
+		wantErr bool
+	}{
+		{
+			name: "key only by default",
+			want: []Order{{Field: "id", Column: "t.id"}},
+		},
+		{
+			name: "key is added as tie breaker",
+			opts: SortOptions{SortBy: "created_at", OrderBy: "desc"},
+			want: []Order{{Field: "created_at", Column: "t.created_at", Desc: true}, {Field: "id", Column: "t.id", Desc: true}},
+		},
+		{
+			name:    "not sortable",
+			opts:    SortOptions{SortBy: "password"},
+			wantErr: true,
+		},
+		{
+			name:    "bad order",
+			opts:    SortOptions{SortBy: "id", OrderBy: "sideways"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := testSchema.Parse("", tt.opts, DateRangeOptions{}, PaginationOptions{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse error = %v", err)
+			}
+			if !reflect.DeepEqual(spec.Sort, tt.want) {
+				t.Errorf("Sort = %#v, want %#v", spec.Sort, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package query
+
+// Operator is a filter operator supported by the list DSL.
+type Operator string
+
+const (
+	OpEq    Operator = "eq"
+	OpIn    Operator = "in"
+	OpLike  Operator = "like"
+	OpRange Operator = "range"
+)
+
+// FieldType decides how filter values are parsed.
+type FieldType int
+
+const (
+	TypeString FieldType = iota
+	TypeInt
+	TypeDate
+)
+
+// MaxInValues caps the number of values accepted by the in operator.
+const MaxInValues = 100
+
+// Field describes a column exposed to clients. The first operator is used
+// when a filter is given as a plain value, e.g. {"full_name":"john"}. A
+// field without operators can only be sorted by.
+type Field struct {
+	Column    string
+	Type      FieldType
+	Operators []Operator
+	Sortable  bool
+}
+
+// Schema whitelists the fields a list endpoint can filter and sort by,
+// keyed by the name clients use in filters, sort_by and range_by.
+type Schema struct {
+	Fields map[string]Field
+	// Key is a unique, sortable field used as tie breaker for stable
+	// ordering and cursor pagination.
+	Key string
+	// DefaultRange is the field used by from_date/to_date when range_by is empty.
+	DefaultRange string
+	// DefaultSort is the field used when sort_by is empty.
+	DefaultSort  string
+	DefaultOrder string
+}
+
+func (f Field) allows(op Operator) bool {
+	for _, allowed := range f.Operators {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package query
+
+import "gorm.io/gorm"
+
+// Condition is a validated filter. Columns always come from a Schema, so
+// they are safe to use in SQL; values are passed as bind parameters.
+type Condition struct {
+	Field    string
+	Column   string
+	Operator Operator
+	// Values holds one value for eq and like, the list for in and
+	// [from, to] for range, where a nil bound is open.
+	Values []interface{}
+}
+
+// Order is a validated sort key.
+type Order struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+// Spec is the result of parsing list params against a Schema.
+type Spec struct {
+	Conditions []Condition
+	Sort       []Order
+
+	// after holds the sort key values decoded from the cursor, if any.
+	after []interface{}
+}
+
+// Has reports whether the spec filters on the given field.
+func (s *Spec) Has(field string) bool {
+	_, ok := s.condition(field)
+	return ok
+}
+
+// Value returns the value of an eq condition on the given field.
+func (s *Spec) Value(field string) (interface{}, bool) {
+	cond, ok := s.condition(field)
+	if !ok || cond.Operator != OpEq {
+		return nil, false
+	}
+	return cond.Values[0], true
+}
+
+func (s *Spec) condition(field string) (Condition, bool) {
+	if s == nil {
+		return Condition{}, false
+	}
+	for _, cond := range s.Conditions {
+		if cond.Field == field {
+			return cond, true
+		}
+	}
+	return Condition{}, false
+}
+
+// Filter returns a GORM scope applying the spec conditions.
+func (s *Spec) Filter() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if s == nil {
+			return db
+		}
+		for _, cond := range s.Conditions {
+			switch cond.Operator {
+			case OpEq:
+				db = db.Where(cond.Column+" = ?", cond.Values[0])
+			case OpLike:
+				db = db.Where(cond.Column+" LIKE ?", cond.Values[0])
+			case OpIn:
+				db = db.Where(cond.Column+" IN ?", cond.Values)
+			case OpRange:
+				if cond.Values[0] != nil {
+					db = db.Where(cond.Column+" >= ?", cond.Values[0])
+				}
+				if cond.Values[1] != nil {
+					db = db.Where(cond.Column+" <= ?", cond.Values[1])
+				}
+			}
+		}
+		return db
+	}
+}
+
+// Sorting returns a GORM scope applying the spec sort keys.
+func (s *Spec) Sorting() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if s == nil {
+			return db
+		}
+		for _, order := range s.Sort {
+			if order.Desc {
+				db = db.Order(order.Column + " DESC")
+			} else {
+				db = db.Order(order.Column + " ASC")
+			}
+		}
+		return db
+	}
+}
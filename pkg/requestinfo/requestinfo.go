@@ -0,0 +1,33 @@
+package xrequestinfo
+
+import "context"
+
+type infoKey struct{}
+
+// Info carries request metadata that is useful outside the delivery layer,
+// e.g. for audit records written from usecases.
+type Info struct {
+	ActorID   int
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// WithInfo returns a copy of ctx carrying info.
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// FromContext returns the request metadata stored in ctx. The zero Info is
+// returned for contexts that did not originate from a request.
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
+
+// WithActor returns a copy of ctx whose metadata records the authenticated user.
+func WithActor(ctx context.Context, actorID int) context.Context {
+	info := FromContext(ctx)
+	info.ActorID = actorID
+	return WithInfo(ctx, info)
+}
@@ -0,0 +1,80 @@
+package xsoftdelete
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	xrequestinfo "thomas.vn/apartment_service/pkg/requestinfo"
+	xutils "thomas.vn/apartment_service/pkg/utils"
+)
+
+const (
+	// NotDeleted is the is_deleted value of live rows.
+	NotDeleted = 0
+	// Deleted is the is_deleted value of soft deleted rows.
+	Deleted = 1
+)
+
+// Table implements soft delete semantics for tables carrying the
+// is_deleted, deleted_by and deleted_at columns.
+type Table struct {
+	db   *gorm.DB
+	name string
+}
+
+func New(db *gorm.DB, name string) *Table {
+	return &Table{db: db, name: name}
+}
+
+// Scope restricts a query to live rows, or to soft deleted rows when
+// onlyDeleted is true. column is the is_deleted column, optionally
+// qualified with a table alias (e.g. "cg.is_deleted").
+func Scope(column string, onlyDeleted bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if onlyDeleted {
+			return db.Where(column+" = ?", Deleted)
+		}
+		return db.Where(column+" = ?", NotDeleted)
+	}
+}
+
+// Delete marks the row as deleted. DeletedBy is taken from the
+// authenticated user stored in ctx. It reports whether a live row was found.
+func (t *Table) Delete(ctx context.Context, id interface{}) (bool, error) {
+	now := xutils.GetTimeNow()
+	result := t.db.WithContext(ctx).
+		Table(t.name).
+		Where("id = ? AND is_deleted = ?", id, NotDeleted).
+		Updates(map[string]interface{}{
+			"is_deleted": Deleted,
+			"deleted_by": xrequestinfo.FromContext(ctx).ActorID,
+			"deleted_at": now,
+			"updated_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Restore brings a soft deleted row back. It reports whether a deleted
+// row was found.
+func (t *Table) Restore(ctx context.Context, id interface{}) (bool, error) {
+	result := t.db.WithContext(ctx).
+		Table(t.name).
+		Where("id = ? AND is_deleted = ?", id, Deleted).
+		Updates(map[string]interface{}{
+			"is_deleted": NotDeleted,
+			"deleted_by": 0,
+			"deleted_at": nil,
+			"updated_at": xutils.GetTimeNow(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Purge permanently removes rows soft deleted before the given time and
+// returns the number of removed rows.
+func (t *Table) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := t.db.WithContext(ctx).
+		Exec("DELETE FROM "+t.name+" WHERE is_deleted = ? AND deleted_at < ?", Deleted, before)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,64 @@
+package xtenant
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type scopeKey struct{}
+
+// Scope describes which buildings (tenants) a caller may access.
+// A global scope is not restricted to any building.
+type Scope struct {
+	Global      bool
+	BuildingIDs []int64
+}
+
+// Allows reports whether the scope grants access to the given building.
+func (s *Scope) Allows(buildingID int64) bool {
+	if s.Global {
+		return true
+	}
+	for _, id := range s.BuildingIDs {
+		if id == buildingID {
+			return true
+		}
+	}
+	return false
+}
+
+// WithScope returns a copy of ctx carrying the caller's scope.
+func WithScope(ctx context.Context, scope *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// FromContext returns the scope stored in ctx, if any.
+func FromContext(ctx context.Context) (*Scope, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(*Scope)
+	return scope, ok && scope != nil
+}
+
+// Filter returns a GORM scope restricting column to the buildings allowed
+// by the scope in ctx. Queries without a scope in ctx (jobs, internal calls)
+// and global scopes are left untouched.
+func Filter(ctx context.Context, column string) func(db *gorm.DB) *gorm.DB {
+	return FilterBy(ctx, func(db *gorm.DB, buildingIDs []int64) *gorm.DB {
+		return db.Where(column+" IN ?", buildingIDs)
+	})
+}
+
+// FilterBy is like Filter but lets the caller build the condition, for
+// resources linked to buildings through another table.
+func FilterBy(ctx context.Context, cond func(db *gorm.DB, buildingIDs []int64) *gorm.DB) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope, ok := FromContext(ctx)
+		if !ok || scope.Global {
+			return db
+		}
+		if len(scope.BuildingIDs) == 0 {
+			return db.Where("1 = 0")
+		}
+		return cond(db, scope.BuildingIDs)
+	}
+}
@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,6 +17,9 @@ const (
 	pingPeriod = (pongWait * 9) / 10 // 54 seconds
 	// maxMessageSize is the maximum size of an inbound message (8 KB).
 	maxMessageSize = 8 * 1024
+	// sendBufferSize is how many outgoing frames a client may lag behind
+	// before it is disconnected as a slow consumer.
+	sendBufferSize = 512
 )
 
 // Client represents a single authenticated WebSocket connection.
@@ -24,24 +28,42 @@ type Client struct {
 	UserID string
 	send   chan []byte
 
+	// userID is the authenticated user, zero until AUTH succeeds.
+	userID int
+
 	// rooms tracks which chat rooms this client has joined (for cleanup on disconnect).
 	rooms map[int]struct{}
 	mu    sync.Mutex
 
 	// limiter enforces a per-client message rate limit.
 	limiter *rateLimiter
+	// typing throttles TYPING_START fan-out on top of limiter.
+	typing *rateLimiter
+
+	closeOnce sync.Once
 }
 
 func newClient(conn *websocket.Conn, userID string) *Client {
 	return &Client{
 		conn:    conn,
 		UserID:  userID,
-		send:    make(chan []byte, 512),
+		send:    make(chan []byte, sendBufferSize),
 		rooms:   make(map[int]struct{}),
-		limiter: newRateLimiter(10, time.Second), // 10 messages/second max
+		limiter: newRateLimiter(10, time.Second),  // 10 messages/second max
+		typing:  newRateLimiter(1, 2*time.Second), // 1 typing event/2 seconds
 	}
 }
 
+// Authenticated reports whether the client completed AUTH.
+func (c *Client) Authenticated() bool {
+	return c.userID != 0
+}
+
+func (c *Client) authenticate(userID int) {
+	c.userID = userID
+	c.UserID = strconv.Itoa(userID)
+}
+
 func (c *Client) JoinRoom(room int) {
 	c.mu.Lock()
 	c.rooms[room] = struct{}{}
@@ -72,6 +94,32 @@ func (c *Client) AllRooms() []int {
 	return rooms
 }
 
+// enqueue queues msg for WritePump. A client whose buffer is full cannot
+// keep up: it is disconnected instead of silently missing frames, and
+// catches up with RESUME once it reconnects.
+func (c *Client) enqueue(msg []byte) bool {
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		c.closeSlow()
+		return false
+	}
+}
+
+// closeSlow closes the connection with a "try again later" close frame. The
+// read pump then fails and cleans the client up. It does not block, as it
+// is called while the hub is locked.
+func (c *Client) closeSlow() {
+	c.closeOnce.Do(func() {
+		go func() {
+			msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer")
+			_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(writeWait))
+			_ = c.conn.Close()
+		}()
+	})
+}
+
 // WritePump reads outgoing messages from c.send and forwards them to the
 // WebSocket connection. It also sends periodic pings to keep the connection alive.
 // Must be run in its own goroutine. Closes the connection when the channel is closed.
@@ -1,43 +1,318 @@
 package ws
 
-import "sync"
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// backendTimeout bounds backend calls made while handling a socket event.
+const backendTimeout = 5 * time.Second
+
+// Backend fans out room broadcasts, user messages and presence across app
+// instances. Without a backend the hub only reaches clients of the local
+// process.
+type Backend interface {
+	// Start begins delivering messages of subscribed rooms to deliver and
+	// messages of subscribed users to deliverUser.
+	Start(deliver func(room int, msg []byte), deliverUser func(userID string, msg []byte)) error
+	// Publish sends msg to the room on every instance.
+	Publish(ctx context.Context, room int, msg []byte) error
+	// Subscribe and Unsubscribe are called when a room gains its first or
+	// loses its last local member.
+	Subscribe(ctx context.Context, room int) error
+	Unsubscribe(ctx context.Context, room int) error
+	// PublishUser sends msg to the connections of userID on every instance.
+	PublishUser(ctx context.Context, userID string, msg []byte) error
+	// SubscribeUser and UnsubscribeUser are called when a user gains a first
+	// or loses a last local connection.
+	SubscribeUser(ctx context.Context, userID string) error
+	UnsubscribeUser(ctx context.Context, userID string) error
+	// SetPresence records whether userID has a connection in room on this instance.
+	SetPresence(ctx context.Context, room int, userID string, present bool) error
+	// Presence returns the users present in room on any instance.
+	Presence(ctx context.Context, room int) ([]string, error)
+	// SetOnline records whether userID has a connection on this instance.
+	// Calling it again for an online user refreshes the entry.
+	SetOnline(ctx context.Context, userID string, online bool) error
+	// UserStatus returns the online state of the users on any instance.
+	UserStatus(ctx context.Context, userIDs []string) ([]UserStatus, error)
+	Close() error
+}
+
+// envelope is what the hub sends through the backend: either a message
+// for the room members or the ID of a user to remove from the room.
+type envelope struct {
+	Msg   json.RawMessage `json:"m,omitempty"`
+	Evict string          `json:"e,omitempty"`
+}
 
 type Hub struct {
 	Rooms map[int]map[*Client]bool
 	Mu    sync.RWMutex
+
+	// clients holds the local connections of each user, one per device;
+	// lastSeen keeps when users without a connection left.
+	clients  map[string]map[*Client]bool
+	lastSeen map[string]time.Time
+	usersMu  sync.Mutex
+
+	backend Backend
+	// subscribed holds the rooms subscribed on the backend; syncMu guards
+	// it and orders backend room calls.
+	subscribed map[int]bool
+	syncMu     sync.Mutex
+}
+
+type HubOption func(*Hub)
+
+// WithBackend distributes broadcasts and presence through b.
+func WithBackend(b Backend) HubOption {
+	return func(h *Hub) {
+		h.backend = b
+	}
 }
 
-func NewHub() *Hub {
-	return &Hub{
-		Rooms: make(map[int]map[*Client]bool),
+func NewHub(opts ...HubOption) *Hub {
+	h := &Hub{
+		Rooms:      make(map[int]map[*Client]bool),
+		clients:    make(map[string]map[*Client]bool),
+		lastSeen:   make(map[string]time.Time),
+		subscribed: make(map[int]bool),
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
+}
+
+// Start starts the backend, if any.
+func (h *Hub) Start() error {
+	if h.backend == nil {
+		return nil
+	}
+	return h.backend.Start(h.receive, h.deliverUser)
+}
+
+// Close releases the backend, if any.
+func (h *Hub) Close() error {
+	if h.backend == nil {
+		return nil
+	}
+	return h.backend.Close()
 }
 
 func (h *Hub) Join(room int, c *Client) {
 	h.Mu.Lock()
-	defer h.Mu.Unlock()
-
 	if h.Rooms[room] == nil {
 		h.Rooms[room] = make(map[*Client]bool)
 	}
 	h.Rooms[room][c] = true
+	h.Mu.Unlock()
+
+	h.syncRoom(room, c.UserID)
 }
 
 func (h *Hub) Leave(room int, c *Client) {
 	h.Mu.Lock()
-	defer h.Mu.Unlock()
+	if !h.Rooms[room][c] {
+		h.Mu.Unlock()
+		return
+	}
 	delete(h.Rooms[room], c)
+	if len(h.Rooms[room]) == 0 {
+		delete(h.Rooms, room)
+	}
+	h.Mu.Unlock()
+
+	h.syncRoom(room, c.UserID)
 }
 
+// syncRoom brings the backend subscription of the room and the presence of
+// userID in it in line with the local members. It runs outside h.Mu so a
+// slow backend does not stall delivery. Syncs are serialized and read the
+// current members, so concurrent joins and leaves settle on the final state.
+func (h *Hub) syncRoom(room int, userID string) {
+	if h.backend == nil {
+		return
+	}
+
+	h.syncMu.Lock()
+	defer h.syncMu.Unlock()
+
+	h.Mu.RLock()
+	occupied := len(h.Rooms[room]) > 0
+	present := h.hasUserLocked(room, userID)
+	h.Mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+
+	if occupied && !h.subscribed[room] {
+		if err := h.backend.Subscribe(ctx, room); err == nil {
+			h.subscribed[room] = true
+		}
+	} else if !occupied && h.subscribed[room] {
+		if err := h.backend.Unsubscribe(ctx, room); err == nil {
+			delete(h.subscribed, room)
+		}
+	}
+	_ = h.backend.SetPresence(ctx, room, userID, present)
+}
+
+// Broadcast sends msg to every member of the room. With a backend the
+// message reaches all instances; if publishing fails, local members still
+// receive it.
 func (h *Hub) Broadcast(room int, msg []byte) {
+	if h.backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+
+		if err := h.publish(ctx, room, envelope{Msg: msg}); err == nil {
+			return
+		}
+	}
+
+	h.deliver(room, msg)
+}
+
+// BroadcastToRoom sends an event frame to every member of the room.
+func (h *Hub) BroadcastToRoom(_ context.Context, room int, eventType string, data interface{}) error {
+	msg, err := json.Marshal(Event{Type: eventType, Data: data})
+	if err != nil {
+		return err
+	}
+
+	h.Broadcast(room, msg)
+	return nil
+}
+
+// SendToUser sends msg to every connection of the user. With a backend the
+// message reaches all instances; if publishing fails, local connections
+// still receive it.
+func (h *Hub) SendToUser(userID string, msg []byte) {
+	if h.backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+
+		if err := h.backend.PublishUser(ctx, userID, msg); err == nil {
+			return
+		}
+	}
+
+	h.deliverUser(userID, msg)
+}
+
+// NotifyUser sends an event frame to every connection of the user.
+func (h *Hub) NotifyUser(_ context.Context, userID int, eventType string, data interface{}) error {
+	msg, err := json.Marshal(Event{Type: eventType, Data: data})
+	if err != nil {
+		return err
+	}
+
+	h.SendToUser(strconv.Itoa(userID), msg)
+	return nil
+}
+
+// EvictFromRoom removes the user's connections from the room on every
+// instance, e.g. after the user was removed from the chat group.
+func (h *Hub) EvictFromRoom(ctx context.Context, room int, userID int) error {
+	id := strconv.Itoa(userID)
+	if h.backend == nil {
+		h.evict(room, id)
+		return nil
+	}
+
+	return h.publish(ctx, room, envelope{Evict: id})
+}
+
+// Presence returns the IDs of users connected to the room.
+func (h *Hub) Presence(ctx context.Context, room int) ([]string, error) {
+	if h.backend != nil {
+		return h.backend.Presence(ctx, room)
+	}
+
+	h.Mu.RLock()
+	defer h.Mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	users := make([]string, 0, len(h.Rooms[room]))
+	for c := range h.Rooms[room] {
+		if _, ok := seen[c.UserID]; ok {
+			continue
+		}
+		seen[c.UserID] = struct{}{}
+		users = append(users, c.UserID)
+	}
+	return users, nil
+}
+
+func (h *Hub) publish(ctx context.Context, room int, env envelope) error {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return h.backend.Publish(ctx, room, data)
+}
+
+// receive handles an envelope published by any instance.
+func (h *Hub) receive(room int, data []byte) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return
+	}
+
+	if env.Evict != "" {
+		h.evict(room, env.Evict)
+		return
+	}
+	h.deliver(room, env.Msg)
+}
+
+// evict removes the local connections of userID from the room.
+func (h *Hub) evict(room int, userID string) {
+	h.Mu.RLock()
+	clients := make([]*Client, 0)
+	for c := range h.Rooms[room] {
+		if c.UserID == userID {
+			clients = append(clients, c)
+		}
+	}
+	h.Mu.RUnlock()
+
+	for _, c := range clients {
+		h.Leave(room, c)
+		c.LeaveRoom(room)
+	}
+}
+
+// deliver sends msg to the local members of the room.
+func (h *Hub) deliver(room int, msg []byte) {
 	h.Mu.RLock()
 	defer h.Mu.RUnlock()
 
 	for c := range h.Rooms[room] {
-		select {
-		case c.send <- msg:
-		default:
-			// Client send buffer full — drop message to avoid blocking broadcast.
+		c.enqueue(msg)
+	}
+}
+
+// deliverUser sends msg to the local connections of the user.
+func (h *Hub) deliverUser(userID string, msg []byte) {
+	h.usersMu.Lock()
+	defer h.usersMu.Unlock()
+
+	for c := range h.clients[userID] {
+		c.enqueue(msg)
+	}
+}
+
+func (h *Hub) hasUserLocked(room int, userID string) bool {
+	for c := range h.Rooms[room] {
+		if c.UserID == userID {
+			return true
 		}
 	}
+	return false
 }
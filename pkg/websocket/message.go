@@ -1,6 +1,41 @@
 package ws
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
+
+// Message types exchanged over the socket.
+const (
+	MessageTypeAuth        = "AUTH"
+	MessageTypeJoinRoom    = "JOIN_ROOM"
+	MessageTypeCreateRoom  = "CREATE_ROOM"
+	MessageTypeSendMessage = "SEND_MESSAGE"
+	MessageTypeMarkRead    = "MARK_READ"
+	MessageTypeTypingStart = "TYPING_START"
+	MessageTypeTypingStop  = "TYPING_STOP"
+	MessageTypeHeartbeat   = "HEARTBEAT"
+	MessageTypePresence    = "PRESENCE"
+	MessageTypeEditMessage = "EDIT_MESSAGE"
+	MessageTypeDeleteMsg   = "DELETE_MESSAGE"
+	MessageTypeReact       = "REACT"
+	MessageTypeResume      = "RESUME"
+	MessageTypeAck         = "ACK"
+	MessageTypeError       = "ERROR"
+)
+
+// Error codes carried by ERROR frames.
+const (
+	ErrCodeInvalidMessage  = "INVALID_MESSAGE"
+	ErrCodeInvalidPayload  = "INVALID_PAYLOAD"
+	ErrCodeUnknownType     = "UNKNOWN_TYPE"
+	ErrCodeUnauthenticated = "UNAUTHENTICATED"
+	ErrCodeForbidden       = "FORBIDDEN"
+	ErrCodeNotFound        = "NOT_FOUND"
+	ErrCodeBadRequest      = "BAD_REQUEST"
+	ErrCodeRateLimited     = "RATE_LIMITED"
+	ErrCodeInternal        = "INTERNAL_ERROR"
+)
 
 type Message struct {
 	Type    string          `json:"type"`
@@ -15,11 +50,92 @@ type JoinGroupPayload struct {
 type SendMessagePayload struct {
 	ChatGroupID int    `json:"chatGroupId" example:"123"`
 	Message     string `json:"message" example:"Hello"`
-	AccessToken string `json:"accessToken" example:"string"`
+	// ParentID sends the message as a reply in the thread of that message.
+	ParentID *int `json:"parentId,omitempty" example:"456"`
+	// AttachmentIDs links uploads made with POST /api/chat-message/attachments.
+	AttachmentIDs []int `json:"attachmentIds,omitempty"`
+	// Deprecated: the socket is authenticated once with AUTH.
+	AccessToken string `json:"accessToken,omitempty" example:"string"`
+}
+
+type MarkReadPayload struct {
+	ChatGroupID int `json:"chatGroupId" example:"123"`
+	// MessageID defaults to the latest message of the group.
+	MessageID int64 `json:"messageId,omitempty" example:"456"`
+}
+
+// ResumePayload asks for the messages of a room after LastSeq. Without
+// LastSeq the replay starts after the last seq acknowledged with ACK.
+type ResumePayload struct {
+	ChatGroupID int    `json:"chatGroupId" example:"123"`
+	LastSeq     *int64 `json:"lastSeq,omitempty" example:"41"`
+}
+
+// AckPayload acknowledges every message of the room up to Seq.
+type AckPayload struct {
+	ChatGroupID int   `json:"chatGroupId" example:"123"`
+	Seq         int64 `json:"seq" example:"42"`
+}
+
+type EditMessagePayload struct {
+	MessageID int    `json:"messageId" example:"456"`
+	Message   string `json:"message" example:"Hello again"`
+}
+
+type DeleteMessagePayload struct {
+	MessageID int `json:"messageId" example:"456"`
+}
+
+type ReactPayload struct {
+	MessageID int    `json:"messageId" example:"456"`
+	Emoji     string `json:"emoji" example:"👍"`
+}
+
+type TypingPayload struct {
+	ChatGroupID int `json:"chatGroupId" example:"123"`
 }
 
 type CreateRoomPayload struct {
 	Name          string  `json:"name" example:"Room name"`
 	TargetUserIDs []int64 `json:"targetUserIDs" example:"1,2,3"`
-	AccessToken   string  `json:"accessToken" example:"string"`
+	BuildingID    int64   `json:"buildingId,omitempty" example:"1"`
+	// Deprecated: the socket is authenticated once with AUTH.
+	AccessToken string `json:"accessToken,omitempty" example:"string"`
+}
+
+// Event is a server to client frame.
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// TypingEvent is the data of TYPING_START and TYPING_STOP frames sent to
+// the room.
+type TypingEvent struct {
+	ChatGroupID int `json:"chatGroupId" example:"123"`
+	UserID      int `json:"userId" example:"7"`
+}
+
+// PresenceEvent is the data of PRESENCE frames sent to the rooms of a user
+// when they come online or go offline.
+type PresenceEvent struct {
+	UserID     int        `json:"userId" example:"7"`
+	Online     bool       `json:"online" example:"true"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+}
+
+// ResumeEvent is the data of the RESUME frame that ends a replay. With
+// HasMore the client sends another RESUME from LastSeq.
+type ResumeEvent struct {
+	ChatGroupID int   `json:"chatGroupId" example:"123"`
+	LastSeq     int64 `json:"lastSeq" example:"42"`
+	HasMore     bool  `json:"hasMore" example:"false"`
+}
+
+// ErrorPayload is the data of an ERROR frame. RequestType echoes the type
+// of the client message that failed.
+type ErrorPayload struct {
+	Code        string `json:"code" example:"FORBIDDEN"`
+	Message     string `json:"message" example:"not a member of this chat group"`
+	RequestType string `json:"requestType,omitempty" example:"JOIN_ROOM"`
 }
@@ -0,0 +1,136 @@
+package ws
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"thomas.vn/apartment_service/internal/domain/model"
+)
+
+// UserStatus is the online state of a user as seen by the backend.
+type UserStatus struct {
+	UserID   string
+	Online   bool
+	LastSeen time.Time
+}
+
+// Connect marks the user of c online and adds c to the user's channel. It
+// must be called once per authenticated connection; a user may have several.
+func (h *Hub) Connect(c *Client) {
+	h.usersMu.Lock()
+	if h.clients[c.UserID] == nil {
+		h.clients[c.UserID] = make(map[*Client]bool)
+	}
+	h.clients[c.UserID][c] = true
+	first := len(h.clients[c.UserID]) == 1
+	h.usersMu.Unlock()
+
+	if first {
+		h.setOnline(c.UserID, true)
+		h.subscribeUser(c.UserID, true)
+	}
+}
+
+// Disconnect undoes Connect. It reports whether the user has no other
+// connection on this instance.
+func (h *Hub) Disconnect(c *Client) bool {
+	h.usersMu.Lock()
+	delete(h.clients[c.UserID], c)
+	last := len(h.clients[c.UserID]) == 0
+	if last {
+		delete(h.clients, c.UserID)
+		h.lastSeen[c.UserID] = time.Now()
+	}
+	h.usersMu.Unlock()
+
+	if last {
+		h.setOnline(c.UserID, false)
+		h.subscribeUser(c.UserID, false)
+	}
+	return last
+}
+
+// Touch refreshes the presence of the user of c after a heartbeat.
+func (h *Hub) Touch(c *Client) {
+	h.setOnline(c.UserID, true)
+}
+
+// UserPresence returns the online state and last seen time of the users.
+func (h *Hub) UserPresence(ctx context.Context, userIDs []int64) ([]*model.UserPresence, error) {
+	ids := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+
+	var statuses []UserStatus
+	if h.backend != nil {
+		var err error
+		if statuses, err = h.backend.UserStatus(ctx, ids); err != nil {
+			return nil, err
+		}
+	} else {
+		statuses = h.localStatus(ids)
+	}
+
+	res := make([]*model.UserPresence, 0, len(statuses))
+	for _, s := range statuses {
+		id, err := strconv.ParseInt(s.UserID, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		p := &model.UserPresence{UserID: id, Online: s.Online}
+		if !s.LastSeen.IsZero() {
+			lastSeen := s.LastSeen
+			p.LastSeenAt = &lastSeen
+		}
+		res = append(res, p)
+	}
+	return res, nil
+}
+
+func (h *Hub) localStatus(userIDs []string) []UserStatus {
+	h.usersMu.Lock()
+	defer h.usersMu.Unlock()
+
+	now := time.Now()
+	statuses := make([]UserStatus, 0, len(userIDs))
+	for _, id := range userIDs {
+		s := UserStatus{UserID: id, LastSeen: h.lastSeen[id]}
+		if len(h.clients[id]) > 0 {
+			s.Online = true
+			s.LastSeen = now
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+func (h *Hub) setOnline(userID string, online bool) {
+	if h.backend == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+
+	_ = h.backend.SetOnline(ctx, userID, online)
+}
+
+// subscribeUser follows or stops following the user's channel on the
+// backend.
+func (h *Hub) subscribeUser(userID string, subscribe bool) {
+	if h.backend == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+
+	if subscribe {
+		_ = h.backend.SubscribeUser(ctx, userID)
+	} else {
+		_ = h.backend.UnsubscribeUser(ctx, userID)
+	}
+}
@@ -0,0 +1,362 @@
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	xlogger "thomas.vn/apartment_service/pkg/logger"
+)
+
+const (
+	// presenceTTL is how long a presence entry survives without a heartbeat,
+	// so users of a crashed instance eventually disappear.
+	presenceTTL = 90 * time.Second
+	// presenceHeartbeat is how often local presence entries are refreshed.
+	presenceHeartbeat = 30 * time.Second
+)
+
+type presenceKey struct {
+	room   int
+	userID string
+}
+
+// RedisBackend distributes room broadcasts and user messages through Redis
+// pub/sub and keeps
+// room presence in one sorted set per room, shared by all instances. Users
+// are online while a sorted set of their instances has a fresh entry; the
+// last seen times live in a single hash.
+type RedisBackend struct {
+	logger     *xlogger.Logger
+	client     *redis.Client
+	prefix     string
+	instanceID string
+
+	pubsub *redis.PubSub
+
+	mu       sync.Mutex
+	presence map[presenceKey]struct{}
+	online   map[string]struct{}
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewRedisBackend creates a backend using client. prefix namespaces the
+// channels and keys, e.g. "ws".
+func NewRedisBackend(logger *xlogger.Logger, client *redis.Client, prefix string) *RedisBackend {
+	return &RedisBackend{
+		logger:     logger,
+		client:     client,
+		prefix:     prefix,
+		instanceID: newInstanceID(),
+		presence:   make(map[presenceKey]struct{}),
+		online:     make(map[string]struct{}),
+		stop:       make(chan struct{}),
+	}
+}
+
+func (b *RedisBackend) Start(deliver func(room int, msg []byte), deliverUser func(userID string, msg []byte)) error {
+	b.pubsub = b.client.Subscribe(context.Background())
+
+	b.wg.Add(2)
+	go b.receive(deliver, deliverUser)
+	go b.heartbeat()
+
+	b.logger.Info("WebSocket redis backend started", xlogger.String("instance_id", b.instanceID))
+	return nil
+}
+
+func (b *RedisBackend) Publish(ctx context.Context, room int, msg []byte) error {
+	if err := b.client.Publish(ctx, b.roomChannel(room), msg).Err(); err != nil {
+		b.logger.Error("Publish room message failed", xlogger.Int("room", room), xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b *RedisBackend) Subscribe(ctx context.Context, room int) error {
+	if err := b.pubsub.Subscribe(ctx, b.roomChannel(room)); err != nil {
+		b.logger.Error("Subscribe room failed", xlogger.Int("room", room), xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b *RedisBackend) Unsubscribe(ctx context.Context, room int) error {
+	if err := b.pubsub.Unsubscribe(ctx, b.roomChannel(room)); err != nil {
+		b.logger.Error("Unsubscribe room failed", xlogger.Int("room", room), xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b *RedisBackend) PublishUser(ctx context.Context, userID string, msg []byte) error {
+	if err := b.client.Publish(ctx, b.userChannel(userID), msg).Err(); err != nil {
+		b.logger.Error("Publish user message failed", xlogger.String("user_id", userID), xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b *RedisBackend) SubscribeUser(ctx context.Context, userID string) error {
+	if err := b.pubsub.Subscribe(ctx, b.userChannel(userID)); err != nil {
+		b.logger.Error("Subscribe user failed", xlogger.String("user_id", userID), xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b *RedisBackend) UnsubscribeUser(ctx context.Context, userID string) error {
+	if err := b.pubsub.Unsubscribe(ctx, b.userChannel(userID)); err != nil {
+		b.logger.Error("Unsubscribe user failed", xlogger.String("user_id", userID), xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b *RedisBackend) SetPresence(ctx context.Context, room int, userID string, present bool) error {
+	key := presenceKey{room: room, userID: userID}
+
+	b.mu.Lock()
+	if present {
+		b.presence[key] = struct{}{}
+	} else {
+		delete(b.presence, key)
+	}
+	b.mu.Unlock()
+
+	var err error
+	if present {
+		err = b.client.ZAdd(ctx, b.presenceKey(room), redis.Z{
+			Score:  float64(time.Now().Unix()),
+			Member: b.presenceMember(userID),
+		}).Err()
+	} else {
+		err = b.client.ZRem(ctx, b.presenceKey(room), b.presenceMember(userID)).Err()
+	}
+	if err != nil {
+		b.logger.Error("Update room presence failed", xlogger.Int("room", room), xlogger.Error(err))
+	}
+	return err
+}
+
+func (b *RedisBackend) Presence(ctx context.Context, room int) ([]string, error) {
+	key := b.presenceKey(room)
+	expired := strconv.FormatInt(time.Now().Add(-presenceTTL).Unix(), 10)
+
+	if err := b.client.ZRemRangeByScore(ctx, key, "-inf", "("+expired).Err(); err != nil {
+		return nil, err
+	}
+	members, err := b.client.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(members))
+	users := make([]string, 0, len(members))
+	for _, member := range members {
+		// Members are "<instance>|<user>"; a user may be online on several instances.
+		idx := strings.LastIndex(member, "|")
+		userID := member[idx+1:]
+		if _, ok := seen[userID]; ok {
+			continue
+		}
+		seen[userID] = struct{}{}
+		users = append(users, userID)
+	}
+	return users, nil
+}
+
+func (b *RedisBackend) SetOnline(ctx context.Context, userID string, online bool) error {
+	b.mu.Lock()
+	if online {
+		b.online[userID] = struct{}{}
+	} else {
+		delete(b.online, userID)
+	}
+	b.mu.Unlock()
+
+	now := time.Now()
+	pipe := b.client.TxPipeline()
+	if online {
+		b.refreshOnline(ctx, pipe, userID, now)
+	} else {
+		pipe.ZRem(ctx, b.onlineKey(userID), b.instanceID)
+		pipe.HSet(ctx, b.lastSeenKey(), userID, now.Unix())
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		b.logger.Error("Update user presence failed", xlogger.String("user_id", userID), xlogger.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b *RedisBackend) UserStatus(ctx context.Context, userIDs []string) ([]UserStatus, error) {
+	if len(userIDs) == 0 {
+		return []UserStatus{}, nil
+	}
+
+	now := time.Now()
+	fresh := strconv.FormatInt(now.Add(-presenceTTL).Unix(), 10)
+
+	pipe := b.client.Pipeline()
+	counts := make([]*redis.IntCmd, 0, len(userIDs))
+	for _, id := range userIDs {
+		counts = append(counts, pipe.ZCount(ctx, b.onlineKey(id), fresh, "+inf"))
+	}
+	lastSeen := pipe.HMGet(ctx, b.lastSeenKey(), userIDs...)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	seen := lastSeen.Val()
+	statuses := make([]UserStatus, 0, len(userIDs))
+	for i, id := range userIDs {
+		s := UserStatus{UserID: id, Online: counts[i].Val() > 0}
+		if s.Online {
+			s.LastSeen = now
+		} else if i < len(seen) {
+			if raw, ok := seen[i].(string); ok {
+				if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+					s.LastSeen = time.Unix(unix, 0)
+				}
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+func (b *RedisBackend) Close() error {
+	close(b.stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+
+	b.mu.Lock()
+	for key := range b.presence {
+		_ = b.client.ZRem(ctx, b.presenceKey(key.room), b.presenceMember(key.userID)).Err()
+	}
+	for userID := range b.online {
+		_ = b.client.ZRem(ctx, b.onlineKey(userID), b.instanceID).Err()
+		_ = b.client.HSet(ctx, b.lastSeenKey(), userID, time.Now().Unix()).Err()
+	}
+	b.mu.Unlock()
+
+	var err error
+	if b.pubsub != nil {
+		err = b.pubsub.Close()
+	}
+	b.wg.Wait()
+	return err
+}
+
+func (b *RedisBackend) receive(deliver func(room int, msg []byte), deliverUser func(userID string, msg []byte)) {
+	defer b.wg.Done()
+
+	roomPrefix := b.prefix + ":room:"
+	userPrefix := b.prefix + ":user:"
+	for msg := range b.pubsub.Channel() {
+		if userID, ok := strings.CutPrefix(msg.Channel, userPrefix); ok {
+			deliverUser(userID, []byte(msg.Payload))
+			continue
+		}
+
+		room, err := strconv.Atoi(strings.TrimPrefix(msg.Channel, roomPrefix))
+		if err != nil {
+			continue
+		}
+		deliver(room, []byte(msg.Payload))
+	}
+}
+
+// heartbeat refreshes the presence entries of this instance.
+func (b *RedisBackend) heartbeat() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(presenceHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		}
+
+		b.mu.Lock()
+		keys := make([]presenceKey, 0, len(b.presence))
+		for key := range b.presence {
+			keys = append(keys, key)
+		}
+		users := make([]string, 0, len(b.online))
+		for userID := range b.online {
+			users = append(users, userID)
+		}
+		b.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		now := time.Now()
+		score := float64(now.Unix())
+		pipe := b.client.Pipeline()
+		for _, key := range keys {
+			pipe.ZAdd(ctx, b.presenceKey(key.room), redis.Z{Score: score, Member: b.presenceMember(key.userID)})
+		}
+		for _, userID := range users {
+			b.refreshOnline(ctx, pipe, userID, now)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && len(keys)+len(users) > 0 {
+			b.logger.Error("Refresh room presence failed", xlogger.Error(err))
+		}
+		cancel()
+	}
+}
+
+// refreshOnline queues the commands marking userID online on this instance.
+func (b *RedisBackend) refreshOnline(ctx context.Context, pipe redis.Pipeliner, userID string, now time.Time) {
+	key := b.onlineKey(userID)
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.Unix()), Member: b.instanceID})
+	pipe.Expire(ctx, key, 2*presenceTTL)
+	pipe.HSet(ctx, b.lastSeenKey(), userID, now.Unix())
+}
+
+func (b *RedisBackend) roomChannel(room int) string {
+	return fmt.Sprintf("%s:room:%d", b.prefix, room)
+}
+
+func (b *RedisBackend) userChannel(userID string) string {
+	return fmt.Sprintf("%s:user:%s", b.prefix, userID)
+}
+
+func (b *RedisBackend) presenceKey(room int) string {
+	return fmt.Sprintf("%s:presence:%d", b.prefix, room)
+}
+
+func (b *RedisBackend) onlineKey(userID string) string {
+	return fmt.Sprintf("%s:online:%s", b.prefix, userID)
+}
+
+func (b *RedisBackend) lastSeenKey() string {
+	return b.prefix + ":last_seen"
+}
+
+func (b *RedisBackend) presenceMember(userID string) string {
+	return b.instanceID + "|" + userID
+}
+
+// newInstanceID identifies this process in shared presence sets.
+func newInstanceID() string {
+	host, _ := os.Hostname()
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(buf))
+}
@@ -3,13 +3,17 @@ package ws
 import (
 	"context"
 	"encoding/json"
-	"strconv"
+	"errors"
+	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 
+	"thomas.vn/apartment_service/internal/domain/apperror"
 	"thomas.vn/apartment_service/internal/domain/model/chatgroup"
 	"thomas.vn/apartment_service/internal/domain/model/chatmessage"
 	"thomas.vn/apartment_service/internal/domain/usecase"
+	xrequestinfo "thomas.vn/apartment_service/pkg/requestinfo"
 )
 
 type Server struct {
@@ -31,16 +35,29 @@ func (s *Server) Handle(conn *websocket.Conn) {
 // It cleans up the client from all rooms on exit.
 func (s *Server) readPump(c *Client) {
 	defer func() {
-		c.mu.Lock()
-		for room := range c.rooms {
+		rooms := c.AllRooms()
+		for _, room := range rooms {
 			s.Hub.Leave(room, c)
 		}
-		c.mu.Unlock()
+		if c.Authenticated() && s.Hub.Disconnect(c) {
+			now := time.Now()
+			s.broadcastPresence(rooms, PresenceEvent{UserID: c.userID, LastSeenAt: &now})
+		}
 		close(c.send)
 	}()
 
 	c.conn.SetReadLimit(maxMessageSize)
 
+	// Pongs answer the pings of WritePump; they keep the connection and the
+	// user's presence alive.
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		if c.Authenticated() {
+			s.Hub.Touch(c)
+		}
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
 	for {
 		_, data, err := c.conn.ReadMessage()
 		if err != nil {
@@ -48,8 +65,14 @@ func (s *Server) readPump(c *Client) {
 		}
 		var msg Message
 		if err := json.Unmarshal(data, &msg); err != nil {
+			s.sendError(c, "", ErrCodeInvalidMessage, "message must be valid JSON")
+			continue
+		}
+		if !c.limiter.Allow() {
+			s.sendError(c, msg.Type, ErrCodeRateLimited, "too many messages")
 			continue
 		}
+		_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
 		s.dispatch(c, msg)
 	}
 }
@@ -57,15 +80,38 @@ func (s *Server) readPump(c *Client) {
 // ================= ROUTER =================
 
 func (s *Server) dispatch(c *Client, msg Message) {
+	if msg.Type != MessageTypeAuth && !c.Authenticated() {
+		s.sendError(c, msg.Type, ErrCodeUnauthenticated, "authenticate with AUTH first")
+		return
+	}
+
 	switch msg.Type {
-	case "AUTH":
+	case MessageTypeAuth:
 		s.handleAuth(c, msg)
-	case "JOIN_ROOM":
+	case MessageTypeJoinRoom:
 		s.handleJoinRoom(c, msg)
-	case "CREATE_ROOM":
+	case MessageTypeCreateRoom:
 		s.handleCreateRoom(c, msg)
-	case "SEND_MESSAGE":
+	case MessageTypeSendMessage:
 		s.handleSendMessage(c, msg)
+	case MessageTypeMarkRead:
+		s.handleMarkRead(c, msg)
+	case MessageTypeResume:
+		s.handleResume(c, msg)
+	case MessageTypeAck:
+		s.handleAck(c, msg)
+	case MessageTypeEditMessage:
+		s.handleEditMessage(c, msg)
+	case MessageTypeDeleteMsg:
+		s.handleDeleteMessage(c, msg)
+	case MessageTypeReact:
+		s.handleReact(c, msg)
+	case MessageTypeTypingStart, MessageTypeTypingStop:
+		s.handleTyping(c, msg)
+	case MessageTypeHeartbeat:
+		s.handleHeartbeat(c, msg)
+	default:
+		s.sendError(c, msg.Type, ErrCodeUnknownType, "unknown message type")
 	}
 }
 
@@ -74,98 +120,373 @@ func (s *Server) dispatch(c *Client, msg Message) {
 func (s *Server) handleAuth(c *Client, msg Message) {
 	var p Auth
 	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
 		return
 	}
 
 	claims, err := s.Token.VerifyAccessToken(p.AccessToken)
 	if err != nil {
+		s.sendError(c, msg.Type, ErrCodeUnauthenticated, "invalid access token")
 		return
 	}
 
-	c.UserID = strconv.Itoa(int(claims.UserID))
+	// Rooms were joined with the first identity, so it cannot change.
+	if c.Authenticated() && c.userID != int(claims.UserID) {
+		s.sendError(c, msg.Type, ErrCodeForbidden, "socket is already authenticated as another user")
+		return
+	}
+	if !c.Authenticated() {
+		c.authenticate(int(claims.UserID))
+		s.Hub.Connect(c)
+	}
+
+	s.send(c, Event{
+		Type: MessageTypeAuth,
+		Data: map[string]any{
+			"userId": claims.UserID,
+		},
+	})
 }
 
 func (s *Server) handleJoinRoom(c *Client, msg Message) {
 	var p JoinGroupPayload
 	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
 		return
 	}
 
 	room := p.ChatGroupID
+	if err := s.ChatUC.JoinRoom(s.context(c), room, c.userID); err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
+		return
+	}
+
 	s.Hub.Join(room, c)
 	c.JoinRoom(room)
+
+	s.send(c, Event{
+		Type: MessageTypeJoinRoom,
+		Data: map[string]any{
+			"chatGroupId": room,
+		},
+	})
+	s.broadcastPresence([]int{room}, PresenceEvent{UserID: c.userID, Online: true})
 }
 
 func (s *Server) handleCreateRoom(c *Client, msg Message) {
 	var p CreateRoomPayload
 	if err := json.Unmarshal(msg.Payload, &p); err != nil {
-		return
-	}
-
-	claims, err := s.Token.VerifyAccessToken(p.AccessToken)
-	if err != nil {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
 		return
 	}
 
 	req := &chatgroup.CreateChatGroupRequest{
 		Name:          p.Name,
-		OwnerID:       int64(claims.UserID),
+		OwnerID:       int64(c.userID),
 		TargetUserIDs: p.TargetUserIDs,
+		BuildingID:    p.BuildingID,
 	}
 
-	roomID, err := s.ChatUC.CreateRoom(context.Background(), req)
+	group, err := s.ChatUC.CreateRoom(s.context(c), req)
 	if err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
 		return
 	}
+	roomID := group.ID
 
 	s.Hub.Join(roomID, c)
 	c.JoinRoom(roomID)
 
-	resp, _ := json.Marshal(map[string]any{
-		"type": "CREATE_ROOM",
-		"data": map[string]any{
+	s.send(c, Event{
+		Type: MessageTypeCreateRoom,
+		Data: map[string]any{
 			"chatGroupId": roomID,
 		},
 	})
-
-	select {
-	case c.send <- resp:
-	default:
-	}
 }
 
 func (s *Server) handleSendMessage(c *Client, msg Message) {
 	var p SendMessagePayload
 	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
 		return
 	}
 
-	claims, err := s.Token.VerifyAccessToken(p.AccessToken)
-	if err != nil {
+	room := p.ChatGroupID
+	req := &chatmessage.CreateChatMessageRequest{
+		ChatGroupID:   room,
+		UserIDSender:  c.userID,
+		MessageText:   p.Message,
+		ParentID:      p.ParentID,
+		AttachmentIDs: p.AttachmentIDs,
+	}
+
+	// The sender joins the room first, so that it receives the broadcast
+	// of its own message like any other member.
+	if !c.InRoom(room) {
+		if err := s.ChatUC.JoinRoom(s.context(c), room, c.userID); err != nil {
+			s.sendUsecaseError(c, msg.Type, err)
+			return
+		}
+		s.Hub.Join(room, c)
+		c.JoinRoom(room)
+	}
+
+	// SendMessage stores the message and broadcasts it to the room.
+	if _, err := s.ChatUC.SendMessage(s.context(c), req); err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
+	}
+}
+
+// handleResume replays the messages of a room the client missed, e.g. while
+// reconnecting, as SEND_MESSAGE frames followed by a RESUME frame. The client
+// joins the room before the replay is read, so no message falls in between;
+// a message may arrive twice, and clients drop seqs they already have.
+func (s *Server) handleResume(c *Client, msg Message) {
+	var p ResumePayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil || (p.LastSeq != nil && *p.LastSeq < 0) {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
 		return
 	}
 
 	room := p.ChatGroupID
-	if !c.InRoom(room) {
+	if err := s.ChatUC.JoinRoom(s.context(c), room, c.userID); err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
+		return
+	}
+
+	joined := !c.InRoom(room)
+	if joined {
 		s.Hub.Join(room, c)
 		c.JoinRoom(room)
 	}
 
-	req := &chatmessage.CreateChatMessageRequest{
-		ChatGroupID:  p.ChatGroupID,
-		UserIDSender: int(claims.UserID),
-		MessageText:  p.Message,
+	replay, err := s.ChatUC.Resume(s.context(c), c.userID, room, p.LastSeq)
+	if err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
+		return
 	}
 
-	resp, err := s.ChatUC.SendMessage(context.Background(), req)
+	for _, m := range replay.Messages {
+		s.send(c, Event{Type: MessageTypeSendMessage, Data: m})
+	}
+	s.send(c, Event{
+		Type: MessageTypeResume,
+		Data: ResumeEvent{
+			ChatGroupID: room,
+			LastSeq:     replay.LastSeq,
+			HasMore:     replay.HasMore,
+		},
+	})
+
+	if joined {
+		s.broadcastPresence([]int{room}, PresenceEvent{UserID: c.userID, Online: true})
+	}
+}
+
+// handleAck records the delivery of the room's messages up to a seq. Acks
+// are cumulative and only answered on error.
+func (s *Server) handleAck(c *Client, msg Message) {
+	var p AckPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil || p.Seq <= 0 {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
+		return
+	}
+
+	req := &chatgroup.AckRequest{
+		ChatGroupIDRequest: chatgroup.ChatGroupIDRequest{ID: int64(p.ChatGroupID)},
+		Seq:                p.Seq,
+	}
+
+	if _, err := s.ChatUC.Ack(s.context(c), c.userID, req); err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
+	}
+}
+
+// handleMarkRead moves the read marker of the client. The usecase tells the
+// room with a MESSAGE_READ event; the sender also gets a MARK_READ reply.
+func (s *Server) handleMarkRead(c *Client, msg Message) {
+	var p MarkReadPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
+		return
+	}
+
+	req := &chatgroup.MarkReadRequest{
+		ChatGroupIDRequest: chatgroup.ChatGroupIDRequest{ID: int64(p.ChatGroupID)},
+		MessageID:          p.MessageID,
+	}
+
+	receipt, err := s.ChatUC.MarkRead(s.context(c), c.userID, req)
 	if err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
 		return
 	}
 
-	event, _ := json.Marshal(map[string]any{
-		"type": "SEND_MESSAGE",
-		"data": resp,
+	s.send(c, Event{
+		Type: MessageTypeMarkRead,
+		Data: receipt,
 	})
+}
 
-	s.Hub.Broadcast(room, event)
+// handleEditMessage, handleDeleteMessage and handleReact leave the room
+// broadcast to the usecase; a client outside the room gets the event
+// directly.
+func (s *Server) handleEditMessage(c *Client, msg Message) {
+	var p EditMessagePayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil || p.Message == "" {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
+		return
+	}
+
+	req := &chatmessage.EditChatMessageRequest{
+		ChatMessageIDRequest: chatmessage.ChatMessageIDRequest{ID: p.MessageID},
+		MessageText:          p.Message,
+	}
+
+	resp, err := s.ChatUC.EditMessage(s.context(c), c.userID, req)
+	if err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
+		return
+	}
+	s.sendOutsideRoom(c, resp.ChatGroupID, Event{Type: msg.Type, Data: resp})
+}
+
+func (s *Server) handleDeleteMessage(c *Client, msg Message) {
+	var p DeleteMessagePayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
+		return
+	}
+
+	event, err := s.ChatUC.DeleteMessage(s.context(c), c.userID, p.MessageID)
+	if err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
+		return
+	}
+	s.sendOutsideRoom(c, event.ChatGroupID, Event{Type: msg.Type, Data: event})
+}
+
+func (s *Server) handleReact(c *Client, msg Message) {
+	var p ReactPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil || p.Emoji == "" || len(p.Emoji) > 32 {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
+		return
+	}
+
+	req := &chatmessage.ReactRequest{
+		ChatMessageIDRequest: chatmessage.ChatMessageIDRequest{ID: p.MessageID},
+		Emoji:                p.Emoji,
+	}
+
+	event, err := s.ChatUC.React(s.context(c), c.userID, req)
+	if err != nil {
+		s.sendUsecaseError(c, msg.Type, err)
+		return
+	}
+	s.sendOutsideRoom(c, event.ChatGroupID, Event{Type: msg.Type, Data: event})
+}
+
+// handleTyping fans typing indicators out to the room. Typing is best
+// effort, so throttled TYPING_START messages are dropped silently.
+func (s *Server) handleTyping(c *Client, msg Message) {
+	var p TypingPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		s.sendError(c, msg.Type, ErrCodeInvalidPayload, "invalid payload")
+		return
+	}
+
+	// Membership was checked when the client joined the room.
+	if !c.InRoom(p.ChatGroupID) {
+		s.sendError(c, msg.Type, ErrCodeForbidden, "join the room first")
+		return
+	}
+	if msg.Type == MessageTypeTypingStart && !c.typing.Allow() {
+		return
+	}
+
+	event, _ := json.Marshal(Event{
+		Type: msg.Type,
+		Data: TypingEvent{ChatGroupID: p.ChatGroupID, UserID: c.userID},
+	})
+	s.Hub.Broadcast(p.ChatGroupID, event)
+}
+
+// handleHeartbeat refreshes the presence of clients that cannot answer
+// protocol level pings.
+func (s *Server) handleHeartbeat(c *Client, msg Message) {
+	s.Hub.Touch(c)
+	s.send(c, Event{Type: MessageTypeHeartbeat})
+}
+
+// ================= HELPERS =================
+
+// sendOutsideRoom sends event to c unless c already receives the room
+// broadcast.
+func (s *Server) sendOutsideRoom(c *Client, room int, event Event) {
+	if !c.InRoom(room) {
+		s.send(c, event)
+	}
+}
+
+func (s *Server) broadcastPresence(rooms []int, presence PresenceEvent) {
+	if len(rooms) == 0 {
+		return
+	}
+
+	event, err := json.Marshal(Event{Type: MessageTypePresence, Data: presence})
+	if err != nil {
+		return
+	}
+	for _, room := range rooms {
+		s.Hub.Broadcast(room, event)
+	}
+}
+
+// context returns the context used for usecase calls made on behalf of c.
+func (s *Server) context(c *Client) context.Context {
+	return xrequestinfo.WithActor(context.Background(), c.userID)
+}
+
+func (s *Server) send(c *Client, event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	c.enqueue(data)
+}
+
+func (s *Server) sendError(c *Client, requestType, code, message string) {
+	s.send(c, Event{
+		Type: MessageTypeError,
+		Data: ErrorPayload{
+			Code:        code,
+			Message:     message,
+			RequestType: requestType,
+		},
+	})
+}
+
+// sendUsecaseError maps domain errors to ERROR frames. Unexpected errors
+// are reported without details.
+func (s *Server) sendUsecaseError(c *Client, requestType string, err error) {
+	var de *apperror.DomainError
+	if !errors.As(err, &de) {
+		s.sendError(c, requestType, ErrCodeInternal, "internal server error")
+		return
+	}
+
+	code := ErrCodeBadRequest
+	switch de.Status {
+	case http.StatusUnauthorized:
+		code = ErrCodeUnauthenticated
+	case http.StatusForbidden:
+		code = ErrCodeForbidden
+	case http.StatusNotFound:
+		code = ErrCodeNotFound
+	case http.StatusInternalServerError:
+		code = ErrCodeInternal
+	}
+	s.sendError(c, requestType, code, de.Message)
 }